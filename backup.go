@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/chanbackup"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// keyLocatorInfo is a key locator of our side of a channel.
+type keyLocatorInfo struct {
+	Key    string `json:"key"`
+	Family uint32 `json:"family"`
+	Index  uint32 `json:"index"`
+}
+
+// channelBackupInfo is the exportable summary of a static channel backup.
+type channelBackupInfo struct {
+	Version         string           `json:"version"`
+	Outpoint        string           `json:"outpoint"`
+	ShortChannelID  string           `json:"short_channel_id"`
+	RemoteNodePub   string           `json:"remote_node_pubkey"`
+	RemoteAddresses []string         `json:"remote_addresses"`
+	CapacitySat     int64            `json:"capacity_sat"`
+	Chain           string           `json:"chain"`
+	IsInitiator     bool             `json:"is_initiator"`
+	LocalCsvDelay   uint16           `json:"local_csv_delay"`
+	RemoteCsvDelay  uint16           `json:"remote_csv_delay"`
+	KeyLocators     []keyLocatorInfo `json:"key_locators"`
+	LeaseExpiry     uint32           `json:"lease_expiry,omitempty"`
+	HasCloseTx      bool             `json:"has_close_tx"`
+}
+
+// readBackupInput returns the raw backup bytes of a channel.backup file or of
+// a hex encoded backup blob, as printed by "lncli exportchanbackup".
+func readBackupInput(data []byte) []byte {
+	trimmed := strings.TrimSpace(string(data))
+	if raw, err := hex.DecodeString(trimmed); err == nil && len(raw) > 0 {
+		return raw
+	}
+	return data
+}
+
+// decodeChannelBackup decrypts a multi channel backup (channel.backup) or, if
+// single is set, a single channel backup with the key derived from the seed.
+func decodeChannelBackup(w *wallet.Wallet, data []byte, single bool) ([]channelBackupInfo, error) {
+	if w.WatchOnly() {
+		return nil, fmt.Errorf("o backup de canais é cifrado com uma chave privada da seed, indisponível no modo somente leitura")
+	}
+	var singles []chanbackup.Single
+	if single {
+		var s chanbackup.Single
+		if err := s.UnpackFromReader(bytes.NewReader(readBackupInput(data)), w); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar backup de canal: %w", err)
+		}
+		singles = append(singles, s)
+	} else {
+		var m chanbackup.Multi
+		if err := m.UnpackFromReader(bytes.NewReader(readBackupInput(data)), w); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar channel.backup: %w", err)
+		}
+		singles = m.StaticBackups
+	}
+
+	infos := make([]channelBackupInfo, 0, len(singles))
+	for _, s := range singles {
+		infos = append(infos, describeChannelBackup(&s))
+	}
+	return infos, nil
+}
+
+// describeChannelBackup turns a decrypted backup into its exportable summary.
+func describeChannelBackup(s *chanbackup.Single) channelBackupInfo {
+	addrs := make([]string, 0, len(s.Addresses))
+	for _, addr := range s.Addresses {
+		addrs = append(addrs, addr.String())
+	}
+
+	locators := []struct {
+		key string
+		loc chanbackup.KeyLocator
+	}{
+		{"multisig", s.LocalKeys.MultiSig},
+		{"revocation_base", s.LocalKeys.RevocationBase},
+		{"payment_base", s.LocalKeys.PaymentBase},
+		{"delay_base", s.LocalKeys.DelayBase},
+		{"htlc_base", s.LocalKeys.HtlcBase},
+		{"shachain_root", s.ShaChainRoot},
+	}
+	keyLocators := make([]keyLocatorInfo, 0, len(locators))
+	for _, l := range locators {
+		keyLocators = append(keyLocators, keyLocatorInfo{
+			Key:    l.key,
+			Family: uint32(l.loc.Family),
+			Index:  l.loc.Index,
+		})
+	}
+
+	return channelBackupInfo{
+		Version:         s.Version.String(),
+		Outpoint:        s.FundingOutpoint.String(),
+		ShortChannelID:  formatShortChannelID(s.ShortChannelID),
+		RemoteNodePub:   hex.EncodeToString(s.RemoteNodePub.SerializeCompressed()),
+		RemoteAddresses: addrs,
+		CapacitySat:     int64(s.Capacity),
+		Chain:           chainName(&s.ChainHash),
+		IsInitiator:     s.IsInitiator,
+		LocalCsvDelay:   s.LocalCsvDelay,
+		RemoteCsvDelay:  s.RemoteCsvDelay,
+		KeyLocators:     keyLocators,
+		LeaseExpiry:     s.LeaseExpiry,
+		HasCloseTx:      s.HasCloseTx,
+	}
+}
+
+// formatShortChannelID formats a short channel ID as block x tx x output.
+func formatShortChannelID(id uint64) string {
+	return fmt.Sprintf("%dx%dx%d", id>>40, (id>>16)&0xffffff, id&0xffff)
+}
+
+// chainName returns the name of the network with the given genesis hash, or
+// the hash itself for unknown chains.
+func chainName(genesis *chainhash.Hash) string {
+	for _, n := range wallet.Networks {
+		if n.Params.GenesisHash.IsEqual(genesis) {
+			return n.Name
+		}
+	}
+	return genesis.String()
+}
+
+// writeChannelBackups writes a human-readable listing of decoded backups.
+func writeChannelBackups(out io.Writer, infos []channelBackupInfo) {
+	fmt.Fprintf(out, "%d canal(is) no backup.\n", len(infos))
+	for i, c := range infos {
+		fmt.Fprintf(out, "\nCanal %d (%s):\n", i+1, c.Version)
+		fmt.Fprintf(out, "  Outpoint: %s\n", c.Outpoint)
+		fmt.Fprintf(out, "  Short Channel ID: %s\n", c.ShortChannelID)
+		fmt.Fprintf(out, "  Nó Remoto: %s\n", c.RemoteNodePub)
+		if len(c.RemoteAddresses) > 0 {
+			fmt.Fprintf(out, "  Endereços: %s\n", strings.Join(c.RemoteAddresses, ", "))
+		}
+		fmt.Fprintf(out, "  Capacidade: %d sat\n", c.CapacitySat)
+		fmt.Fprintf(out, "  Rede: %s\n", c.Chain)
+		fmt.Fprintf(out, "  Iniciador: %t\n", c.IsInitiator)
+		fmt.Fprintf(out, "  CSV Local/Remoto: %d/%d\n", c.LocalCsvDelay, c.RemoteCsvDelay)
+		if c.HasCloseTx {
+			fmt.Fprintf(out, "  Contém dados de force close: sim\n")
+		}
+		if c.LeaseExpiry != 0 {
+			fmt.Fprintf(out, "  Expiração do Lease: %d\n", c.LeaseExpiry)
+		}
+		for _, l := range c.KeyLocators {
+			fmt.Fprintf(out, "  Key Locator %s: família %d, índice %d\n", l.Key, l.Family, l.Index)
+		}
+	}
+}
+
+// --- GUI ---
+
+// handleOpenChannelBackup lets the user pick a channel.backup file and shows
+// its decrypted contents.
+func handleOpenChannelBackup() {
+	if currentWallet == nil {
+		showStatus("Erro: Gere ou decodifique uma seed antes de abrir um backup.", true)
+		return
+	}
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro ao abrir arquivo: %v", err), true)
+			return
+		}
+		if reader == nil {
+			return // Cancelado
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro ao ler arquivo: %v", err), true)
+			return
+		}
+		showChannelBackup(data, false)
+	}, mainWindow)
+}
+
+// showChannelBackup decodes a backup and shows the channels in a dialog,
+// from which they can be exported as JSON.
+func showChannelBackup(data []byte, single bool) {
+	if currentWallet == nil {
+		showStatus("Erro: Gere ou decodifique uma seed antes de abrir um backup.", true)
+		return
+	}
+	infos, err := decodeChannelBackup(currentWallet, data, single)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+	log.Printf("Backup decodificado com %d canal(is).", len(infos))
+
+	var text strings.Builder
+	writeChannelBackups(&text, infos)
+	resultEntry := widget.NewMultiLineEntry()
+	resultEntry.SetText(text.String())
+	resultEntry.Wrapping = fyne.TextWrapOff
+	resultEntry.Disable()
+	resultScroll := container.NewScroll(resultEntry)
+	resultScroll.SetMinSize(fyne.NewSize(700, 400))
+
+	exportButton := widget.NewButton("Exportar JSON...", func() {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				showStatus(fmt.Sprintf("Erro ao salvar arquivo: %v", err), true)
+				return
+			}
+			if writer == nil {
+				return // Cancelado
+			}
+			defer writer.Close()
+			if err := writeJSON(writer, infos); err != nil {
+				showStatus(fmt.Sprintf("Erro ao exportar JSON: %v", err), true)
+				return
+			}
+			showStatus(fmt.Sprintf("Backup exportado para %s", writer.URI().Path()), false)
+		}, mainWindow)
+	})
+
+	dialog.ShowCustom("Backup de Canais (SCB)", "Fechar", container.NewBorder(nil, exportButton, nil, nil, resultScroll), mainWindow)
+	showStatus(fmt.Sprintf("Backup decodificado: %d canal(is).", len(infos)), false)
+}
+
+// readBackupFile reads a backup from a path, with "-" meaning stdin.
+func readBackupFile(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
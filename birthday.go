@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// formatBirthday describes the birthday of a seed: its date, the number of
+// days since the genesis block it is stored as and, where it can be estimated
+// offline, the approximate height of the first block of that day.
+func formatBirthday(seed *crypto.CipherSeed) string {
+	description := fmt.Sprintf("%s (dia %d desde o bloco gênese", seed.BirthdayTime().Format(birthdayLayout), seed.Birthday)
+	if height, ok := birthdayHeight(seed); ok {
+		description += fmt.Sprintf(", altura ≈ %d", height)
+	}
+	return description + ")"
+}
+
+// birthdayHeight estimates offline the height of the first block of a seed's
+// birthday on the current network.
+func birthdayHeight(seed *crypto.CipherSeed) (int64, bool) {
+	return currentNetwork.EstimateHeight(seed.BirthdayTime())
+}
+
+// birthdayHeightJSON is birthdayHeight for JSON output, nil if the height
+// can't be estimated.
+func birthdayHeightJSON(seed *crypto.CipherSeed) *int64 {
+	height, ok := birthdayHeight(seed)
+	if !ok {
+		return nil
+	}
+	return &height
+}
+
+const (
+	// birthdayLayout is the format of the birthday dates users enter.
+	birthdayLayout = "2006-01-02"
+
+	// birthdayPlaceholder describes the birthdays users can enter.
+	birthdayPlaceholder = "AAAA-MM-DD ou altura de bloco (vazio = hoje)"
+)
+
+// parseBirthday parses the birthday of a new seed, a date in birthdayLayout or
+// a block height of the network, or returns now if it is empty. Since aezeed
+// birthdays count days from the time of day of the genesis block, a date is
+// returned at its last second so that it is stored as that day. A height is
+// converted to the estimated time of its block, which is stored as the day it
+// falls in. Either way, rescans start a day earlier, see
+// crypto.CipherSeed.RescanTime.
+func parseBirthday(value string, network *wallet.Network, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return now, nil
+	}
+	if height, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return parseBirthdayHeight(height, network, now)
+	}
+	date, err := time.Parse(birthdayLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("aniversário inválido %q, use uma data AAAA-MM-DD ou uma altura de bloco", value)
+	}
+	if date.Before(crypto.BitcoinGenesisDate.UTC().Truncate(24 * time.Hour)) {
+		return time.Time{}, fmt.Errorf("o aniversário %s é anterior ao bloco gênese", value)
+	}
+	if date.After(now.UTC()) {
+		return time.Time{}, fmt.Errorf("o aniversário %s está no futuro", value)
+	}
+	return date.Add(24*time.Hour - time.Second), nil
+}
+
+// parseBirthdayHeight converts the block height of a birthday to the
+// estimated time of its block. Past the last block the network knows, the
+// estimate is early on purpose, so the height of a block that was already
+// mined is never taken for a future one.
+func parseBirthdayHeight(height int64, network *wallet.Network, now time.Time) (time.Time, error) {
+	if height < 0 {
+		return time.Time{}, fmt.Errorf("altura de bloco inválida: %d", height)
+	}
+	blockTime, ok := network.EstimateTime(height)
+	if !ok {
+		return time.Time{}, fmt.Errorf("a data de uma altura só pode ser estimada na mainnet, informe o aniversário da seed em %s como data", network)
+	}
+	if blockTime.After(now) {
+		return time.Time{}, fmt.Errorf("o bloco %d ainda não foi minerado (estimado para %s)", height, blockTime.Format(birthdayLayout))
+	}
+	return blockTime, nil
+}
+
+// rescanStartHeight asks the selected source, which must be able to find
+// blocks by time like the local node, for the height a rescan from the given
+// time, usually the rescan time of a seed, can start from.
+func rescanStartHeight(ctx context.Context, from time.Time) (int64, error) {
+	src, err := currentSource()
+	if err != nil {
+		return 0, err
+	}
+	heightSource, ok := src.(blockchain.HeightSource)
+	if !ok {
+		return 0, fmt.Errorf("a fonte %s não informa alturas de bloco, selecione o Nó Local (RPC)", sourceName())
+	}
+	height, err := heightSource.HeightAt(ctx, from)
+	if err != nil {
+		return 0, describeSourceError(err)
+	}
+	return height, nil
+}
+
+// sourceFindsHeights reports whether the selected source can find blocks by
+// time, which rescanStartHeight needs.
+func sourceFindsHeights() bool {
+	src, err := currentSource()
+	if err != nil {
+		return false
+	}
+	_, ok := src.(blockchain.HeightSource)
+	return ok
+}
+
+// rescanCommand returns the Bitcoin Core command that rescans a wallet from a
+// height.
+func rescanCommand(height int64) string {
+	return fmt.Sprintf("rescanblockchain %d", height)
+}
+
+// --- GUI ---
+
+// newRescanHeightBox shows a button that looks up the rescan start height of
+// the loaded seed, rescanned from the given time, on the local node, replaced
+// by the height and the rescan command once found.
+func newRescanHeightBox(from time.Time) fyne.CanvasObject {
+	box := container.NewVBox()
+	var button *widget.Button
+	button = widget.NewButtonWithIcon("Calcular Altura Inicial no Nó Local", theme.SearchIcon(), func() {
+		if selectedBackend == nil {
+			showStatus("Erro: Selecione o Nó Local (RPC) como fonte de dados.", true)
+			return
+		}
+		button.Disable()
+		showStatus(fmt.Sprintf("Buscando o bloco do aniversário da seed via %s...", sourceName()), false)
+		go func() {
+			height, err := rescanStartHeight(context.Background(), from)
+			fyne.Do(func() {
+				button.Enable()
+				if err != nil {
+					showStatus(fmt.Sprintf("Erro: %v", err), true)
+					return
+				}
+				box.Objects = []fyne.CanvasObject{
+					widget.NewLabel(fmt.Sprintf("Reescaneamento a partir do bloco %d.", height)),
+					newCopyRow("Comando", rescanCommand(height)),
+				}
+				box.Refresh()
+				showStatus(fmt.Sprintf("Altura inicial do reescaneamento: %d", height), false)
+			})
+		}()
+	})
+	box.Add(button)
+	return box
+}
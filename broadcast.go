@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/broadcast"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// prepareBroadcast decodes a raw hex transaction or a finalized PSBT, looks up
+// the outputs it spends on the selected source and verifies its signatures.
+// Transactions with invalid signatures are rejected.
+func prepareBroadcast(ctx context.Context, input string) (*broadcast.Tx, error) {
+	tx, err := broadcast.Decode(input)
+	switch {
+	case errors.Is(err, broadcast.ErrNotFinalized):
+		return nil, fmt.Errorf("a PSBT não está totalmente assinada")
+	case errors.Is(err, broadcast.ErrUnsigned):
+		return nil, fmt.Errorf("a transação possui entradas não assinadas")
+	case err != nil:
+		return nil, fmt.Errorf("transação inválida: %w", err)
+	}
+
+	src, err := currentSource()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.LookupPrevOuts(ctx, src); err != nil {
+		return nil, describeSourceError(err)
+	}
+
+	err = tx.Verify()
+	if err != nil && !errors.Is(err, broadcast.ErrUnknownPrevOuts) {
+		return nil, fmt.Errorf("assinatura inválida: %w", err)
+	}
+	if _, err := tx.Fee(); err != nil && !errors.Is(err, broadcast.ErrUnknownPrevOuts) {
+		return nil, fmt.Errorf("transação inválida: %w", err)
+	}
+	return tx, nil
+}
+
+// sendTransaction publishes a transaction through the selected source.
+func sendTransaction(ctx context.Context, tx *broadcast.Tx) (*chainhash.Hash, error) {
+	src, err := currentSource()
+	if err != nil {
+		return nil, err
+	}
+	txid, err := src.Broadcast(ctx, tx.MsgTx)
+	if err != nil {
+		return nil, describeSourceError(err)
+	}
+	log.Printf("Transação %s transmitida via %s.", txid, sourceName())
+	return txid, nil
+}
+
+// broadcastInputJSON is the JSON form of a transaction input.
+type broadcastInputJSON struct {
+	OutPoint string `json:"outpoint"`
+	ValueSat *int64 `json:"value_sat,omitempty"`
+}
+
+// broadcastOutputJSON is the JSON form of a transaction output.
+type broadcastOutputJSON struct {
+	Address  string `json:"address,omitempty"`
+	ValueSat int64  `json:"value_sat"`
+}
+
+// broadcastJSON is the JSON form of a transaction to broadcast.
+type broadcastJSON struct {
+	TxID        string                `json:"txid"`
+	VSize       int64                 `json:"vsize"`
+	Weight      int64                 `json:"weight"`
+	Inputs      []broadcastInputJSON  `json:"inputs"`
+	Outputs     []broadcastOutputJSON `json:"outputs"`
+	FeeSat      *int64                `json:"fee_sat,omitempty"`
+	FeeRate     *float64              `json:"fee_rate,omitempty"`
+	Verified    bool                  `json:"verified"`
+	Broadcasted bool                  `json:"broadcasted"`
+}
+
+func newBroadcastJSON(tx *broadcast.Tx) *broadcastJSON {
+	out := &broadcastJSON{
+		TxID:     tx.MsgTx.TxHash().String(),
+		VSize:    tx.VSize(),
+		Weight:   tx.Weight(),
+		Verified: tx.Verify() == nil,
+	}
+	for i, in := range tx.MsgTx.TxIn {
+		entry := broadcastInputJSON{OutPoint: in.PreviousOutPoint.String()}
+		if prevOut := tx.PrevOuts[i]; prevOut != nil {
+			value := prevOut.Value
+			entry.ValueSat = &value
+		}
+		out.Inputs = append(out.Inputs, entry)
+	}
+	for i, txOut := range tx.MsgTx.TxOut {
+		out.Outputs = append(out.Outputs, broadcastOutputJSON{
+			Address:  tx.OutputAddress(i, currentNetwork.Params),
+			ValueSat: txOut.Value,
+		})
+	}
+	if fee, err := tx.Fee(); err == nil {
+		feeSat := int64(fee)
+		feeRate, _ := tx.FeeRate()
+		out.FeeSat, out.FeeRate = &feeSat, &feeRate
+	}
+	return out
+}
+
+// writeBroadcastSummary writes a human-readable summary of a transaction.
+func writeBroadcastSummary(out io.Writer, tx *broadcast.Tx) {
+	j := newBroadcastJSON(tx)
+
+	fmt.Fprintf(out, "Transação: %s\n", j.TxID)
+	fmt.Fprintf(out, "Tamanho: %d vB (weight %d)\n", j.VSize, j.Weight)
+
+	fmt.Fprintf(out, "\nEntradas (%d):\n", len(j.Inputs))
+	for _, in := range j.Inputs {
+		value := "valor desconhecido"
+		if in.ValueSat != nil {
+			value = btcutil.Amount(*in.ValueSat).String()
+		}
+		fmt.Fprintf(out, "  %s  %s\n", in.OutPoint, value)
+	}
+
+	fmt.Fprintf(out, "\nSaídas (%d):\n", len(j.Outputs))
+	for _, o := range j.Outputs {
+		addr := o.Address
+		if addr == "" {
+			addr = "(script não padrão)"
+		}
+		fmt.Fprintf(out, "  %s  %s\n", addr, btcutil.Amount(o.ValueSat))
+	}
+
+	fmt.Fprintln(out)
+	if j.FeeSat != nil {
+		fmt.Fprintf(out, "Taxa: %s (%.2f sat/vB)\n", btcutil.Amount(*j.FeeSat), *j.FeeRate)
+	} else {
+		fmt.Fprintf(out, "Taxa: desconhecida (a fonte não forneceu as saídas gastas)\n")
+	}
+	if j.Verified {
+		fmt.Fprintf(out, "Assinaturas: válidas\n")
+	} else {
+		fmt.Fprintf(out, "Assinaturas: não verificadas (saídas gastas desconhecidas)\n")
+	}
+}
+
+// --- GUI ---
+
+// handleBroadcast asks for a raw transaction or a finalized PSBT to
+// broadcast through the selected source.
+func handleBroadcast() {
+	input := widget.NewMultiLineEntry()
+	input.SetPlaceHolder("Transação em hex ou PSBT finalizada (base64 ou hex)")
+	input.Wrapping = fyne.TextWrapBreak
+	input.SetMinRowsVisible(8)
+
+	dialog.ShowCustomConfirm("Transmitir Transação", "Analisar", "Cancelar", input, func(ok bool) {
+		if ok {
+			startBroadcast(input.Text)
+		}
+	}, mainWindow)
+}
+
+// startBroadcast decodes and checks a transaction in the background, then
+// asks for confirmation before sending it.
+func startBroadcast(input string) {
+	if strings.TrimSpace(input) == "" {
+		showStatus("Erro: Informe uma transação ou PSBT.", true)
+		return
+	}
+
+	showStatus(fmt.Sprintf("Analisando transação via %s...", sourceName()), false)
+	go func() {
+		tx, err := prepareBroadcast(context.Background(), input)
+		fyne.Do(func() {
+			if err != nil {
+				showStatus(fmt.Sprintf("Erro: %v", err), true)
+				return
+			}
+			confirmBroadcast(tx)
+		})
+	}()
+}
+
+// confirmBroadcast shows the summary of a transaction and sends it once
+// confirmed.
+func confirmBroadcast(tx *broadcast.Tx) {
+	var text strings.Builder
+	writeBroadcastSummary(&text, tx)
+	fmt.Fprintf(&text, "\nTransmitir via %s? Esta ação não pode ser desfeita.\n", sourceName())
+
+	summary := widget.NewMultiLineEntry()
+	summary.SetText(text.String())
+	summary.Wrapping = fyne.TextWrapOff
+	summary.Disable()
+	summaryScroll := container.NewScroll(summary)
+	summaryScroll.SetMinSize(fyne.NewSize(700, 400))
+	clearStatus()
+
+	dialog.ShowCustomConfirm("Confirmar Transmissão", "Transmitir", "Cancelar", summaryScroll, func(ok bool) {
+		if !ok {
+			showStatus("Transmissão cancelada.", false)
+			return
+		}
+		showStatus(fmt.Sprintf("Transmitindo via %s...", sourceName()), false)
+		go func() {
+			txid, err := sendTransaction(context.Background(), tx)
+			fyne.Do(func() {
+				if err != nil {
+					showStatus(fmt.Sprintf("Erro na transmissão: %v", err), true)
+					return
+				}
+				showBroadcastResult(txid)
+			})
+		}()
+	}, mainWindow)
+}
+
+// showBroadcastResult shows the ID of a broadcast transaction.
+func showBroadcastResult(txid *chainhash.Hash) {
+	txidEntry := widget.NewEntry()
+	txidEntry.SetText(txid.String())
+	copyButton := widget.NewButton("Copiar TXID", func() {
+		mainWindow.Clipboard().SetContent(txid.String())
+		showStatus("TXID copiado para a área de transferência.", false)
+	})
+	dialog.ShowCustom("Transação Transmitida", "Fechar", container.NewVBox(
+		widget.NewLabel("A transação foi aceita pela fonte:"),
+		txidEntry,
+		copyButton,
+	), mainWindow)
+	showStatus(fmt.Sprintf("Transação transmitida: %s", txid), false)
+}
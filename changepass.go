@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// envNewPassphrase allows scripts to hand over the new passphrase of a
+	// seed without exposing it in the process list.
+	envNewPassphrase = "AEZEED_NEW_PASSPHRASE"
+
+	// envConfirmPassphrase repeats envNewPassphrase, so a typo in either
+	// can't lock the seed under an unintended passphrase.
+	envConfirmPassphrase = "AEZEED_CONFIRM_PASSPHRASE"
+)
+
+// passphraseChange is a seed re-enciphered under a new passphrase.
+type passphraseChange struct {
+	// seed is the seed deciphered from the new mnemonic, verified to be
+	// the one of the original mnemonic.
+	seed     *crypto.CipherSeed
+	mnemonic crypto.Mnemonic
+}
+
+// changePassphrase re-enciphers a mnemonic under a new passphrase. The new
+// mnemonic is deciphered again and must hold the same seed as the original,
+// so a mistake can't lose it.
+func changePassphrase(mnemonicStr string, oldPass, newPass []byte) (*passphraseChange, error) {
+	mnemonic, err := parseMnemonic(mnemonicStr)
+	if err != nil {
+		return nil, err
+	}
+	newMnemonic, seed, err := mnemonic.ChangePassVerified(oldPass, newPass)
+	switch {
+	case errors.Is(err, crypto.ErrInvalidPass):
+		return nil, errors.New("a passphrase atual não decifra o mnemônico")
+	case errors.Is(err, crypto.ErrPassChangeMismatch):
+		return nil, fmt.Errorf("o novo mnemônico não confere com o original e foi descartado: %w", err)
+	case err != nil:
+		return nil, fmt.Errorf("erro ao decodificar mnemônico (verifique palavras e passphrase): %w", err)
+	}
+
+	return &passphraseChange{seed: seed, mnemonic: newMnemonic}, nil
+}
+
+// writePassphraseChange writes the new mnemonic of a passphrase change and
+// what was verified about it.
+func writePassphraseChange(out io.Writer, change *passphraseChange) {
+	fmt.Fprintf(out, "Novo mnemônico: %s\n", strings.Join(change.mnemonic[:], " "))
+	fmt.Fprintf(out, "Master Fingerprint: %s\n", seedFingerprint(change.seed))
+	fmt.Fprintf(out, "Aniversário: %s\n", formatBirthday(change.seed))
+	fmt.Fprintln(out, "Verificado: o novo mnemônico decifra com a nova passphrase para a mesma entropia, aniversário e versão interna do original.")
+	fmt.Fprintln(out, "O mnemônico original continua válido com a passphrase antiga; destrua as cópias dele se a troca for para revogá-la.")
+}
+
+// --- CLI ---
+
+func runPassphraseChange(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "passphrase change")
+	var flags seedFlags
+	flags.register(fs)
+	newPassphrase := fs.String("new-passphrase", "", "nova passphrase (vazia para o padrão 'aezeed'); também aceita a variável "+envNewPassphrase+", repetida em "+envConfirmPassphrase)
+	confirmPassphrase := fs.String("confirm-passphrase", "", "repetição da nova passphrase, exigida com --new-passphrase")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+
+	newPass, fromEnv := os.LookupEnv(envNewPassphrase)
+	switch {
+	case flagGiven(fs, "new-passphrase"):
+		if *confirmPassphrase != *newPassphrase {
+			return errors.New("--confirm-passphrase não confere com --new-passphrase")
+		}
+		newPass = *newPassphrase
+	case !fromEnv:
+		return fmt.Errorf("informe a nova passphrase com --new-passphrase ou %s", envNewPassphrase)
+	default:
+		confirm, ok := os.LookupEnv(envConfirmPassphrase)
+		if !ok {
+			return fmt.Errorf("repita a nova passphrase de %s em %s", envNewPassphrase, envConfirmPassphrase)
+		}
+		if confirm != newPass {
+			return fmt.Errorf("%s não confere com %s", envConfirmPassphrase, envNewPassphrase)
+		}
+	}
+
+	mnemonicStr, err := flags.readMnemonic(env)
+	if err != nil {
+		return err
+	}
+	oldPass := flags.passphraseBytes()
+	change, err := changePassphrase(mnemonicStr, oldPass, passphraseOrDefault(newPass))
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(oldPass, passphraseOrDefault(newPass)) {
+		fmt.Fprintln(env.stderr, "Aviso: a nova passphrase é igual à atual; o mnemônico não mudou.")
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, struct {
+			Mnemonic          []string `json:"mnemonic"`
+			MasterFingerprint string   `json:"master_fingerprint"`
+			Birthday          string   `json:"birthday"`
+			Verified          bool     `json:"verified"`
+		}{change.mnemonic[:], seedFingerprint(change.seed), change.seed.BirthdayTime().Format(birthdayLayout), true})
+	}
+	writePassphraseChange(env.stdout, change)
+	return nil
+}
+
+// --- GUI ---
+
+// showPassphraseChange asks for the current and the new passphrase of the
+// mnemonic in the entry, then shows it re-enciphered under the new one.
+func showPassphraseChange() {
+	mnemonicStr := mnemonicEntry.Text
+	if _, err := parseMnemonic(mnemonicStr); err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	oldEntry := widget.NewPasswordEntry()
+	oldEntry.SetText(passphraseEntry.Text)
+	oldEntry.SetPlaceHolder("Vazia para o padrão 'aezeed'")
+	newEntry := widget.NewPasswordEntry()
+	newEntry.SetPlaceHolder("Vazia para o padrão 'aezeed'")
+	confirmEntry := widget.NewPasswordEntry()
+
+	content := container.NewVBox(
+		widget.NewLabel("O mnemônico informado será cifrado com a nova passphrase, mantendo a entropia e o aniversário."),
+		widget.NewForm(
+			widget.NewFormItem("Passphrase atual:", oldEntry),
+			widget.NewFormItem("Nova passphrase:", newEntry),
+			widget.NewFormItem("Confirme a nova:", confirmEntry),
+		),
+	)
+	formDialog := dialog.NewCustomConfirm("Trocar Passphrase", "Trocar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if newEntry.Text != confirmEntry.Text {
+			showStatus("Erro: A confirmação não confere com a nova passphrase.", true)
+			return
+		}
+		oldPass := passphraseOrDefault(oldEntry.Text)
+		newPass := passphraseOrDefault(newEntry.Text)
+		newText := newEntry.Text
+
+		progressBar.Show()
+		showStatus("Cifrando o mnemônico com a nova passphrase...", false)
+		go func() {
+			change, err := changePassphrase(mnemonicStr, oldPass, newPass)
+			fyne.Do(func() {
+				progressBar.Hide()
+				if err != nil {
+					showStatus(fmt.Sprintf("Erro: %v", err), true)
+					return
+				}
+				showPassphraseChangeResult(change, newText)
+			})
+		}()
+	}, mainWindow)
+	formDialog.Resize(fyne.NewSize(550, 0))
+	formDialog.Show()
+}
+
+// showPassphraseChangeResult shows the new mnemonic of a passphrase change,
+// which can then be loaded with its passphrase.
+func showPassphraseChangeResult(change *passphraseChange, newPassphrase string) {
+	var text strings.Builder
+	writePassphraseChange(&text, change)
+	label := widget.NewLabel(text.String())
+	label.Wrapping = fyne.TextWrapWord
+
+	var resultDialog dialog.Dialog
+	content := container.NewVBox(
+		label,
+		newCopyRow("Novo mnemônico", strings.Join(change.mnemonic[:], " ")),
+		widget.NewButtonWithIcon("Usar Novo Mnemônico", theme.ConfirmIcon(), func() {
+			resultDialog.Hide()
+			mnemonicEntry.SetText(strings.Join(change.mnemonic[:], " "))
+			passphraseEntry.SetText(newPassphrase)
+			clearStatus()
+			decodeMnemonicAndAddresses()
+		}),
+	)
+	resultDialog = dialog.NewCustom("Passphrase Trocada", "Fechar", content, mainWindow)
+	resultDialog.Resize(fyne.NewSize(650, 0))
+	resultDialog.Show()
+	showStatus("Mnemônico cifrado com a nova passphrase e verificado.", false)
+}
@@ -0,0 +1,1378 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/descriptor"
+	"aezeed_address_generator_gui/internal/discovery"
+	"aezeed_address_generator_gui/internal/entropy"
+	"aezeed_address_generator_gui/internal/wallet"
+)
+
+const (
+	// envMnemonic and envPassphrase allow scripts to hand over secrets
+	// without exposing them in the process list.
+	envMnemonic   = "AEZEED_MNEMONIC"
+	envPassphrase = "AEZEED_PASSPHRASE"
+)
+
+// errUsage is returned by commands when their arguments are invalid. The
+// usage text has already been printed when it is returned.
+var errUsage = errors.New("uso incorreto")
+
+// cliEnv holds the streams a command reads from and writes to.
+type cliEnv struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// cliCommand describes a headless subcommand.
+type cliCommand struct {
+	name    string
+	summary string
+	run     func(env *cliEnv, args []string) error
+}
+
+// cliCommands lists every headless subcommand, in the order they are shown in
+// the usage text.
+var cliCommands = []cliCommand{
+	{"seed new", "gera uma nova seed aezeed e seu mnemônico", runSeedNew},
+	{"seed decode", "decodifica e valida um mnemônico aezeed", runSeedDecode},
+	{"seed analyze", "informa se uma frase é um mnemônico aezeed, BIP39 ou ambos", runSeedAnalyze},
+	{"seed recover", "recupera um mnemônico com uma palavra errada, ilegível (?) ou ausente", runSeedRecover},
+	{"passphrase recover", "procura a passphrase esquecida de um mnemônico", runPassphraseRecover},
+	{"passphrase change", "cifra um mnemônico com uma nova passphrase, mantendo a seed", runPassphraseChange},
+	{"xpub", "exibe a master fingerprint e as XPUBs da conta", runXpub},
+	{"descriptors", "exibe os descritores da conta e o JSON do importdescriptors", runDescriptors},
+	{"lnd keys", "exibe a chave pública do nó e as famílias de chaves LND", runLNDKeys},
+	{"addresses", "lista endereços derivados da seed", runAddresses},
+	{"backup decode", "decifra um channel.backup ou backup de canal do LND", runBackupDecode},
+	{"find", "procura um endereço dentro da seed", runFind},
+	{"check", "verifica o uso de endereços em uma fonte online", runCheck},
+	{"discover", "descobre os endereços usados de todas as contas (gap limit)", runDiscover},
+	{"sweep", "monta uma PSBT que envia todo o saldo da seed para um endereço", runSweep},
+	{"broadcast", "transmite uma transação assinada ou PSBT finalizada pela fonte online", runBroadcast},
+}
+
+// runCLI executes the headless command-line interface and returns the process
+// exit code.
+func runCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	env := &cliEnv{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	// Logging is only useful for troubleshooting, keep it off the
+	// terminal unless explicitly asked for.
+	log.SetOutput(io.Discard)
+	if len(args) > 0 && (args[0] == "-v" || args[0] == "--verbose") {
+		log.SetOutput(stderr)
+		args = args[1:]
+	}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printCLIUsage(stdout)
+		return 0
+	}
+
+	cmd, rest := lookupCommand(args)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "Comando desconhecido: %s\n\n", strings.Join(args, " "))
+		printCLIUsage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(env, rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "Erro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// lookupCommand finds the command named by the leading arguments, matching
+// the longest name first so "seed new" wins over a hypothetical "seed".
+func lookupCommand(args []string) (*cliCommand, []string) {
+	for i := range cliCommands {
+		cmd := &cliCommands[i]
+		parts := strings.Fields(cmd.name)
+		if len(args) < len(parts) {
+			continue
+		}
+		if strings.Join(args[:len(parts)], " ") == cmd.name {
+			return cmd, args[len(parts):]
+		}
+	}
+	return nil, nil
+}
+
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, "Uso:")
+	fmt.Fprintln(w, "  CONVERSOR_LND                         inicia a interface gráfica")
+	fmt.Fprintln(w, "  CONVERSOR_LND [-v] <comando> [opções]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Comandos:")
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use \"CONVERSOR_LND <comando> -h\" para ver as opções de cada comando.")
+	fmt.Fprintf(w, "O mnemônico pode ser passado por --mnemonic, pela variável %s ou pela entrada padrão.\n", envMnemonic)
+	fmt.Fprintf(w, "A passphrase pode ser passada por --passphrase ou pela variável %s.\n", envPassphrase)
+}
+
+// newFlagSet creates a flag set for the named command that reports errors to
+// the command's stderr instead of exiting the process.
+func newFlagSet(env *cliEnv, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+// parseFlags parses args allowing positional arguments to be interleaved with
+// flags, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// seedFlags are the options shared by every command that works on an
+// existing mnemonic.
+type seedFlags struct {
+	mnemonic   string
+	passphrase string
+	network    string
+	jsonOut    bool
+
+	// watch and watchPurpose load a watch-only wallet instead of a
+	// mnemonic, on the commands that don't need private keys.
+	watch        string
+	watchPurpose uint
+}
+
+func (f *seedFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.mnemonic, "mnemonic", "", "mnemônico aezeed de 24 palavras")
+	fs.StringVar(&f.passphrase, "passphrase", "", "passphrase da seed (padrão 'aezeed')")
+	fs.StringVar(&f.network, "network", wallet.MainNet.Name, "rede: mainnet, testnet, signet ou regtest")
+	fs.BoolVar(&f.jsonOut, "json", false, "saída em JSON")
+}
+
+// registerWatch adds the options that load a watch-only wallet from an
+// extended public key or descriptor instead of a mnemonic.
+func (f *seedFlags) registerWatch(fs *flag.FlagSet) {
+	fs.StringVar(&f.watch, "watch", "", "modo somente leitura: xpub/ypub/zpub da conta ou descritor, no lugar do mnemônico")
+	fs.UintVar(&f.watchPurpose, "watch-purpose", uint(defaultWatchPurpose), "propósito BIP de uma xpub em --watch: 44, 49, 84 ou 86")
+}
+
+// passphraseBytes resolves the passphrase from the flag or the environment,
+// falling back to the aezeed default.
+func (f *seedFlags) passphraseBytes() []byte {
+	if f.passphrase == "" {
+		return passphraseOrDefault(os.Getenv(envPassphrase))
+	}
+	return passphraseOrDefault(f.passphrase)
+}
+
+// selectNetwork resolves the network flag and makes it the current network.
+func (f *seedFlags) selectNetwork() error {
+	n, err := wallet.NetworkByName(f.network)
+	if err != nil {
+		return fmt.Errorf("rede inválida %q (use mainnet, testnet, signet ou regtest)", f.network)
+	}
+	currentNetwork = n
+	return nil
+}
+
+// readMnemonic resolves the mnemonic from the flag, the environment or stdin,
+// in that order.
+func (f *seedFlags) readMnemonic(env *cliEnv) (string, error) {
+	if f.mnemonic != "" {
+		return f.mnemonic, nil
+	}
+	if mnemonicStr := os.Getenv(envMnemonic); mnemonicStr != "" {
+		return mnemonicStr, nil
+	}
+	input, err := io.ReadAll(env.stdin)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler mnemônico da entrada padrão: %w", err)
+	}
+	return string(input), nil
+}
+
+// loadSeed resolves the mnemonic as readMnemonic does and decodes it into a
+// cipher seed and its wallet.
+func (f *seedFlags) loadSeed(env *cliEnv) (*crypto.CipherSeed, *wallet.Wallet, error) {
+	if err := f.selectNetwork(); err != nil {
+		return nil, nil, err
+	}
+
+	mnemonicStr, err := f.readMnemonic(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seed, err := decodeMnemonic(mnemonicStr, f.passphraseBytes())
+	if err != nil && analyzeMnemonic(mnemonicStr).isBIP39() {
+		return nil, nil, errors.New("a frase é um mnemônico BIP39, não aezeed: este comando exige aezeed (use \"seed analyze\" para detalhes)")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, err := wallet.New(seed, currentNetwork)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao derivar chave mestra: %w", err)
+	}
+	return seed, w, nil
+}
+
+// loadWallet loads the watch-only wallet of --watch if given, otherwise the
+// wallet of the mnemonic, which may also be a BIP39 mnemonic. The seed is nil
+// for watch-only and BIP39 wallets.
+func (f *seedFlags) loadWallet(env *cliEnv) (*crypto.CipherSeed, *wallet.Wallet, error) {
+	if err := f.selectNetwork(); err != nil {
+		return nil, nil, err
+	}
+	if f.watch != "" {
+		w, err := loadWatchOnly(f.watch, currentNetwork, uint32(f.watchPurpose))
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, w, nil
+	}
+
+	mnemonicStr, err := f.readMnemonic(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format := analyzeMnemonic(mnemonicStr); format.isBIP39() {
+		_, w, err := newBIP39Wallet(format.words, f.rawPassphrase(), currentNetwork)
+		return nil, w, err
+	}
+	f.mnemonic = mnemonicStr
+	return f.loadSeed(env)
+}
+
+// rawPassphrase resolves the passphrase from the flag or the environment,
+// without the aezeed default, as BIP39 passphrases are used.
+func (f *seedFlags) rawPassphrase() string {
+	if f.passphrase == "" {
+		return os.Getenv(envPassphrase)
+	}
+	return f.passphrase
+}
+
+// flagGiven reports whether the named flag was set on the command line.
+func flagGiven(fs *flag.FlagSet, name string) bool {
+	given := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// walletAccount returns the account of the --account flag, defaulting to the
+// watched account of watch-only wallets.
+func walletAccount(fs *flag.FlagSet, w *wallet.Wallet, account uint32) uint32 {
+	if _, watched, ok := w.WatchedAccount(); ok && !flagGiven(fs, "account") {
+		return watched
+	}
+	return account
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parsePurposes parses a purpose selector: either a single BIP number (44,
+// 49, 84 or 86) or "all".
+func parsePurposes(s string) ([]wallet.PurposeInfo, error) {
+	if s == "all" {
+		return wallet.Purposes, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "bip"), 10, 32)
+	if err == nil {
+		for _, p := range wallet.Purposes {
+			if p.Purpose == uint32(n) {
+				return []wallet.PurposeInfo{p}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("propósito inválido %q (use 44, 49, 84, 86 ou all)", s)
+}
+
+// parseChain validates a change chain selector.
+func parseChain(chain uint) (uint32, error) {
+	if chain != uint(wallet.ExternalChain) && chain != uint(wallet.InternalChain) {
+		return 0, fmt.Errorf("change inválido %d (use 0 ou 1)", chain)
+	}
+	return uint32(chain), nil
+}
+
+// parseAccount validates an account number.
+func parseAccount(account uint) (uint32, error) {
+	if account > uint(wallet.MaxAccount) {
+		return 0, fmt.Errorf("conta inválida %d (máximo %d)", account, wallet.MaxAccount)
+	}
+	return uint32(account), nil
+}
+
+// pathFlags are the options that select a custom derivation path template
+// instead of the standard paths.
+type pathFlags struct {
+	path   string
+	script string
+}
+
+func (f *pathFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "path", "", "caminho personalizado com * no lugar do índice, ex.: m/84'/0'/0'/0/* (substitui --purpose, --account e --change)")
+	fs.StringVar(&f.script, "script", defaultCustomScript, "tipo de script do caminho personalizado: p2pkh, p2sh-p2wpkh, p2wpkh ou p2tr")
+}
+
+// template parses the custom path, returning nil if none was given.
+func (f *pathFlags) template() (*wallet.PathTemplate, wallet.ScriptType, error) {
+	if f.path == "" {
+		return nil, wallet.ScriptType{}, nil
+	}
+	return parseCustomPath(f.path, f.script)
+}
+
+// sourceFlags are the options that select and configure an online
+// blockchain source. The option flags are generated from the registered
+// backends.
+type sourceFlags struct {
+	fs     *flag.FlagSet
+	source string
+
+	// values and bools hold the option flags by backend ID and option key.
+	values map[string]map[string]*string
+	bools  map[string]map[string]*bool
+
+	proxy    string
+	proxyAll bool
+}
+
+func (f *sourceFlags) register(fs *flag.FlagSet, defaultSource string) {
+	f.fs = fs
+	fs.StringVar(&f.source, "source", defaultSource, "fonte de dados: "+sourceIDs())
+	fs.StringVar(&f.proxy, "proxy", "", "proxy SOCKS5 host:porta (ex.: Tor em 127.0.0.1:9050), usado para endereços .onion")
+	fs.BoolVar(&f.proxyAll, "proxy-all", false, "usar o proxy para todas as conexões, não apenas .onion")
+
+	f.values = make(map[string]map[string]*string)
+	f.bools = make(map[string]map[string]*bool)
+	for _, b := range blockchainSources.Backends() {
+		f.values[b.ID] = make(map[string]*string)
+		f.bools[b.ID] = make(map[string]*bool)
+		for _, opt := range b.Options {
+			if opt.Bool {
+				f.bools[b.ID][opt.Key] = fs.Bool(opt.Flag, false, opt.Usage)
+			} else {
+				f.values[b.ID][opt.Key] = fs.String(opt.Flag, "", opt.Usage)
+			}
+		}
+	}
+}
+
+// apply validates the source selection and configures the sources,
+// returning the selected backend, or nil for offline. The settings saved by
+// the GUI are used for the flags that aren't given, and the defaults of the
+// current network for options set nowhere.
+func (f *sourceFlags) apply() (*blockchain.Backend, error) {
+	var backend *blockchain.Backend
+	if !strings.EqualFold(f.source, "offline") {
+		b, err := blockchainSources.ByID(f.source)
+		if err != nil {
+			return nil, fmt.Errorf("fonte inválida %q (use %s)", f.source, sourceIDs())
+		}
+		backend = b
+	}
+
+	settings, _ := loadSettings()
+	set := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	for _, b := range blockchainSources.Backends() {
+		for _, opt := range b.Options {
+			if !set[opt.Flag] {
+				continue
+			}
+			value := ""
+			switch {
+			case opt.Bool && *f.bools[b.ID][opt.Key]:
+				value = "true"
+			case !opt.Bool:
+				value = *f.values[b.ID][opt.Key]
+			}
+			setSourceOption(b, opt.Key, value)
+		}
+	}
+
+	proxy := settings.Proxy
+	if set["proxy"] {
+		proxy.Addr = f.proxy
+	}
+	if set["proxy-all"] {
+		proxy.All = f.proxyAll
+	}
+	setSourceProxy(proxy.Addr, proxy.All)
+
+	selectedBackend = backend
+	return backend, nil
+}
+
+func runSeedNew(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "seed new")
+	passphrase := fs.String("passphrase", "", "passphrase da nova seed (padrão 'aezeed')")
+	network := fs.String("network", wallet.MainNet.Name, "rede: mainnet, testnet, signet ou regtest")
+	jsonOut := fs.Bool("json", false, "saída em JSON")
+	hexEntropy := fs.String("hex", "", "entropia em hexadecimal: 16 bytes, ou mais para aplicar hash")
+	dice := fs.String("dice", "", "lançamentos de um dado de 6 faces (1 a 6), no mínimo 50")
+	coins := fs.String("coins", "", "lançamentos de moeda (0/1 ou H/T), no mínimo 128")
+	birthdayStr := fs.String("birthday", "", "aniversário da seed: data AAAA-MM-DD ou altura de bloco (padrão hoje)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	flags := seedFlags{passphrase: *passphrase, network: *network}
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+	birthday, err := parseBirthday(*birthdayStr, currentNetwork, time.Now())
+	if err != nil {
+		return err
+	}
+
+	type entropyInput struct {
+		kind  entropy.Kind
+		value string
+	}
+	var inputs []entropyInput
+	for _, input := range []entropyInput{{entropy.Hex, *hexEntropy}, {entropy.Dice, *dice}, {entropy.Coins, *coins}} {
+		if input.value != "" {
+			inputs = append(inputs, input)
+		}
+	}
+	if len(inputs) > 1 {
+		return errors.New("use apenas uma fonte de entropia: --hex, --dice ou --coins")
+	}
+	var (
+		kind   entropy.Kind
+		result *entropy.Result
+	)
+	if len(inputs) == 1 {
+		kind = inputs[0].kind
+		if result, err = parseEntropy(kind, inputs[0].value); err != nil {
+			return err
+		}
+	}
+
+	var seedEntropy *[crypto.EntropySize]byte
+	if result != nil {
+		seedEntropy = &result.Entropy
+	}
+	seed, mnemonic, err := createNewSeed(seedEntropy, birthday, flags.passphraseBytes())
+	if err != nil {
+		return err
+	}
+	w, err := wallet.New(seed, currentNetwork)
+	if err != nil {
+		return fmt.Errorf("erro ao derivar chave mestra: %w", err)
+	}
+	fingerprint, err := w.MasterFingerprint()
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		out := struct {
+			Mnemonic          []string           `json:"mnemonic"`
+			MasterFingerprint string             `json:"master_fingerprint"`
+			Birthday          string             `json:"birthday"`
+			BirthdayHeight    *int64             `json:"birthday_height_estimate,omitempty"`
+			Entropy           *entropyReportJSON `json:"entropy,omitempty"`
+		}{mnemonic[:], fingerprint, seed.BirthdayTime().Format(birthdayLayout), birthdayHeightJSON(seed), nil}
+		if result != nil {
+			out.Entropy = newEntropyReportJSON(kind, result)
+		}
+		return writeJSON(env.stdout, out)
+	}
+
+	fmt.Fprintf(env.stdout, "Mnemônico: %s\n", strings.Join(mnemonic[:], " "))
+	fmt.Fprintf(env.stdout, "Master Fingerprint: %s\n", fingerprint)
+	fmt.Fprintf(env.stdout, "Aniversário: %s\n", formatBirthday(seed))
+	if result != nil {
+		fmt.Fprintln(env.stdout)
+		writeEntropyReport(env.stdout, kind, result)
+	}
+	return nil
+}
+
+func runSeedDecode(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "seed decode")
+	var flags seedFlags
+	flags.register(fs)
+	var src sourceFlags
+	src.register(fs, "offline")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	seed, w, err := flags.loadSeed(env)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := w.MasterFingerprint()
+	if err != nil {
+		return err
+	}
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+
+	// With the local node, the birthday also tells the block rescans
+	// can start from. Other sources can't find blocks by time, so the
+	// height is left out.
+	var startHeight *int64
+	if source != nil && sourceFindsHeights() {
+		height, err := rescanStartHeight(context.Background(), seed.RescanTime())
+		if err != nil {
+			return err
+		}
+		startHeight = &height
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, struct {
+			Valid             bool   `json:"valid"`
+			Version           uint8  `json:"version"`
+			InternalVersion   uint8  `json:"internal_version"`
+			MasterFingerprint string `json:"master_fingerprint"`
+			Birthday          string `json:"birthday"`
+			BirthdayDays      uint16 `json:"birthday_days"`
+			BirthdayHeight    *int64 `json:"birthday_height_estimate,omitempty"`
+			StartHeight       *int64 `json:"start_height,omitempty"`
+		}{true, seed.Version(), seed.InternalVersion, fingerprint, seed.BirthdayTime().Format(birthdayLayout), seed.Birthday, birthdayHeightJSON(seed), startHeight})
+	}
+
+	fmt.Fprintln(env.stdout, "Mnemônico decodificado com sucesso!")
+	fmt.Fprintf(env.stdout, "Versão do aezeed: %d\n", seed.Version())
+	fmt.Fprintf(env.stdout, "Versão interna: %d\n", seed.InternalVersion)
+	fmt.Fprintf(env.stdout, "Master Fingerprint: %s\n", fingerprint)
+	fmt.Fprintf(env.stdout, "Aniversário: %s\n", formatBirthday(seed))
+	if startHeight != nil {
+		fmt.Fprintf(env.stdout, "Altura inicial do reescaneamento: %d (%s)\n", *startHeight, rescanCommand(*startHeight))
+	}
+	return nil
+}
+
+func runXpub(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "xpub")
+	var flags seedFlags
+	flags.register(fs)
+	accountFlag := fs.Uint("account", uint(wallet.DefaultAccount), "número da conta")
+	slip132 := fs.Bool("slip132", false, "exibir as chaves BIP49 e BIP84 como ypub e zpub (upub e vpub nas redes de teste)")
+	flags.registerWatch(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	account, err := parseAccount(*accountFlag)
+	if err != nil {
+		return err
+	}
+
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	account = walletAccount(fs, w, account)
+	accountXpub := w.AccountXpub
+	if *slip132 {
+		accountXpub = w.AccountSLIP132
+	}
+	// Watch-only wallets only know the fingerprint of descriptors with
+	// a key origin.
+	fingerprint, err := w.MasterFingerprint()
+	if errors.Is(err, wallet.ErrWatchOnly) {
+		fingerprint = ""
+	} else if err != nil {
+		return err
+	}
+
+	type xpubEntry struct {
+		Purpose uint32 `json:"purpose"`
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Xpub    string `json:"xpub"`
+	}
+	var xpubs []xpubEntry
+	for _, p := range w.Purposes() {
+		xpub, err := accountXpub(p.Purpose, account)
+		if err != nil {
+			return fmt.Errorf("%s: erro ao derivar XPUB: %w", p.Name, err)
+		}
+		xpubs = append(xpubs, xpubEntry{
+			Purpose: p.Purpose,
+			Name:    p.Name,
+			Path:    w.AccountPath(p.Purpose, account),
+			Xpub:    xpub,
+		})
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, struct {
+			MasterFingerprint string      `json:"master_fingerprint,omitempty"`
+			WatchOnly         bool        `json:"watch_only,omitempty"`
+			Xpubs             []xpubEntry `json:"xpubs"`
+		}{fingerprint, w.WatchOnly(), xpubs})
+	}
+
+	if fingerprint == "" {
+		fmt.Fprintln(env.stdout, "Master Fingerprint: desconhecida (somente leitura)")
+	} else {
+		fmt.Fprintf(env.stdout, "Master Fingerprint: %s\n", fingerprint)
+	}
+	for _, x := range xpubs {
+		fmt.Fprintf(env.stdout, "%s %s:\n  %s\n", x.Name, x.Path, x.Xpub)
+	}
+	return nil
+}
+
+func runDescriptors(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "descriptors")
+	var flags seedFlags
+	flags.register(fs)
+	accountFlag := fs.Uint("account", uint(wallet.DefaultAccount), "número da conta")
+	importOut := fs.Bool("import", false, "saída no formato do importdescriptors do Bitcoin Core, reescaneando a partir do aniversário da seed")
+	flags.registerWatch(fs)
+	var src sourceFlags
+	src.register(fs, "offline")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	account, err := parseAccount(*accountFlag)
+	if err != nil {
+		return err
+	}
+
+	seed, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	account = walletAccount(fs, w, account)
+	accounts, err := descriptor.ForWallet(w, account)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar descritores: %w", err)
+	}
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+
+	// With the local node, wallets that already have the descriptors can
+	// be rescanned from the block of the birthday. The hint goes to stderr
+	// unless the output is meant for people, so it can't break the JSON.
+	if source != nil && seed != nil && sourceFindsHeights() {
+		height, err := rescanStartHeight(context.Background(), seed.RescanTime())
+		if err != nil {
+			return err
+		}
+		hintOut := env.stderr
+		if !*importOut && !flags.jsonOut {
+			hintOut = env.stdout
+		}
+		fmt.Fprintf(hintOut, "Altura inicial do reescaneamento: %d (%s)\n", height, rescanCommand(height))
+	}
+
+	if *importOut {
+		birthday, _ := descriptorBirthday(seed)
+		importJSON, err := importDescriptorsJSON(accounts, birthday)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(env.stdout, importJSON)
+		return nil
+	}
+
+	if flags.jsonOut {
+		type descriptorEntry struct {
+			Purpose uint32 `json:"purpose"`
+			Account uint32 `json:"account"`
+			Receive string `json:"receive"`
+			Change  string `json:"change"`
+		}
+		entries := make([]descriptorEntry, len(accounts))
+		for i, a := range accounts {
+			entries[i] = descriptorEntry{a.Purpose, a.Account, a.Receive, a.Change}
+		}
+		return writeJSON(env.stdout, entries)
+	}
+
+	for i, a := range accounts {
+		fmt.Fprintf(env.stdout, "%s:\n", w.Purposes()[i].Name)
+		fmt.Fprintf(env.stdout, "  Recebimento: %s\n", a.Receive)
+		fmt.Fprintf(env.stdout, "  Troco:       %s\n", a.Change)
+	}
+	return nil
+}
+
+func runLNDKeys(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "lnd keys")
+	var flags seedFlags
+	flags.register(fs)
+	index := fs.Uint("index", 0, "índice da chave em cada família")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	_, w, err := flags.loadSeed(env)
+	if err != nil {
+		return err
+	}
+	nodePub, err := w.NodePubKey()
+	if err != nil {
+		return fmt.Errorf("erro ao derivar chave do nó: %w", err)
+	}
+	nodePubHex := hex.EncodeToString(nodePub.SerializeCompressed())
+
+	type familyEntry struct {
+		Family uint32 `json:"family"`
+		Name   string `json:"name"`
+		Path   string `json:"path"`
+		PubKey string `json:"pubkey"`
+	}
+	var families []familyEntry
+	for _, f := range wallet.KeyFamilies {
+		key, err := w.DeriveLNDKey(f.Family, uint32(*index))
+		if err != nil {
+			return fmt.Errorf("%s: erro ao derivar chave: %w", f.Name, err)
+		}
+		families = append(families, familyEntry{
+			Family: uint32(f.Family),
+			Name:   f.Name,
+			Path:   key.Path,
+			PubKey: hex.EncodeToString(key.PubKey.SerializeCompressed()),
+		})
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, struct {
+			NodePubKey string        `json:"node_pubkey"`
+			Families   []familyEntry `json:"families"`
+		}{nodePubHex, families})
+	}
+
+	fmt.Fprintf(env.stdout, "Chave Pública do Nó: %s\n", nodePubHex)
+	for _, f := range families {
+		fmt.Fprintf(env.stdout, "%d %s %s:\n  %s\n", f.Family, f.Name, f.Path, f.PubKey)
+	}
+	return nil
+}
+
+func runAddresses(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "addresses")
+	var flags seedFlags
+	flags.register(fs)
+	purposeStr := fs.String("purpose", "all", "propósito BIP: 44, 49, 84, 86 ou all")
+	accountFlag := fs.Uint("account", uint(wallet.DefaultAccount), "número da conta")
+	change := fs.Uint("change", uint(wallet.ExternalChain), "cadeia: 0 (externa) ou 1 (interna)")
+	start := fs.Uint("start", 0, "índice inicial")
+	count := fs.Uint("count", AddressBatchSize, "quantidade de endereços")
+	var pathOpts pathFlags
+	pathOpts.register(fs)
+	flags.registerWatch(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	purposes, err := parsePurposes(*purposeStr)
+	if err != nil {
+		return err
+	}
+	account, err := parseAccount(*accountFlag)
+	if err != nil {
+		return err
+	}
+	chain, err := parseChain(*change)
+	if err != nil {
+		return err
+	}
+	template, script, err := pathOpts.template()
+	if err != nil {
+		return err
+	}
+
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	if template != nil && w.WatchOnly() {
+		return errCustomPathWatchOnly
+	}
+	if *purposeStr == "all" {
+		purposes = w.Purposes()
+	}
+	account = walletAccount(fs, w, account)
+
+	type addressEntry struct {
+		Purpose uint32 `json:"purpose"`
+		Path    string `json:"path"`
+		Address string `json:"address"`
+	}
+	var entries []addressEntry
+	if template != nil {
+		addrs, err := w.DeriveTemplateAddresses(template, script, uint32(*start), uint32(*count))
+		if err != nil {
+			return fmt.Errorf("caminho %s: %w", template, err)
+		}
+		for _, derived := range addrs {
+			entries = append(entries, addressEntry{
+				Purpose: derived.Purpose,
+				Path:    derived.Path,
+				Address: derived.Address.String(),
+			})
+		}
+	} else {
+		for _, p := range purposes {
+			for i := uint32(0); i < uint32(*count); i++ {
+				index := uint32(*start) + i
+				derived, err := w.DeriveAddress(p.Purpose, account, chain, index)
+				if err != nil {
+					return fmt.Errorf("%s índice %d: %w", p.Name, index, err)
+				}
+				entries = append(entries, addressEntry{
+					Purpose: p.Purpose,
+					Path:    derived.Path,
+					Address: derived.Address.String(),
+				})
+			}
+		}
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, entries)
+	}
+	for _, e := range entries {
+		fmt.Fprintf(env.stdout, "%-24s %s\n", e.Path, e.Address)
+	}
+	return nil
+}
+
+func runBackupDecode(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "backup decode")
+	var flags seedFlags
+	flags.register(fs)
+	single := fs.Bool("single", false, "o arquivo contém o backup de um único canal (lncli exportchanbackup --chan_point)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.stderr, "Uso: backup decode [opções] <arquivo|->")
+		fs.PrintDefaults()
+		return errUsage
+	}
+
+	data, err := readBackupFile(positional[0], env.stdin)
+	if err != nil {
+		return fmt.Errorf("erro ao ler backup: %w", err)
+	}
+	_, w, err := flags.loadSeed(env)
+	if err != nil {
+		return err
+	}
+	infos, err := decodeChannelBackup(w, data, *single)
+	if err != nil {
+		return err
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, infos)
+	}
+	writeChannelBackups(env.stdout, infos)
+	return nil
+}
+
+func runFind(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "find")
+	var flags seedFlags
+	flags.register(fs)
+	var src sourceFlags
+	src.register(fs, "offline")
+	limit := fs.Uint("limit", uint(addressSearchLimit), "índice máximo buscado por derivação")
+	accounts := fs.Uint("accounts", 1, "número de contas buscadas, a partir da conta 0")
+	var pathOpts pathFlags
+	pathOpts.register(fs)
+	flags.registerWatch(fs)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.stderr, "Uso: find [opções] <endereço>")
+		fs.PrintDefaults()
+		return errUsage
+	}
+	targetAddrStr := positional[0]
+	if *accounts == 0 {
+		return fmt.Errorf("--accounts deve ser maior que zero")
+	}
+	lastAccount, err := parseAccount(*accounts - 1)
+	if err != nil {
+		return err
+	}
+	template, script, err := pathOpts.template()
+	if err != nil {
+		return err
+	}
+
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	if template != nil && w.WatchOnly() {
+		return errCustomPathWatchOnly
+	}
+	lastAccount = lookupLastAccount(w, lastAccount)
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+
+	findResult, err := findAddressInSeed(w, targetAddrStr, lastAccount, uint32(*limit))
+	if err != nil {
+		return err
+	}
+	if findResult == nil && template != nil {
+		findResult, err = findAddressInTemplate(w, targetAddrStr, template, script, uint32(*limit))
+		if err != nil {
+			return err
+		}
+	}
+
+	var onlineInfo, onlineErr string
+	if source != nil {
+		info, err := lookupOnlineInfo(targetAddrStr)
+		if err != nil {
+			onlineErr = err.Error()
+		} else {
+			onlineInfo = info
+		}
+	}
+
+	var path string
+	if findResult != nil {
+		path = findResult.Path
+	}
+
+	if flags.jsonOut {
+		return writeJSON(env.stdout, struct {
+			Address     string `json:"address"`
+			Found       bool   `json:"found"`
+			Path        string `json:"path,omitempty"`
+			OnlineInfo  string `json:"online_info,omitempty"`
+			OnlineError string `json:"online_error,omitempty"`
+		}{targetAddrStr, findResult != nil, path, onlineInfo, onlineErr})
+	}
+
+	if findResult != nil {
+		fmt.Fprintf(env.stdout, "Endereço ENCONTRADO: %s\n", path)
+	} else {
+		fmt.Fprintf(env.stdout, "Endereço NÃO encontrado na seed (contas 0 a %d, limite de busca: %d por derivação).\n", lastAccount, *limit)
+		if template != nil {
+			fmt.Fprintf(env.stdout, "Caminho personalizado %s (%s) também buscado.\n", template, script.Name)
+		}
+	}
+	if onlineErr != "" {
+		fmt.Fprintf(env.stdout, "Erro na verificação online: %s\n", onlineErr)
+	} else if onlineInfo != "" {
+		fmt.Fprintf(env.stdout, "Info Online: %s\n", onlineInfo)
+	}
+	return nil
+}
+
+func runCheck(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "check")
+	var flags seedFlags
+	flags.register(fs)
+	var src sourceFlags
+	src.register(fs, "blockstream")
+	purposeStr := fs.String("purpose", "84", "propósito BIP: 44, 49, 84 ou 86")
+	accountFlag := fs.Uint("account", uint(wallet.DefaultAccount), "número da conta")
+	change := fs.Uint("change", uint(wallet.ExternalChain), "cadeia: 0 (externa) ou 1 (interna)")
+	start := fs.Uint("start", 0, "índice inicial")
+	count := fs.Uint("count", AddressBatchSize, "quantidade de endereços")
+	var pathOpts pathFlags
+	pathOpts.register(fs)
+	flags.registerWatch(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	purposes, err := parsePurposes(*purposeStr)
+	if err != nil {
+		return err
+	}
+	if len(purposes) != 1 {
+		return fmt.Errorf("check aceita apenas um propósito por vez")
+	}
+	account, err := parseAccount(*accountFlag)
+	if err != nil {
+		return err
+	}
+	chain, err := parseChain(*change)
+	if err != nil {
+		return err
+	}
+	template, script, err := pathOpts.template()
+	if err != nil {
+		return err
+	}
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	if template != nil && w.WatchOnly() {
+		return errCustomPathWatchOnly
+	}
+	if w.WatchOnly() && !flagGiven(fs, "purpose") {
+		purposes = w.Purposes()
+	}
+	account = walletAccount(fs, w, account)
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("verificação requer uma fonte online")
+	}
+
+	scope := fmt.Sprintf("%s, conta %d", purposes[0].Name, account)
+	var results []addressCheckResult
+	if template != nil {
+		scope = fmt.Sprintf("%s, %s", template, scriptTypeLabels[script.Name])
+		results, err = checkTemplateBatch(w, template, script, uint32(*start), uint32(*count), nil)
+	} else {
+		results, err = checkAddressBatch(
+			w, purposes[0].Purpose, account, chain, uint32(*start),
+			uint32(*count), nil,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	type checkEntry struct {
+		Index   uint32 `json:"index"`
+		Address string `json:"address"`
+		Info    string `json:"info,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	entries := make([]checkEntry, len(results))
+	errorCount := 0
+	for i, r := range results {
+		entries[i] = checkEntry{Index: r.Index, Address: r.Address.String(), Info: r.Info}
+		if r.Err != nil {
+			entries[i].Error = r.Err.Error()
+			errorCount++
+		}
+	}
+
+	if flags.jsonOut {
+		if err := writeJSON(env.stdout, entries); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(env.stdout, "Resultados da Verificação para %s (Fonte: %s):\n", scope, source.Name)
+		for _, e := range entries {
+			if e.Error != "" {
+				fmt.Fprintf(env.stdout, "Índice %d: Erro - %s\n", e.Index, e.Error)
+			} else {
+				fmt.Fprintf(env.stdout, "Índice %d (%s): %s\n", e.Index, e.Address, e.Info)
+			}
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%d erros ocorreram durante a verificação", errorCount)
+	}
+	return nil
+}
+
+func runDiscover(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "discover")
+	var flags seedFlags
+	flags.register(fs)
+	var src sourceFlags
+	src.register(fs, "blockstream")
+	purposeStr := fs.String("purpose", "all", "propósitos BIP: all, 44, 49, 84 ou 86")
+	gapLimit := fs.Uint("gap", uint(discovery.DefaultGapLimit), "endereços sem uso consecutivos que encerram uma cadeia")
+	maxAccounts := fs.Uint("accounts", uint(discovery.DefaultMaxAccounts), "número máximo de contas por propósito")
+	flags.registerWatch(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *gapLimit == 0 || *maxAccounts == 0 {
+		return fmt.Errorf("--gap e --accounts devem ser maiores que zero")
+	}
+
+	purposes, err := parsePurposes(*purposeStr)
+	if err != nil {
+		return err
+	}
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	if _, err := src.apply(); err != nil {
+		return err
+	}
+
+	cfg, err := newDiscoveryConfig(w, uint32(*gapLimit), uint32(*maxAccounts))
+	if err != nil {
+		return err
+	}
+	for _, p := range purposes {
+		cfg.Purposes = append(cfg.Purposes, p.Purpose)
+	}
+	cfg.Progress = func(p discovery.Progress) {
+		fmt.Fprintf(env.stderr, "\rBIP%d conta %d change %d índice %d (verificados: %d, usados: %d)",
+			p.Purpose, p.Account, p.Chain, p.Index, p.Checked, p.Used)
+	}
+
+	// Ctrl+C stops the scan and still prints the partial report.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := discovery.Scan(ctx, cfg)
+	fmt.Fprintln(env.stderr)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		return err
+	}
+
+	if flags.jsonOut {
+		out := newDiscoveryJSON(report)
+		out.Canceled = canceled
+		return writeJSON(env.stdout, out)
+	}
+	if canceled {
+		fmt.Fprintln(env.stdout, "Descoberta CANCELADA. Resultado parcial:")
+	}
+	writeDiscoveryReport(env.stdout, report)
+	return nil
+}
+
+// stringList is a flag that can be given multiple times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runSweep(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "sweep")
+	var flags seedFlags
+	flags.register(fs)
+	var src sourceFlags
+	src.register(fs, "blockstream")
+	to := fs.String("to", "", "endereço de destino (obrigatório)")
+	feeRateStr := fs.String("feerate", strconv.FormatFloat(defaultSweepFeeRate, 'f', -1, 64), "taxa em sat/vB")
+	sign := fs.Bool("sign", false, "assinar a PSBT com a seed (padrão: PSBT não assinada para um assinador externo; indisponível com --watch)")
+	var addresses stringList
+	fs.Var(&addresses, "address", "endereço da seed a varrer, buscado nas contas de --accounts (pode ser repetido; padrão: descoberta completa)")
+	gapLimit := fs.Uint("gap", uint(discovery.DefaultGapLimit), "gap limit da descoberta")
+	maxAccounts := fs.Uint("accounts", uint(discovery.DefaultMaxAccounts), "número máximo de contas por propósito na descoberta")
+	flags.registerWatch(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *to == "" {
+		fmt.Fprintln(env.stderr, "Uso: sweep --to <endereço> [opções]")
+		fs.PrintDefaults()
+		return errUsage
+	}
+	if *gapLimit == 0 || *maxAccounts == 0 {
+		return fmt.Errorf("--gap e --accounts devem ser maiores que zero")
+	}
+	feeRate, err := parseFeeRate(*feeRateStr)
+	if err != nil {
+		return err
+	}
+
+	_, w, err := flags.loadWallet(env)
+	if err != nil {
+		return err
+	}
+	if *sign && w.WatchOnly() {
+		return errSignWatchOnly
+	}
+	dest, err := decodeDestination(w, *to)
+	if err != nil {
+		return err
+	}
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("a varredura requer uma fonte online")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var addrs []*wallet.DerivedAddress
+	for _, a := range addresses {
+		found, err := findAddressInSeed(w, a, lookupLastAccount(w, uint32(*maxAccounts)-1), addressSearchLimit)
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			return fmt.Errorf("o endereço %s não pertence à seed (contas 0 a %d, limite de busca: %d)", a, *maxAccounts-1, addressSearchLimit)
+		}
+		addrs = append(addrs, found)
+	}
+	if len(addrs) == 0 {
+		cfg, err := newDiscoveryConfig(w, uint32(*gapLimit), uint32(*maxAccounts))
+		if err != nil {
+			return err
+		}
+		cfg.Progress = func(p discovery.Progress) {
+			fmt.Fprintf(env.stderr, "\rBIP%d conta %d change %d índice %d (verificados: %d, usados: %d)",
+				p.Purpose, p.Account, p.Chain, p.Index, p.Checked, p.Used)
+		}
+		report, err := discovery.Scan(ctx, cfg)
+		fmt.Fprintln(env.stderr)
+		if err != nil {
+			return err
+		}
+		addrs = fundedAddresses(report)
+	}
+
+	s, err := buildSweep(ctx, w, addrs, dest, feeRate, *sign)
+	if err != nil {
+		return err
+	}
+
+	if flags.jsonOut {
+		out, err := newSweepJSON(s, dest.String())
+		if err != nil {
+			return err
+		}
+		return writeJSON(env.stdout, out)
+	}
+	return writeSweep(env.stdout, s, dest.String())
+}
+
+func runBroadcast(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "broadcast")
+	var flags seedFlags
+	fs.StringVar(&flags.network, "network", wallet.MainNet.Name, "rede: mainnet, testnet, signet ou regtest")
+	fs.BoolVar(&flags.jsonOut, "json", false, "saída em JSON")
+	var src sourceFlags
+	src.register(fs, "blockstream")
+	yes := fs.Bool("yes", false, "transmitir sem pedir confirmação")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.stderr, "Uso: broadcast [opções] <transação hex | PSBT | ->")
+		fs.PrintDefaults()
+		return errUsage
+	}
+
+	input := positional[0]
+	if input == "-" {
+		if !*yes {
+			return fmt.Errorf("ao ler a transação da entrada padrão, use --yes para confirmar")
+		}
+		data, err := io.ReadAll(env.stdin)
+		if err != nil {
+			return fmt.Errorf("erro ao ler a entrada padrão: %w", err)
+		}
+		input = string(data)
+	}
+
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+	source, err := src.apply()
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("a transmissão requer uma fonte online")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	tx, err := prepareBroadcast(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	summary := newBroadcastJSON(tx)
+	if !flags.jsonOut {
+		writeBroadcastSummary(env.stdout, tx)
+	}
+
+	if !*yes {
+		fmt.Fprintf(env.stderr, "\nTransmitir via %s? [s/N]: ", source.Name)
+		answer, _ := bufio.NewReader(env.stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "s" && answer != "sim" && answer != "y" && answer != "yes" {
+			if flags.jsonOut {
+				return writeJSON(env.stdout, summary)
+			}
+			fmt.Fprintln(env.stdout, "Transmissão cancelada.")
+			return nil
+		}
+	}
+
+	txid, err := sendTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if flags.jsonOut {
+		summary.TxID = txid.String()
+		summary.Broadcasted = true
+		return writeJSON(env.stdout, summary)
+	}
+	fmt.Fprintf(env.stdout, "Transação transmitida: %s\n", txid)
+	return nil
+}
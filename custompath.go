@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultCustomScript is the script type of custom paths when none is given.
+const defaultCustomScript = "p2wpkh"
+
+var (
+	// customPath is the path template being explored, or nil while the
+	// standard paths are shown.
+	customPath *wallet.PathTemplate
+
+	// customScript is the script type of the addresses of customPath.
+	customScript wallet.ScriptType
+
+	customPathEntry    *widget.Entry
+	customScriptSelect *widget.Select
+)
+
+// errCustomPathWatchOnly is returned when a custom path is used with a
+// watch-only wallet, which only has the keys of its account.
+var errCustomPathWatchOnly = errors.New("caminhos personalizados requerem a seed, indisponível no modo somente leitura")
+
+// scriptTypeLabels are the display names of the script types.
+var scriptTypeLabels = map[string]string{
+	"p2pkh":       "Legado (P2PKH)",
+	"p2sh-p2wpkh": "Nested SegWit (P2SH-P2WPKH)",
+	"p2wpkh":      "SegWit Nativo (P2WPKH)",
+	"p2tr":        "Taproot (P2TR)",
+}
+
+// parseCustomPath parses a path template and the name of its script type.
+func parseCustomPath(path, script string) (*wallet.PathTemplate, wallet.ScriptType, error) {
+	t, err := wallet.ParsePathTemplate(path)
+	if err != nil {
+		return nil, wallet.ScriptType{}, fmt.Errorf("caminho inválido %q: use o formato m/84'/0'/0'/0/* com um único * no lugar do índice", path)
+	}
+	scriptType, err := wallet.ScriptTypeByName(script)
+	if err != nil {
+		names := make([]string, 0, len(wallet.ScriptTypes))
+		for _, st := range wallet.ScriptTypes {
+			names = append(names, st.Name)
+		}
+		return nil, wallet.ScriptType{}, fmt.Errorf("tipo de script desconhecido %q (use %s)", script, strings.Join(names, ", "))
+	}
+	return t, scriptType, nil
+}
+
+// checkTemplateBatch derives count addresses of a path template, starting at
+// start, and checks each of them against the selected blockchain source.
+func checkTemplateBatch(w *wallet.Wallet, t *wallet.PathTemplate, script wallet.ScriptType, start, count uint32, progress func(string)) ([]addressCheckResult, error) {
+	addrs, err := w.DeriveTemplateAddresses(t, script, start, count)
+	if err != nil {
+		return nil, err
+	}
+	return checkDerivedAddresses(addrs, progress)
+}
+
+// findAddressInTemplate searches the indexes of a path template for an
+// address, returning a nil result when the address isn't found.
+func findAddressInTemplate(w *wallet.Wallet, targetAddrStr string, t *wallet.PathTemplate, script wallet.ScriptType, searchLimit uint32) (*wallet.DerivedAddress, error) {
+	log.Printf("Buscando endereço %s no caminho %s (%s) até índice %d...", targetAddrStr, t, script.Name, searchLimit-1)
+	found, err := w.FindTemplateAddress(targetAddrStr, t, script, searchLimit)
+	var wrongNet wallet.ErrWrongNetwork
+	switch {
+	case errors.Is(err, wallet.ErrAddressNotFound):
+		log.Printf("Endereço %s não encontrado no caminho %s.", targetAddrStr, t)
+		return nil, nil
+	case errors.As(err, &wrongNet):
+		return nil, fmt.Errorf("o endereço pertence à rede %s, mas a rede selecionada é %s", strings.Join(wrongNet.Detected, "/"), wrongNet.Expected)
+	case err != nil:
+		return nil, fmt.Errorf("erro ao buscar no caminho %s: %w", t, err)
+	}
+	log.Printf("Endereço encontrado! Derivação: %s", found.Path)
+	return found, nil
+}
+
+// --- GUI ---
+
+// newCustomPathCard builds the card to explore a custom derivation path.
+func newCustomPathCard() fyne.CanvasObject {
+	customPathEntry = widget.NewEntry()
+	customPathEntry.SetPlaceHolder("m/84'/0'/0'/0/*")
+
+	labels := make([]string, 0, len(wallet.ScriptTypes))
+	for _, st := range wallet.ScriptTypes {
+		labels = append(labels, scriptTypeLabels[st.Name])
+	}
+	customScriptSelect = widget.NewSelect(labels, nil)
+	customScriptSelect.SetSelected(scriptTypeLabels[defaultCustomScript])
+
+	exploreButton := widget.NewButtonWithIcon("Explorar", theme.SearchIcon(), func() {
+		clearStatus()
+		applyCustomPath()
+	})
+	resetButton := widget.NewButtonWithIcon("Voltar aos Caminhos Padrão", theme.HomeIcon(), func() {
+		clearStatus()
+		resetCustomPath()
+	})
+
+	return widget.NewCard("Caminho Personalizado", "", container.NewPadded(
+		container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Caminho:", customPathEntry),
+				widget.NewFormItem("Tipo:", customScriptSelect),
+			),
+			container.NewGridWithColumns(2, exploreButton, resetButton),
+		),
+	))
+}
+
+// applyCustomPath shows the addresses of the path template entered in the
+// custom path card.
+func applyCustomPath() {
+	if currentWallet != nil && currentWallet.WatchOnly() {
+		showStatus(fmt.Sprintf("Erro: %v.", errCustomPathWatchOnly), true)
+		return
+	}
+	script := defaultCustomScript
+	for name, label := range scriptTypeLabels {
+		if label == customScriptSelect.Selected {
+			script = name
+		}
+	}
+	t, scriptType, err := parseCustomPath(customPathEntry.Text, script)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	log.Printf("Caminho personalizado: %s (%s)", t, scriptType.Name)
+	customPath, customScript = t, scriptType
+	currentBatchStart = 0
+	updateAddressGrid()
+	showStatus(fmt.Sprintf("Exibindo endereços do caminho %s", t), false)
+}
+
+// resetCustomPath returns to the addresses of the standard paths.
+func resetCustomPath() {
+	if customPath == nil {
+		return
+	}
+	customPath = nil
+	currentBatchStart = 0
+	updateAddressGrid()
+	showStatus("Exibindo endereços dos caminhos padrão", false)
+}
+
+// updateCustomAddressGrid shows the current batch of addresses of the custom
+// path.
+func updateCustomAddressGrid() {
+	batchLabel.SetText(fmt.Sprintf("Endereços (%s, %s, Índices %d-%d):", customPath, scriptTypeLabels[customScript.Name], currentBatchStart, currentBatchStart+AddressBatchSize-1))
+
+	addrs, err := currentWallet.DeriveTemplateAddresses(customPath, customScript, currentBatchStart, AddressBatchSize)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro ao derivar caminho %s: %v", customPath, err), true)
+		return
+	}
+
+	grid := container.NewGridWithColumns(3)
+	grid.Add(widget.NewLabelWithStyle("Índice", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	grid.Add(widget.NewLabelWithStyle("Caminho", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	grid.Add(widget.NewLabelWithStyle("Endereço", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	for _, addr := range addrs {
+		addrStr := addr.Address.String()
+		copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+			mainWindow.Clipboard().SetContent(addrStr)
+			showStatus(fmt.Sprintf("Endereço %s copiado!", addrStr), false)
+		})
+		grid.Add(widget.NewLabel(strconv.FormatUint(uint64(addr.Index), 10)))
+		grid.Add(widget.NewLabel(addr.Path))
+		grid.Add(container.NewBorder(nil, nil, nil, copyBtn, widget.NewLabel(addrStr)))
+	}
+
+	if currentBatchStart == 0 {
+		outputContainer.Objects = []fyne.CanvasObject{}
+	}
+	outputContainer.Add(grid)
+	outputContainer.Refresh()
+}
+
+// checkCustomPathInfo verifies the current batch of addresses of the custom
+// path.
+func checkCustomPathInfo() {
+	if customPath == nil {
+		showStatus("Erro: Nenhum caminho personalizado ativo. Informe um caminho e clique em Explorar.", true)
+		return
+	}
+	t, script := customPath, customScript
+	scope := fmt.Sprintf("%s, %s", t, scriptTypeLabels[script.Name])
+	runAddressVerification("Caminho Personalizado", scope, func(progress func(string)) ([]addressCheckResult, error) {
+		return checkTemplateBatch(currentWallet, t, script, currentBatchStart, AddressBatchSize, progress)
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/descriptor"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// importDescriptorsJSON returns the argument of the Bitcoin Core
+// importdescriptors RPC for the descriptors of an account, rescanning from
+// the rescan time of the seed.
+func importDescriptorsJSON(accounts []*descriptor.Account, birthday time.Time) (string, error) {
+	requests := descriptor.ImportRequests(accounts, birthday)
+	data, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar JSON do importdescriptors: %w", err)
+	}
+	return string(data), nil
+}
+
+// descriptorBirthday returns the time descriptors are rescanned from, with a
+// description of it. Without a seed, as in watch-only mode, the birthday is
+// unknown and the whole chain is rescanned.
+func descriptorBirthday(seed *crypto.CipherSeed) (time.Time, string) {
+	if seed == nil {
+		return time.Unix(0, 0), "Reescaneamento desde o bloco gênese (aniversário da seed desconhecido)."
+	}
+	rescan := seed.RescanTime()
+	return rescan, fmt.Sprintf("Reescaneamento a partir de %s, um dia antes do aniversário da seed (%s), para incluir fundos recebidos no início do dia do aniversário.", rescan.UTC().Format("2006-01-02 15:04 MST"), seed.BirthdayTime().Format("2006-01-02"))
+}
+
+// newCopyRow shows a value that can't be edited next to a button that copies
+// it, labelled with name.
+func newCopyRow(name, value string) fyne.CanvasObject {
+	copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		mainWindow.Clipboard().SetContent(value)
+		showStatus(fmt.Sprintf("%s copiado!", name), false)
+	})
+	valueEntry := widget.NewMultiLineEntry()
+	valueEntry.SetText(value)
+	valueEntry.Wrapping = fyne.TextWrapBreak
+	valueEntry.Disable()
+	return container.NewBorder(nil, nil, widget.NewLabel(name+":"), copyButton, valueEntry)
+}
+
+// updateDescriptorDisplay shows the receive and change descriptors of the
+// current account, and the importdescriptors JSON to import them all into
+// Bitcoin Core.
+func updateDescriptorDisplay() {
+	title := widget.NewLabelWithStyle(fmt.Sprintf("Descritores da Conta %d:", currentAccount), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	if currentWallet == nil {
+		descriptorContainer.Objects = []fyne.CanvasObject{
+			title,
+			widget.NewLabel("Erro - Chave mestra não disponível."),
+		}
+		descriptorContainer.Refresh()
+		return
+	}
+
+	accounts, err := descriptor.ForWallet(currentWallet, currentAccount)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro ao gerar descritores: %v", err), true)
+		descriptorContainer.Objects = []fyne.CanvasObject{
+			title,
+			widget.NewLabel(fmt.Sprintf("Erro ao gerar descritores: %v", err)),
+		}
+		descriptorContainer.Refresh()
+		return
+	}
+
+	descs := []fyne.CanvasObject{title}
+	for _, a := range accounts {
+		descs = append(descs,
+			newCopyRow(fmt.Sprintf("BIP%d Recebimento", a.Purpose), a.Receive),
+			newCopyRow(fmt.Sprintf("BIP%d Troco", a.Purpose), a.Change),
+		)
+	}
+
+	birthday, rescanFrom := descriptorBirthday(currentSeed)
+	importJSON, err := importDescriptorsJSON(accounts, birthday)
+	if err != nil {
+		showStatus(err.Error(), true)
+	} else {
+		importButton := widget.NewButtonWithIcon("Copiar JSON do importdescriptors", theme.ContentCopyIcon(), func() {
+			mainWindow.Clipboard().SetContent(importJSON)
+			showStatus("JSON do importdescriptors copiado!", false)
+		})
+		descs = append(descs,
+			widget.NewLabel(rescanFrom),
+			importButton,
+		)
+		// Wallets that already have the descriptors can be rescanned
+		// from the block of the rescan time instead.
+		if currentSeed != nil {
+			descs = append(descs, newRescanHeightBox(birthday))
+		}
+	}
+
+	descriptorContainer.Objects = descs
+	descriptorContainer.Refresh()
+}
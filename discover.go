@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/discovery"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newDiscoveryConfig returns the scan configuration for the selected
+// blockchain source.
+func newDiscoveryConfig(w *wallet.Wallet, gapLimit, maxAccounts uint32) (*discovery.Config, error) {
+	if selectedBackend == nil {
+		return nil, fmt.Errorf("a descoberta requer uma fonte online")
+	}
+	src, err := currentSource()
+	if err != nil {
+		return nil, err
+	}
+	return &discovery.Config{
+		Wallet:      w,
+		Checker:     discovery.NewSourceChecker(src),
+		GapLimit:    gapLimit,
+		MaxAccounts: maxAccounts,
+		Concurrency: selectedBackend.Concurrency,
+	}, nil
+}
+
+// writeDiscoveryReport writes a human-readable discovery report.
+func writeDiscoveryReport(out io.Writer, report *discovery.Report) {
+	fmt.Fprintf(out, "Endereços verificados: %d\n", report.Checked)
+	fmt.Fprintf(out, "Endereços usados: %d\n", len(report.Used))
+	fmt.Fprintf(out, "Saldo total: %s\n", report.TotalBalance)
+
+	fmt.Fprintf(out, "\nContas:\n")
+	for _, a := range report.Accounts {
+		fmt.Fprintf(out, "  BIP%d conta %d: %d usados, saldo %s\n", a.Purpose, a.Account, a.Used, a.Balance)
+	}
+
+	if len(report.Used) > 0 {
+		fmt.Fprintf(out, "\nEndereços usados:\n")
+	}
+	for _, u := range report.Used {
+		fmt.Fprintf(out, "  %-24s %s  txs: %d  saldo: %s\n", u.Path, u.Address, u.Stats.TxCount, u.Stats.Balance)
+	}
+}
+
+// discoveryEntry is the JSON form of a used address.
+type discoveryEntry struct {
+	Path       string `json:"path"`
+	Address    string `json:"address"`
+	TxCount    uint64 `json:"tx_count"`
+	BalanceSat int64  `json:"balance_sat"`
+}
+
+// discoveryJSON is the JSON form of a discovery report.
+type discoveryJSON struct {
+	Checked         int              `json:"checked"`
+	TotalBalanceSat int64            `json:"total_balance_sat"`
+	Used            []discoveryEntry `json:"used"`
+	Canceled        bool             `json:"canceled,omitempty"`
+}
+
+func newDiscoveryJSON(report *discovery.Report) discoveryJSON {
+	out := discoveryJSON{
+		Checked:         report.Checked,
+		TotalBalanceSat: int64(report.TotalBalance),
+		Used:            []discoveryEntry{},
+	}
+	for _, u := range report.Used {
+		out.Used = append(out.Used, discoveryEntry{
+			Path:       u.Path,
+			Address:    u.Address.String(),
+			TxCount:    u.Stats.TxCount,
+			BalanceSat: int64(u.Stats.Balance),
+		})
+	}
+	return out
+}
+
+// --- GUI ---
+
+// handleDiscoveryScan asks for the scan limits and runs a discovery scan of
+// the loaded seed in the background, with progress and cancellation.
+func handleDiscoveryScan() {
+	if currentWallet == nil {
+		showStatus("Erro: Nenhuma chave mestra disponível. Gere ou decodifique uma seed primeiro.", true)
+		return
+	}
+
+	gapEntry := widget.NewEntry()
+	gapEntry.SetText(strconv.Itoa(int(discovery.DefaultGapLimit)))
+	accountsEntry := widget.NewEntry()
+	accountsEntry.SetText(strconv.Itoa(int(discovery.DefaultMaxAccounts)))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Gap Limit:", gapEntry),
+		widget.NewFormItem("Máx. Contas:", accountsEntry),
+	}
+	dialog.ShowForm("Descoberta de Carteira", "Iniciar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		gapLimit, err := strconv.ParseUint(gapEntry.Text, 10, 32)
+		if err != nil || gapLimit == 0 {
+			showStatus("Erro: Gap limit inválido.", true)
+			return
+		}
+		maxAccounts, err := strconv.ParseUint(accountsEntry.Text, 10, 32)
+		if err != nil || maxAccounts == 0 {
+			showStatus("Erro: Número de contas inválido.", true)
+			return
+		}
+		startDiscoveryScan(uint32(gapLimit), uint32(maxAccounts))
+	}, mainWindow)
+}
+
+func startDiscoveryScan(gapLimit, maxAccounts uint32) {
+	cfg, err := newDiscoveryConfig(currentWallet, gapLimit, maxAccounts)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progressLabel := widget.NewLabel("Iniciando descoberta...")
+	progressDialog := dialog.NewCustom("Descoberta em Andamento", "Cancelar", container.NewVBox(
+		progressLabel,
+		widget.NewProgressBarInfinite(),
+	), mainWindow)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	cfg.Progress = func(p discovery.Progress) {
+		msg := fmt.Sprintf("BIP%d conta %d change %d índice %d\nVerificados: %d, usados: %d",
+			p.Purpose, p.Account, p.Chain, p.Index, p.Checked, p.Used)
+		fyne.Do(func() { progressLabel.SetText(msg) })
+	}
+
+	showStatus(fmt.Sprintf("Descoberta iniciada via %s (gap limit %d)...", sourceName(), gapLimit), false)
+	go func() {
+		report, err := discovery.Scan(ctx, cfg)
+		canceled := errors.Is(err, context.Canceled)
+		if err != nil && !canceled {
+			log.Printf("Erro na descoberta: %v", err)
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			if err != nil && !canceled {
+				showStatus(fmt.Sprintf("Erro na descoberta: %v", err), true)
+				return
+			}
+			showDiscoveryReport(report, canceled)
+		})
+	}()
+}
+
+// showDiscoveryReport shows the result of a scan in a dialog.
+func showDiscoveryReport(report *discovery.Report, canceled bool) {
+	var text strings.Builder
+	if canceled {
+		text.WriteString("Descoberta CANCELADA. Resultado parcial:\n\n")
+	}
+	writeDiscoveryReport(&text, report)
+
+	resultEntry := widget.NewMultiLineEntry()
+	resultEntry.SetText(text.String())
+	resultEntry.Wrapping = fyne.TextWrapOff
+	resultEntry.Disable()
+	resultScroll := container.NewScroll(resultEntry)
+	resultScroll.SetMinSize(fyne.NewSize(700, 400))
+	dialog.ShowCustom("Descoberta Concluída", "Fechar", resultScroll, mainWindow)
+
+	showStatus(fmt.Sprintf("Descoberta concluída: %d endereços usados, saldo %s.", len(report.Used), report.TotalBalance), false)
+}
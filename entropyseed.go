@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"aezeed_address_generator_gui/internal/entropy"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// entropyKinds are the kinds of entropy a seed can be created from, with
+// their descriptions, in the order they are offered.
+var entropyKinds = []struct {
+	kind  entropy.Kind
+	label string
+}{
+	{entropy.Hex, "Hexadecimal (16 bytes, ou mais para aplicar hash)"},
+	{entropy.Dice, "Dados de 6 faces (1 a 6, mínimo 50 lançamentos)"},
+	{entropy.Coins, "Moedas (0/1 ou H/T, mínimo 128 lançamentos)"},
+}
+
+// parseEntropy turns an input of the given kind into the entropy of a seed.
+func parseEntropy(kind entropy.Kind, input string) (*entropy.Result, error) {
+	result, err := entropy.Parse(kind, input)
+	var symbolErr entropy.InvalidSymbolError
+	switch {
+	case err == nil:
+		return result, nil
+	case errors.Is(err, entropy.ErrInsufficientEntropy):
+		return nil, fmt.Errorf("entropia insuficiente: são necessários %d bits (%s)", entropy.MinBits, describeMinSymbols(kind))
+	case errors.Is(err, entropy.ErrOddHexLength):
+		return nil, errors.New("a entropia hexadecimal deve ter um número par de dígitos")
+	case errors.As(err, &symbolErr):
+		return nil, fmt.Errorf("caractere inválido %q na posição %d", symbolErr.Symbol, symbolErr.Position)
+	default:
+		return nil, fmt.Errorf("entropia inválida: %w", err)
+	}
+}
+
+// describeMinSymbols tells how many symbols of a kind carry enough entropy.
+func describeMinSymbols(kind entropy.Kind) string {
+	switch kind {
+	case entropy.Hex:
+		return "32 dígitos hexadecimais"
+	case entropy.Dice:
+		return "50 lançamentos de dado"
+	default:
+		return "128 lançamentos de moeda"
+	}
+}
+
+// describeCheck describes a statistical check of the entropy.
+func describeCheck(check entropy.Check) string {
+	verdict := "ok"
+	if !check.Passed {
+		verdict = "FALHOU"
+	}
+	switch check.Name {
+	case entropy.CheckDistribution:
+		return fmt.Sprintf("Distribuição dos símbolos (qui-quadrado %.2f, limite %.2f): %s", check.Value, check.Limit, verdict)
+	case entropy.CheckRuns:
+		return fmt.Sprintf("Maior sequência repetida (%.0f, limite %.0f): %s", check.Value, check.Limit, verdict)
+	default:
+		return fmt.Sprintf("%s (%.2f, limite %.2f): %s", check.Name, check.Value, check.Limit, verdict)
+	}
+}
+
+// writeEntropyReport writes how the entropy of a seed was obtained and the
+// checks that were run on it.
+func writeEntropyReport(out io.Writer, kind entropy.Kind, result *entropy.Result) {
+	fmt.Fprintf(out, "Entropia: %d símbolos (%s), %.1f bits estimados\n", result.Symbols, kind, result.Bits)
+	if result.Hashed {
+		fmt.Fprintln(out, "A entrada foi reduzida aos 16 bytes da seed com SHA-256.")
+	} else {
+		fmt.Fprintln(out, "A entrada foi usada diretamente como os 16 bytes da seed.")
+	}
+	fmt.Fprintln(out, "Verificações de qualidade:")
+	for _, check := range result.Checks {
+		fmt.Fprintf(out, "  %s\n", describeCheck(check))
+	}
+	if !result.Passed() {
+		fmt.Fprintln(out, "ATENÇÃO: a entrada não parece aleatória. Uma entrada aleatória falha uma verificação apenas 1 vez em 1000; considere gerar nova entropia.")
+	}
+}
+
+// entropyReportJSON is the JSON form of the entropy of a seed.
+type entropyReportJSON struct {
+	Kind    string             `json:"kind"`
+	Symbols int                `json:"symbols"`
+	Bits    float64            `json:"bits"`
+	Hashed  bool               `json:"hashed"`
+	Passed  bool               `json:"passed"`
+	Checks  []entropyCheckJSON `json:"checks"`
+}
+
+// entropyCheckJSON is the JSON form of a statistical check of the entropy.
+type entropyCheckJSON struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Limit  float64 `json:"limit"`
+	Passed bool    `json:"passed"`
+}
+
+// newEntropyReportJSON converts the entropy of a seed to its JSON form.
+func newEntropyReportJSON(kind entropy.Kind, result *entropy.Result) *entropyReportJSON {
+	report := &entropyReportJSON{
+		Kind:    kind.String(),
+		Symbols: result.Symbols,
+		Bits:    result.Bits,
+		Hashed:  result.Hashed,
+		Passed:  result.Passed(),
+	}
+	for _, check := range result.Checks {
+		report.Checks = append(report.Checks, entropyCheckJSON{string(check.Name), check.Value, check.Limit, check.Passed})
+	}
+	return report
+}
+
+// --- GUI ---
+
+// showEntropySeedForm asks for entropy and a birthday to create a seed from,
+// enciphered under the passphrase in the entry.
+func showEntropySeedForm() {
+	var labels []string
+	for _, k := range entropyKinds {
+		labels = append(labels, k.label)
+	}
+	kindSelect := widget.NewSelect(labels, nil)
+	kindSelect.SetSelectedIndex(0)
+	inputEntry := widget.NewMultiLineEntry()
+	inputEntry.SetPlaceHolder("Entropia em hexadecimal, lançamentos de dado ou de moeda; espaços são ignorados")
+	inputEntry.Wrapping = fyne.TextWrapBreak
+	inputEntry.SetMinRowsVisible(4)
+	countLabel := widget.NewLabel("")
+	seedBirthdayEntry := widget.NewEntry()
+	seedBirthdayEntry.SetPlaceHolder(birthdayPlaceholder)
+	seedBirthdayEntry.SetText(birthdayEntry.Text)
+
+	// Tell how much entropy the input carries as it is typed.
+	updateCount := func() {
+		kind := entropyKinds[kindSelect.SelectedIndex()].kind
+		result, err := entropy.Parse(kind, inputEntry.Text)
+		var symbolErr entropy.InvalidSymbolError
+		switch {
+		case err == nil:
+			countLabel.SetText(fmt.Sprintf("%d símbolos, %.1f bits: suficiente.", result.Symbols, result.Bits))
+		case errors.As(err, &symbolErr):
+			countLabel.SetText(fmt.Sprintf("Caractere inválido %q na posição %d.", symbolErr.Symbol, symbolErr.Position))
+		default:
+			countLabel.SetText(fmt.Sprintf("Entropia insuficiente: são necessários %d bits (%s).", entropy.MinBits, describeMinSymbols(kind)))
+		}
+	}
+	inputEntry.OnChanged = func(string) { updateCount() }
+	kindSelect.OnChanged = func(string) { updateCount() }
+	updateCount()
+
+	content := container.NewVBox(
+		widget.NewLabel("A seed é criada com a entropia informada, sem usar o gerador aleatório do sistema,\ne cifrada com a passphrase informada na tela principal."),
+		widget.NewForm(
+			widget.NewFormItem("Fonte:", kindSelect),
+			widget.NewFormItem("Entropia:", inputEntry),
+			widget.NewFormItem("", countLabel),
+			widget.NewFormItem("Aniversário:", seedBirthdayEntry),
+		),
+	)
+	formDialog := dialog.NewCustomConfirm("Criar Seed a partir de Entropia", "Criar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		kind := entropyKinds[kindSelect.SelectedIndex()].kind
+		result, err := parseEntropy(kind, inputEntry.Text)
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro: %v", err), true)
+			return
+		}
+		birthday, err := parseBirthday(seedBirthdayEntry.Text, currentNetwork, time.Now())
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro: %v", err), true)
+			return
+		}
+		createEntropySeed(kind, result, birthday)
+	}, mainWindow)
+	formDialog.Resize(fyne.NewSize(650, 0))
+	formDialog.Show()
+}
+
+// createEntropySeed creates and loads a seed of checked entropy, then shows
+// the checks that were run on it.
+func createEntropySeed(kind entropy.Kind, result *entropy.Result, birthday time.Time) {
+	seed, mnemonic, err := createNewSeed(&result.Entropy, birthday, passphraseOrDefault(passphraseEntry.Text))
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		updateXPUBDisplay()
+		return
+	}
+	if !loadNewSeed(seed, mnemonic) {
+		return
+	}
+	if result.Passed() {
+		showStatus("Seed criada a partir da entropia informada.", false)
+	} else {
+		showStatus("Seed criada, mas a entropia falhou em verificações de qualidade.", true)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Mnemônico: %s\n", strings.Join(mnemonic[:], " "))
+	fmt.Fprintf(&text, "Aniversário: %s\n\n", formatBirthday(seed))
+	writeEntropyReport(&text, kind, result)
+	reportLabel := widget.NewLabel(text.String())
+	reportLabel.Wrapping = fyne.TextWrapWord
+	reportScroll := container.NewScroll(reportLabel)
+	reportScroll.SetMinSize(fyne.NewSize(600, 300))
+	dialog.ShowCustom("Seed Criada", "Fechar", reportScroll, mainWindow)
+}
@@ -0,0 +1,124 @@
+// Package bip39 implements the BIP39 mnemonics most wallets use, which share
+// their word list with aezeed but encode the entropy differently and stretch
+// the mnemonic itself, with an optional passphrase, into the BIP32 seed.
+package bip39
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// SeedSize is the size of the BIP32 seed of a mnemonic.
+	SeedSize = 64
+
+	// seedIterations is the number of PBKDF2 rounds that stretch a
+	// mnemonic into its seed.
+	seedIterations = 2048
+
+	// bitsPerWord is the number of bits each word encodes.
+	bitsPerWord = 11
+)
+
+// ErrInvalidChecksum is returned when the checksum of a mnemonic doesn't
+// match its entropy.
+var ErrInvalidChecksum = errors.New("bip39 checksum mismatch")
+
+// ValidWordCount reports whether a mnemonic of n words can be a BIP39
+// mnemonic: 12, 15, 18, 21 or 24 words.
+func ValidWordCount(n int) bool {
+	return n >= 12 && n <= 24 && n%3 == 0
+}
+
+// Entropy decodes a mnemonic into the entropy it encodes, verifying its
+// checksum.
+func Entropy(words []string) ([]byte, error) {
+	if !ValidWordCount(len(words)) {
+		return nil, fmt.Errorf("expected 12, 15, 18, 21 or 24 words, "+
+			"got %d", len(words))
+	}
+
+	bits := make([]byte, (len(words)*bitsPerWord+7)/8)
+	for i, word := range words {
+		index, ok := crypto.ReverseWordMap[word]
+		if !ok {
+			return nil, crypto.ErrUnknownMnemonicWord{
+				Word:  word,
+				Index: uint8(i),
+			}
+		}
+		for b := 0; b < bitsPerWord; b++ {
+			if index&(1<<(bitsPerWord-1-b)) != 0 {
+				setBit(bits, i*bitsPerWord+b)
+			}
+		}
+	}
+
+	// Every 3 words encode 32 bits of entropy and 1 bit of checksum, the
+	// leading bits of the entropy's hash.
+	entropy := bits[:len(words)/3*4]
+	hash := sha256.Sum256(entropy)
+	for b := 0; b < len(words)/3; b++ {
+		if bit(bits, len(entropy)*8+b) != bit(hash[:], b) {
+			return nil, ErrInvalidChecksum
+		}
+	}
+
+	return append([]byte(nil), entropy...), nil
+}
+
+// NewMnemonic encodes entropy of 16, 20, 24, 28 or 32 bytes into a mnemonic.
+func NewMnemonic(entropy []byte) ([]string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return nil, fmt.Errorf("entropy must be 16, 20, 24, 28 or 32 "+
+			"bytes, got %d", len(entropy))
+	}
+
+	hash := sha256.Sum256(entropy)
+	bits := append(append([]byte(nil), entropy...), hash[0])
+
+	words := make([]string, len(entropy)*3/4)
+	for i := range words {
+		index := 0
+		for b := 0; b < bitsPerWord; b++ {
+			index <<= 1
+			if bit(bits, i*bitsPerWord+b) {
+				index |= 1
+			}
+		}
+		words[i] = crypto.DefaultWordList[index]
+	}
+
+	return words, nil
+}
+
+// Seed returns the BIP32 seed of a mnemonic and its passphrase, which may be
+// empty. The mnemonic isn't validated, see Entropy.
+func Seed(words []string, passphrase string) []byte {
+	mnemonic := norm.NFKD.String(strings.Join(words, " "))
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+
+	return pbkdf2.Key(
+		[]byte(mnemonic), []byte(salt), seedIterations, SeedSize,
+		sha512.New,
+	)
+}
+
+// bit reports whether bit i of data, counting from the most significant bit
+// of the first byte, is set.
+func bit(data []byte, i int) bool {
+	return data[i/8]&(0x80>>(i%8)) != 0
+}
+
+// setBit sets bit i of data, counting as bit does.
+func setBit(data []byte, i int) {
+	data[i/8] |= 0x80 >> (i % 8)
+}
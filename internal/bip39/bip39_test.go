@@ -0,0 +1,120 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testVectors are entropy and mnemonic pairs of the reference BIP39 test
+// vectors.
+var testVectors = []struct {
+	entropy  string
+	mnemonic string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon about",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal " +
+			"winner thank yellow",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid " +
+			"letter advice cage above",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+	},
+	{
+		"000000000000000000000000000000000000000000000000",
+		strings.Repeat("abandon ", 17) + "agent",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffff",
+		strings.Repeat("zoo ", 17) + "when",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		strings.Repeat("abandon ", 23) + "art",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		strings.Repeat("zoo ", 23) + "vote",
+	},
+}
+
+// TestVectors checks the encoding and decoding of the test vectors.
+func TestVectors(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range testVectors {
+		entropy, err := hex.DecodeString(v.entropy)
+		require.NoError(t, err)
+		words := strings.Fields(v.mnemonic)
+
+		mnemonic, err := NewMnemonic(entropy)
+		require.NoError(t, err)
+		require.Equal(t, words, mnemonic)
+
+		decoded, err := Entropy(words)
+		require.NoError(t, err)
+		require.Equal(t, entropy, decoded)
+	}
+}
+
+// TestSeed checks the seeds of test vectors with and without passphrase, and
+// that passphrases are normalized.
+func TestSeed(t *testing.T) {
+	t.Parallel()
+
+	seed := Seed(strings.Fields(testVectors[0].mnemonic), "")
+	require.Equal(t,
+		"5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc1"+
+			"9a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4",
+		hex.EncodeToString(seed))
+
+	seed = Seed(strings.Fields(testVectors[1].mnemonic), "TREZOR")
+	require.Equal(t,
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6f"+
+			"a457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		hex.EncodeToString(seed))
+
+	// A composed and a decomposed accent are the same passphrase.
+	words := strings.Fields(testVectors[0].mnemonic)
+	require.Equal(t, Seed(words, "caf\u00e9"), Seed(words, "cafe\u0301"))
+	require.NotEqual(t, Seed(words, "cafe"), Seed(words, "caf\u00e9"))
+}
+
+// TestEntropyErrors checks the mnemonics that aren't valid.
+func TestEntropyErrors(t *testing.T) {
+	t.Parallel()
+
+	words := strings.Fields(testVectors[1].mnemonic)
+
+	_, err := Entropy(words[:11])
+	require.ErrorContains(t, err, "got 11")
+
+	wrong := append([]string(nil), words...)
+	wrong[11] = "zoo"
+	_, err = Entropy(wrong)
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+
+	wrong[3] = "notaword"
+	_, err = Entropy(wrong)
+	wordErr := &crypto.ErrUnknownMnemonicWord{}
+	require.ErrorAs(t, err, wordErr)
+	require.Equal(t, uint8(3), wordErr.Index)
+
+	_, err = NewMnemonic(make([]byte, 15))
+	require.ErrorContains(t, err, "got 15")
+}
@@ -0,0 +1,522 @@
+package bitcoind
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// maxResponseSize is the largest RPC response accepted.
+	maxResponseSize = 32 * 1024 * 1024
+
+	// rpcInvalidAddressOrKey is the error code of unknown transactions.
+	rpcInvalidAddressOrKey = -5
+
+	// TimestampWindow is subtracted from a time before looking up the
+	// blocks mined after it, as block timestamps may be off by up to two
+	// hours. Bitcoin Core applies the same window to its rescans.
+	TimestampWindow = 2 * time.Hour
+
+	// medianTimeLag is how far the median time past of a block, the
+	// median of the timestamps of the block and its ten predecessors, is
+	// assumed to trail the newest of those timestamps at most. Five blocks
+	// are mined in about an hour, so twelve hours leaves a wide margin for
+	// slow blocks.
+	medianTimeLag = 12 * time.Hour
+)
+
+var (
+	// errAuth is returned when the node rejects the RPC credentials.
+	errAuth = errors.New("HTTP 401 Unauthorized")
+)
+
+// Config configures a bitcoind RPC source.
+type Config struct {
+	// Host is the RPC server as host:port, optionally prefixed with
+	// http:// or https://.
+	Host string
+
+	// User and Pass are the RPC credentials.
+	User string
+	Pass string
+
+	// Network is the network the node must be running on.
+	Network *wallet.Network
+
+	// HTTPClient performs the requests. http.DefaultClient is used if nil.
+	HTTPClient *http.Client
+}
+
+// Source is a blockchain source backed by the UTXO set of a bitcoind node.
+// Without an address index the node only knows unspent outputs, so AddressInfo
+// counts those instead of transactions and History is not supported.
+type Source struct {
+	cfg *Config
+	url string
+
+	mu sync.Mutex
+
+	// chainChecked is set once the node was found on the right network.
+	// It is cleared on connection errors, so a restarted node is checked
+	// again.
+	chainChecked bool
+
+	nextID uint64
+
+	// scanMtx serializes scantxoutset calls, as the node only runs one
+	// scan at a time.
+	scanMtx sync.Mutex
+}
+
+// A compile-time check to ensure Source implements the blockchain.Source
+// and blockchain.HeightSource interfaces.
+var _ blockchain.HeightSource = (*Source)(nil)
+
+// New returns a bitcoind source. The connection is established on first use.
+func New(cfg *Config) *Source {
+	url := cfg.Host
+	lower := strings.ToLower(url)
+	if !strings.HasPrefix(lower, "http://") &&
+		!strings.HasPrefix(lower, "https://") {
+
+		url = "http://" + url
+	}
+
+	return &Source{cfg: cfg, url: url}
+}
+
+// ChainName returns the chain name bitcoind reports for a network.
+func ChainName(n *wallet.Network) string {
+	switch n {
+	case wallet.MainNet:
+		return "main"
+	case wallet.TestNet:
+		return "test"
+	default:
+		return n.Name
+	}
+}
+
+// RPCError is an error returned by the node.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns a human-readable string describing the error.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("node RPC error %d: %s", e.Code, e.Message)
+}
+
+// rpcRequest is a JSON-RPC request.
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC response.
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// rawCall performs a single JSON-RPC request.
+func (s *Source) rawCall(ctx context.Context, method string,
+	params ...interface{}) (json.RawMessage, error) {
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.mu.Unlock()
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "1.0",
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, s.url, bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RPC host %q: %w", s.cfg.Host, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(s.cfg.User, s.cfg.Pass)
+
+	client := s.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errAuth
+	}
+
+	// bitcoind answers RPC errors with a 500 status and a JSON body, so
+	// only bodies that don't decode are reported by status.
+	var rpcResp rpcResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).
+		Decode(&rpcResp)
+	switch {
+	case err != nil && resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	case err != nil:
+		return nil, fmt.Errorf("invalid %s response: %w", method, err)
+	case rpcResp.Error != nil:
+		return nil, rpcResp.Error
+	}
+
+	return rpcResp.Result, nil
+}
+
+// call performs a request, after making sure the node runs on the expected
+// network.
+func (s *Source) call(ctx context.Context, method string,
+	params ...interface{}) (json.RawMessage, error) {
+
+	if err := s.checkChain(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := s.rawCall(ctx, method, params...)
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			s.mu.Lock()
+			s.chainChecked = false
+			s.mu.Unlock()
+		}
+
+		return nil, fmt.Errorf("%s failed: %w", method, describeError(err))
+	}
+
+	return result, nil
+}
+
+// checkChain makes sure the node runs on the expected network, so balances of
+// another chain are never reported.
+func (s *Source) checkChain(ctx context.Context) error {
+	s.mu.Lock()
+	checked := s.chainChecked
+	s.mu.Unlock()
+	if checked {
+		return nil
+	}
+
+	resultBytes, err := s.rawCall(ctx, "getblockchaininfo")
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", s.cfg.Host,
+			describeError(err))
+	}
+
+	var info struct {
+		Chain string `json:"chain"`
+	}
+	if err := json.Unmarshal(resultBytes, &info); err != nil {
+		return fmt.Errorf("invalid getblockchaininfo response: %w", err)
+	}
+	if info.Chain != ChainName(s.cfg.Network) {
+		return fmt.Errorf("node runs on chain %q, expected %q", info.Chain,
+			ChainName(s.cfg.Network))
+	}
+
+	s.mu.Lock()
+	s.chainChecked = true
+	s.mu.Unlock()
+
+	return nil
+}
+
+// describeError explains the common connection errors.
+func describeError(err error) error {
+	switch {
+	case errors.Is(err, errAuth):
+		return fmt.Errorf("RPC authentication failed, check the user "+
+			"and password: %w", err)
+	case strings.Contains(err.Error(), "connection refused"):
+		return fmt.Errorf("connection refused, check that the node is "+
+			"running and the host and port are correct: %w", err)
+	case strings.Contains(err.Error(), "no such host"):
+		return fmt.Errorf("RPC host not found: %w", err)
+	default:
+		return err
+	}
+}
+
+// scanResult is the result of a scantxoutset call.
+type scanResult struct {
+	Success     bool    `json:"success"`
+	TotalAmount float64 `json:"total_amount"`
+	Unspents    []struct {
+		TxID   string  `json:"txid"`
+		Vout   uint32  `json:"vout"`
+		Amount float64 `json:"amount"`
+		Height int64   `json:"height"`
+	} `json:"unspents"`
+}
+
+// scan scans the UTXO set of the node for an address.
+func (s *Source) scan(ctx context.Context,
+	addr btcutil.Address) (*scanResult, error) {
+
+	s.scanMtx.Lock()
+	defer s.scanMtx.Unlock()
+
+	// A scan started by someone else would make ours fail, so it is
+	// aborted first.
+	statusBytes, err := s.call(ctx, "scantxoutset", "status")
+	if err == nil {
+		var status struct {
+			Progress *float64 `json:"progress"`
+		}
+		err := json.Unmarshal(statusBytes, &status)
+		if err == nil && status.Progress != nil {
+			_, err := s.call(ctx, "scantxoutset", "abort")
+			if err == nil {
+				time.Sleep(200 * time.Millisecond)
+			}
+		}
+	}
+
+	descriptors := []map[string]string{
+		{"desc": fmt.Sprintf("addr(%s)", addr.EncodeAddress())},
+	}
+	resultBytes, err := s.call(ctx, "scantxoutset", "start", descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	var result scanResult
+	if err := json.Unmarshal(resultBytes, &result); err != nil {
+		return nil, fmt.Errorf("invalid scantxoutset response: %w", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("scantxoutset was not successful")
+	}
+
+	return &result, nil
+}
+
+// AddressInfo returns the number of unspent outputs and the balance of an
+// address. Emptied addresses look unused.
+func (s *Source) AddressInfo(ctx context.Context,
+	addr btcutil.Address) (*blockchain.AddressInfo, error) {
+
+	result, err := s.scan(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := btcutil.NewAmount(result.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blockchain.AddressInfo{
+		TxCount:   uint64(len(result.Unspents)),
+		Confirmed: balance,
+	}, nil
+}
+
+// Balance returns the confirmed balance of an address.
+func (s *Source) Balance(ctx context.Context,
+	addr btcutil.Address) (btcutil.Amount, error) {
+
+	info, err := s.AddressInfo(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Balance(), nil
+}
+
+// History is not supported, as the node has no address index.
+func (s *Source) History(context.Context, btcutil.Address) ([]blockchain.Tx,
+	error) {
+
+	return nil, blockchain.ErrNotSupported
+}
+
+// UTXOs returns the confirmed unspent outputs of an address.
+func (s *Source) UTXOs(ctx context.Context,
+	addr btcutil.Address) ([]blockchain.UTXO, error) {
+
+	result, err := s.scan(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]blockchain.UTXO, 0, len(result.Unspents))
+	for _, u := range result.Unspents {
+		txid, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", u.TxID, err)
+		}
+		value, err := btcutil.NewAmount(u.Amount)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, blockchain.UTXO{
+			OutPoint: wire.OutPoint{Hash: *txid, Index: u.Vout},
+			Value:    value,
+			Height:   u.Height,
+		})
+	}
+
+	return utxos, nil
+}
+
+// Transaction returns a transaction with getrawtransaction. Transactions
+// that are neither in the mempool nor spend-relevant to the node's wallet are
+// only found if the node runs with txindex.
+func (s *Source) Transaction(ctx context.Context,
+	txid *chainhash.Hash) (*wire.MsgTx, error) {
+
+	resultBytes, err := s.call(ctx, "getrawtransaction", txid.String())
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == rpcInvalidAddressOrKey {
+		return nil, fmt.Errorf("%w: %s", blockchain.ErrTxNotFound,
+			rpcErr.Message)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rawTx string
+	if err := json.Unmarshal(resultBytes, &rawTx); err != nil {
+		return nil, fmt.Errorf("invalid getrawtransaction response: %w",
+			err)
+	}
+
+	return blockchain.DecodeTx(rawTx)
+}
+
+// Broadcast publishes a transaction with sendrawtransaction.
+func (s *Source) Broadcast(ctx context.Context,
+	tx *wire.MsgTx) (*chainhash.Hash, error) {
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	resultBytes, err := s.call(
+		ctx, "sendrawtransaction", hex.EncodeToString(buf.Bytes()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var txid string
+	if err := json.Unmarshal(resultBytes, &txid); err != nil {
+		return nil, fmt.Errorf("invalid sendrawtransaction response: %w",
+			err)
+	}
+
+	return chainhash.NewHashFromStr(txid)
+}
+
+// medianTime returns the median time past of the block at a height.
+func (s *Source) medianTime(ctx context.Context, height int64) (time.Time,
+	error) {
+
+	hashBytes, err := s.call(ctx, "getblockhash", height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var hash string
+	if err := json.Unmarshal(hashBytes, &hash); err != nil {
+		return time.Time{}, fmt.Errorf("invalid getblockhash response: %w",
+			err)
+	}
+
+	headerBytes, err := s.call(ctx, "getblockheader", hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var header struct {
+		MedianTime int64 `json:"mediantime"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return time.Time{}, fmt.Errorf("invalid getblockheader response: "+
+			"%w", err)
+	}
+
+	return time.Unix(header.MedianTime, 0), nil
+}
+
+// HeightAt returns the height of the first block whose median time past is
+// at least t minus TimestampWindow and medianTimeLag, or the height of the tip
+// if there is none yet. Bitcoin Core starts its rescans at the first block
+// with a timestamp, or one of its ancestors, at least t minus TimestampWindow.
+// That maximum of the timestamps can't be looked up over RPC, but the median
+// time past never decreases along the chain, which allows a binary search on
+// the block headers. As the median time past is never above the newest
+// timestamp, searching for the same time would return that block or a later
+// one; taking medianTimeLag off first makes up for it, so no block holding
+// transactions made at or after t comes before the returned height.
+func (s *Source) HeightAt(ctx context.Context, t time.Time) (int64, error) {
+	countBytes, err := s.call(ctx, "getblockcount")
+	if err != nil {
+		return 0, err
+	}
+	var tip int64
+	if err := json.Unmarshal(countBytes, &tip); err != nil {
+		return 0, fmt.Errorf("invalid getblockcount response: %w", err)
+	}
+
+	target := t.Add(-TimestampWindow - medianTimeLag)
+	low, high := int64(0), tip
+	for low < high {
+		mid := low + (high-low)/2
+		medianTime, err := s.medianTime(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if medianTime.Before(target) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// Close releases idle connections. The source stays usable.
+func (s *Source) Close() error {
+	if s.cfg.HTTPClient != nil {
+		s.cfg.HTTPClient.CloseIdleConnections()
+	}
+
+	return nil
+}
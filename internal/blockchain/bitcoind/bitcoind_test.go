@@ -0,0 +1,314 @@
+package bitcoind
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode is a fake bitcoind JSON-RPC server.
+type fakeNode struct {
+	chain string
+
+	mu    sync.Mutex
+	calls []string
+	sent  []string
+	txs   map[string]string
+
+	// medianTimes are the median times past of the blocks, by height.
+	medianTimes []int64
+}
+
+func (f *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, _ := r.BasicAuth()
+	if user != "user" || pass != "pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, req.Method)
+	f.mu.Unlock()
+
+	var result interface{}
+	switch req.Method {
+	case "ping":
+	case "getblockchaininfo":
+		result = map[string]string{"chain": f.chain}
+
+	case "scantxoutset":
+		var action string
+		_ = json.Unmarshal(req.Params[0], &action)
+		if action != "start" {
+			break
+		}
+		result = map[string]interface{}{
+			"success":      true,
+			"total_amount": 0.00015,
+			"unspents": []map[string]interface{}{{
+				"txid":   chainhash.Hash{1}.String(),
+				"vout":   2,
+				"amount": 0.0001,
+				"height": 800000,
+			}, {
+				"txid":   chainhash.Hash{2}.String(),
+				"vout":   0,
+				"amount": 0.00005,
+				"height": 800001,
+			}},
+		}
+
+	case "getrawtransaction":
+		var txid string
+		_ = json.Unmarshal(req.Params[0], &txid)
+		f.mu.Lock()
+		rawTx, ok := f.txs[txid]
+		f.mu.Unlock()
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"result": nil,
+				"error": map[string]interface{}{
+					"code":    rpcInvalidAddressOrKey,
+					"message": "No such mempool or blockchain transaction",
+				},
+				"id": req.ID,
+			})
+			return
+		}
+		result = rawTx
+
+	case "getblockcount":
+		result = len(f.medianTimes) - 1
+
+	case "getblockhash":
+		var height int
+		_ = json.Unmarshal(req.Params[0], &height)
+		result = fmt.Sprintf("%064x", height)
+
+	case "getblockheader":
+		var hash string
+		_ = json.Unmarshal(req.Params[0], &hash)
+		height, _ := strconv.ParseInt(hash, 16, 64)
+		result = map[string]int64{"mediantime": f.medianTimes[height]}
+
+	case "sendrawtransaction":
+		var raw string
+		_ = json.Unmarshal(req.Params[0], &raw)
+		f.mu.Lock()
+		f.sent = append(f.sent, raw)
+		f.mu.Unlock()
+		result = chainhash.Hash{3}.String()
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": result,
+		"error":  nil,
+		"id":     req.ID,
+	})
+}
+
+func newTestSource(t *testing.T, chain, pass string) (*Source, *fakeNode) {
+	t.Helper()
+
+	node := &fakeNode{chain: chain, txs: make(map[string]string)}
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	src := New(&Config{
+		Host:    server.URL,
+		User:    "user",
+		Pass:    pass,
+		Network: wallet.MainNet,
+	})
+	t.Cleanup(func() { src.Close() })
+
+	return src, node
+}
+
+func testAddress(t *testing.T) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+
+	return addr
+}
+
+// TestScan checks address info and UTXOs from the UTXO set.
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	src, node := newTestSource(t, "main", "pass")
+	ctx := context.Background()
+	addr := testAddress(t)
+
+	info, err := src.AddressInfo(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), info.TxCount)
+	require.Equal(t, btcutil.Amount(15000), info.Balance())
+
+	utxos, err := src.UTXOs(ctx, addr)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	require.Equal(t, chainhash.Hash{1}, utxos[0].OutPoint.Hash)
+	require.Equal(t, uint32(2), utxos[0].OutPoint.Index)
+	require.Equal(t, btcutil.Amount(10000), utxos[0].Value)
+	require.Equal(t, int64(800000), utxos[0].Height)
+
+	_, err = src.History(ctx, addr)
+	require.ErrorIs(t, err, blockchain.ErrNotSupported)
+
+	// The chain is only checked when connecting.
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	var chainChecks int
+	for _, call := range node.calls {
+		if call == "getblockchaininfo" {
+			chainChecks++
+		}
+	}
+	require.Equal(t, 1, chainChecks)
+}
+
+// TestWrongChain checks that nodes of another network are rejected.
+func TestWrongChain(t *testing.T) {
+	t.Parallel()
+
+	src, _ := newTestSource(t, "test", "pass")
+	_, err := src.AddressInfo(context.Background(), testAddress(t))
+	require.ErrorContains(t, err, `"test"`)
+}
+
+// TestAuthFailure checks that rejected credentials are reported as such.
+func TestAuthFailure(t *testing.T) {
+	t.Parallel()
+
+	src, _ := newTestSource(t, "main", "wrong")
+	_, err := src.AddressInfo(context.Background(), testAddress(t))
+	require.ErrorIs(t, err, errAuth)
+	require.ErrorContains(t, err, "authentication failed")
+}
+
+// TestBroadcast checks that transactions are sent hex encoded.
+func TestBroadcast(t *testing.T) {
+	t.Parallel()
+
+	src, node := newTestSource(t, "main", "pass")
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	txid, err := src.Broadcast(context.Background(), tx)
+	require.NoError(t, err)
+	require.Equal(t, chainhash.Hash{3}, *txid)
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	var raw strings.Builder
+	require.NoError(t, tx.Serialize(hex.NewEncoder(&raw)))
+	require.Equal(t, []string{raw.String()}, node.sent)
+}
+
+// TestTransaction checks the lookup of raw transactions.
+func TestTransaction(t *testing.T) {
+	t.Parallel()
+
+	src, node := newTestSource(t, "main", "pass")
+	ctx := context.Background()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	var raw strings.Builder
+	require.NoError(t, tx.Serialize(hex.NewEncoder(&raw)))
+	txid := tx.TxHash()
+	node.mu.Lock()
+	node.txs[txid.String()] = raw.String()
+	node.mu.Unlock()
+
+	found, err := src.Transaction(ctx, &txid)
+	require.NoError(t, err)
+	require.Equal(t, txid, found.TxHash())
+
+	_, err = src.Transaction(ctx, &chainhash.Hash{9})
+	require.ErrorIs(t, err, blockchain.ErrTxNotFound)
+	require.ErrorContains(t, err, "No such")
+}
+
+// TestHeightAt checks the binary search for the first block mined after a
+// time.
+func TestHeightAt(t *testing.T) {
+	t.Parallel()
+
+	src, node := newTestSource(t, "main", "pass")
+	ctx := context.Background()
+
+	// One block every ten minutes from the start time, with a median
+	// time past that sometimes stays the same.
+	start := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	for height := 0; height < 1000; height++ {
+		medianTime := start.Add(time.Duration(height/2) * 20 * time.Minute)
+		node.medianTimes = append(node.medianTimes, medianTime.Unix())
+	}
+
+	// Times are looked up this much earlier.
+	window := TimestampWindow + medianTimeLag
+
+	tests := []struct {
+		name   string
+		t      time.Time
+		height int64
+	}{{
+		name:   "before genesis",
+		t:      start.Add(-24 * time.Hour),
+		height: 0,
+	}, {
+		name:   "within the window of genesis",
+		t:      start.Add(window),
+		height: 0,
+	}, {
+		name:   "first of equal median times",
+		t:      start.Add(window + 100*20*time.Minute),
+		height: 200,
+	}, {
+		name:   "between median times",
+		t:      start.Add(window + 100*20*time.Minute + time.Minute),
+		height: 202,
+	}, {
+		name:   "after the tip",
+		t:      start.Add(30 * 24 * time.Hour),
+		height: 999,
+	}}
+	for _, test := range tests {
+		height, err := src.HeightAt(ctx, test.t)
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.height, height, test.name)
+	}
+}
@@ -0,0 +1,140 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func openMock(*OpenConfig) (Source, error) {
+	return NewMock(), nil
+}
+
+// TestRegistry checks registration and lookups of backends.
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	require.NoError(t, r.Register(&Backend{
+		ID: "mock", Name: "Mock", Open: openMock,
+	}))
+	require.NoError(t, r.Register(&Backend{
+		ID: "other", Name: "Other", Open: openMock,
+	}))
+
+	// IDs and names are unique, and Open is required.
+	require.Error(t, r.Register(&Backend{
+		ID: "MOCK", Name: "Mock 2", Open: openMock,
+	}))
+	require.Error(t, r.Register(&Backend{
+		ID: "mock2", Name: "Mock", Open: openMock,
+	}))
+	require.Error(t, r.Register(&Backend{ID: "noopen", Name: "No Open"}))
+
+	backends := r.Backends()
+	require.Len(t, backends, 2)
+	require.Equal(t, "mock", backends[0].ID)
+
+	b, err := r.ByID("Other")
+	require.NoError(t, err)
+	require.Equal(t, "Other", b.Name)
+	b, err = r.ByName("Mock")
+	require.NoError(t, err)
+	require.Equal(t, "mock", b.ID)
+	_, err = r.ByID("missing")
+	require.Error(t, err)
+}
+
+// TestBackendSettings checks that unset options get the network's defaults.
+func TestBackendSettings(t *testing.T) {
+	t.Parallel()
+
+	b := &Backend{
+		ID: "node", Name: "Node", Open: openMock,
+		Options: []Option{{
+			Key: "host",
+			Default: func(n *wallet.Network) string {
+				return n.RPCHost
+			},
+		}, {
+			Key: "user",
+		}},
+	}
+
+	settings := b.Settings(wallet.TestNet, nil)
+	require.Equal(t, map[string]string{
+		"host": wallet.TestNet.RPCHost, "user": "",
+	}, settings)
+
+	settings = b.Settings(wallet.TestNet, map[string]string{
+		"host": "10.0.0.1:8332", "user": "alice", "ignored": "x",
+	})
+	require.Equal(t, map[string]string{
+		"host": "10.0.0.1:8332", "user": "alice",
+	}, settings)
+}
+
+// TestMock checks that the mock reports the outputs added to it.
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+	other, err := btcutil.NewAddressWitnessPubKeyHash(
+		append(make([]byte, 19), 1), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+
+	m := NewMock()
+	m.AddUTXO(addr, UTXO{
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{1}, Index: 0},
+		Value:    1000,
+		Height:   100,
+	})
+	m.AddUTXO(addr, UTXO{
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1},
+		Value:    500,
+	})
+
+	ctx := context.Background()
+	info, err := m.AddressInfo(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), info.TxCount)
+	require.Equal(t, btcutil.Amount(1000), info.Confirmed)
+	require.Equal(t, btcutil.Amount(1500), info.Balance())
+	require.True(t, info.Used())
+
+	history, err := m.History(ctx, addr)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.True(t, history[0].Confirmed())
+	require.False(t, history[1].Confirmed())
+
+	utxos, err := m.UTXOs(ctx, addr)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+
+	info, err = m.AddressInfo(ctx, other)
+	require.NoError(t, err)
+	require.False(t, info.Used())
+
+	tx := wire.NewMsgTx(2)
+	txid, err := m.Broadcast(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, tx.TxHash(), *txid)
+	require.Len(t, m.Broadcasts, 1)
+	require.Len(t, m.Lookups, 4)
+
+	m.Err = errors.New("down")
+	_, err = m.Balance(ctx, addr)
+	require.ErrorIs(t, err, m.Err)
+}
@@ -0,0 +1,345 @@
+package esplora
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// chainPageSize is the number of confirmed transactions Esplora
+	// returns per page.
+	chainPageSize = 25
+)
+
+var (
+	// ErrRateLimited is returned when the API rejects a request because
+	// of its rate limit.
+	ErrRateLimited = errors.New("esplora rate limit exceeded")
+)
+
+// Config configures an Esplora API source.
+type Config struct {
+	// BaseURL is the base URL of the API, such as
+	// https://blockstream.info/api.
+	BaseURL string
+
+	// HTTPClient performs the requests. http.DefaultClient is used if nil.
+	HTTPClient *http.Client
+
+	// RequestDelay is the minimum time between the start of two requests,
+	// which keeps parallel lookups within the rate limit of public
+	// instances.
+	RequestDelay time.Duration
+}
+
+// Source is a blockchain source backed by an Esplora HTTP API.
+type Source struct {
+	cfg *Config
+
+	mu          sync.Mutex
+	nextRequest time.Time
+}
+
+// A compile-time check to ensure Source implements the blockchain.Source
+// interface.
+var _ blockchain.Source = (*Source)(nil)
+
+// New returns an Esplora source.
+func New(cfg *Config) *Source {
+	return &Source{cfg: cfg}
+}
+
+// stats are the transaction statistics Esplora reports for an address, once
+// for the chain and once for the mempool.
+type stats struct {
+	FundedTxoCount uint64 `json:"funded_txo_count"`
+	FundedTxoSum   int64  `json:"funded_txo_sum"`
+	SpentTxoCount  uint64 `json:"spent_txo_count"`
+	SpentTxoSum    int64  `json:"spent_txo_sum"`
+	TxCount        uint64 `json:"tx_count"`
+}
+
+// addressResponse is the response of the address endpoint.
+type addressResponse struct {
+	ChainStats   stats `json:"chain_stats"`
+	MempoolStats stats `json:"mempool_stats"`
+}
+
+// status is the confirmation status of a transaction.
+type status struct {
+	Confirmed   bool  `json:"confirmed"`
+	BlockHeight int64 `json:"block_height"`
+}
+
+// height returns the confirmation height, zero if unconfirmed.
+func (s status) height() int64 {
+	if !s.Confirmed {
+		return 0
+	}
+
+	return s.BlockHeight
+}
+
+// txResponse is a transaction of the address transactions endpoints.
+type txResponse struct {
+	TxID   string `json:"txid"`
+	Status status `json:"status"`
+}
+
+// utxoResponse is an output of the address UTXO endpoint.
+type utxoResponse struct {
+	TxID   string `json:"txid"`
+	Vout   uint32 `json:"vout"`
+	Value  int64  `json:"value"`
+	Status status `json:"status"`
+}
+
+// wait blocks until the next request may start.
+func (s *Source) wait(ctx context.Context) error {
+	if s.cfg.RequestDelay == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	at := s.nextRequest
+	if at.Before(now) {
+		at = now
+	}
+	s.nextRequest = at.Add(s.cfg.RequestDelay)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// do performs a request and returns the response body. A 404 response is
+// reported as a nil body.
+func (s *Source) do(ctx context.Context, method, path string,
+	body []byte) ([]byte, error) {
+
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(s.cfg.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(
+		ctx, method, url, bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return respBody, nil
+	case http.StatusNotFound:
+		return nil, nil
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimited
+	default:
+		return nil, fmt.Errorf("esplora error %d: %s", resp.StatusCode,
+			strings.TrimSpace(string(respBody)))
+	}
+}
+
+// getJSON performs a GET request and decodes the JSON response into result.
+// It reports whether the resource was found.
+func (s *Source) getJSON(ctx context.Context, path string,
+	result interface{}) (bool, error) {
+
+	body, err := s.do(ctx, http.MethodGet, path, nil)
+	if err != nil || body == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return false, fmt.Errorf("invalid esplora response: %w", err)
+	}
+
+	return true, nil
+}
+
+// AddressInfo returns the transaction count and the balance of an address.
+// Unknown addresses are reported as unused.
+func (s *Source) AddressInfo(ctx context.Context,
+	addr btcutil.Address) (*blockchain.AddressInfo, error) {
+
+	var resp addressResponse
+	_, err := s.getJSON(ctx, "/address/"+addr.EncodeAddress(), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, mempool := resp.ChainStats, resp.MempoolStats
+	return &blockchain.AddressInfo{
+		TxCount: chain.TxCount + mempool.TxCount,
+		Confirmed: btcutil.Amount(
+			chain.FundedTxoSum - chain.SpentTxoSum,
+		),
+		Unconfirmed: btcutil.Amount(
+			mempool.FundedTxoSum - mempool.SpentTxoSum,
+		),
+	}, nil
+}
+
+// Balance returns the balance of an address.
+func (s *Source) Balance(ctx context.Context,
+	addr btcutil.Address) (btcutil.Amount, error) {
+
+	info, err := s.AddressInfo(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Balance(), nil
+}
+
+// History returns the mempool and the confirmed transactions of an address,
+// newest first. Confirmed transactions are fetched page by page.
+func (s *Source) History(ctx context.Context,
+	addr btcutil.Address) ([]blockchain.Tx, error) {
+
+	base := "/address/" + addr.EncodeAddress() + "/txs"
+
+	var mempool []txResponse
+	if _, err := s.getJSON(ctx, base+"/mempool", &mempool); err != nil {
+		return nil, err
+	}
+	all := mempool
+
+	lastSeen := ""
+	for {
+		var page []txResponse
+		_, err := s.getJSON(ctx, base+"/chain"+lastSeen, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if len(page) < chainPageSize {
+			break
+		}
+		lastSeen = "/" + page[len(page)-1].TxID
+	}
+
+	history := make([]blockchain.Tx, 0, len(all))
+	for _, tx := range all {
+		txid, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", tx.TxID, err)
+		}
+		history = append(history, blockchain.Tx{
+			TxID:   *txid,
+			Height: tx.Status.height(),
+		})
+	}
+
+	return history, nil
+}
+
+// UTXOs returns the unspent outputs of an address.
+func (s *Source) UTXOs(ctx context.Context,
+	addr btcutil.Address) ([]blockchain.UTXO, error) {
+
+	var resp []utxoResponse
+	_, err := s.getJSON(ctx, "/address/"+addr.EncodeAddress()+"/utxo", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]blockchain.UTXO, 0, len(resp))
+	for _, u := range resp {
+		txid, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", u.TxID, err)
+		}
+		utxos = append(utxos, blockchain.UTXO{
+			OutPoint: wire.OutPoint{Hash: *txid, Index: u.Vout},
+			Value:    btcutil.Amount(u.Value),
+			Height:   u.Status.height(),
+		})
+	}
+
+	return utxos, nil
+}
+
+// Transaction returns a transaction by its ID.
+func (s *Source) Transaction(ctx context.Context,
+	txid *chainhash.Hash) (*wire.MsgTx, error) {
+
+	body, err := s.do(
+		ctx, http.MethodGet, "/tx/"+txid.String()+"/hex", nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("%w: %v", blockchain.ErrTxNotFound, txid)
+	}
+
+	return blockchain.DecodeTx(strings.TrimSpace(string(body)))
+}
+
+// Broadcast publishes a transaction.
+func (s *Source) Broadcast(ctx context.Context,
+	tx *wire.MsgTx) (*chainhash.Hash, error) {
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	body, err := s.do(
+		ctx, http.MethodPost, "/tx",
+		[]byte(hex.EncodeToString(buf.Bytes())),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("esplora broadcast endpoint not found")
+	}
+
+	return chainhash.NewHashFromStr(strings.TrimSpace(string(body)))
+}
+
+// Close does nothing, as requests don't keep state.
+func (s *Source) Close() error {
+	return nil
+}
@@ -0,0 +1,232 @@
+package esplora
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// txid returns a distinct transaction ID for a number.
+func txid(n int) string {
+	return chainhash.Hash{byte(n), byte(n >> 8), 0xaa}.String()
+}
+
+// testTx returns a transaction known to the fake servers, and its hex
+// encoding.
+func testTx(t *testing.T) (*wire.MsgTx, string) {
+	t.Helper()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+
+	var raw strings.Builder
+	require.NoError(t, tx.Serialize(hex.NewEncoder(&raw)))
+
+	return tx, raw.String()
+}
+
+// newTestServer returns a fake Esplora API knowing a single used address
+// with 30 confirmed transactions, one mempool transaction and two UTXOs.
+func newTestServer(t *testing.T, addr btcutil.Address) (*httptest.Server,
+	*[]string) {
+
+	t.Helper()
+
+	var broadcasts []string
+	base := "/address/" + addr.EncodeAddress()
+	tx, rawTx := testTx(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == base:
+			fmt.Fprint(w, `{"chain_stats":{"funded_txo_sum":7000,`+
+				`"spent_txo_sum":2000,"tx_count":30},`+
+				`"mempool_stats":{"funded_txo_sum":0,`+
+				`"spent_txo_sum":500,"tx_count":1}}`)
+
+		case r.URL.Path == base+"/txs/mempool":
+			fmt.Fprintf(w, `[{"txid":%q,"status":`+
+				`{"confirmed":false}}]`, txid(1000))
+
+		case strings.HasPrefix(r.URL.Path, base+"/txs/chain"):
+			// The first page holds transactions 0-24, the
+			// second 25-29.
+			start := 0
+			if strings.HasSuffix(r.URL.Path, txid(24)) {
+				start = 25
+			}
+			var txs []string
+			for i := start; i < 30 && i < start+25; i++ {
+				txs = append(txs, fmt.Sprintf(
+					`{"txid":%q,"status":{"confirmed":true,`+
+						`"block_height":%d}}`, txid(i), 800000-i,
+				))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(txs, ","))
+
+		case r.URL.Path == base+"/utxo":
+			fmt.Fprintf(w, `[{"txid":%q,"vout":1,"value":4500,`+
+				`"status":{"confirmed":true,"block_height":799990}},`+
+				`{"txid":%q,"vout":0,"value":500,"status":`+
+				`{"confirmed":false}}]`, txid(10), txid(1000))
+
+		case r.URL.Path == "/tx" && r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			broadcasts = append(broadcasts, string(body))
+			fmt.Fprint(w, txid(2000))
+
+		case r.URL.Path == "/tx/"+tx.TxHash().String()+"/hex":
+			fmt.Fprint(w, rawTx)
+
+		case r.URL.Path == "/address/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &broadcasts
+}
+
+func testAddress(t *testing.T, b byte) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		append(make([]byte, 19), b), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+
+	return addr
+}
+
+// TestAddressInfo checks balances and transaction counts, including unknown
+// addresses.
+func TestAddressInfo(t *testing.T) {
+	t.Parallel()
+
+	addr := testAddress(t, 1)
+	server, _ := newTestServer(t, addr)
+	src := New(&Config{BaseURL: server.URL + "/"})
+	ctx := context.Background()
+
+	info, err := src.AddressInfo(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(31), info.TxCount)
+	require.Equal(t, btcutil.Amount(5000), info.Confirmed)
+	require.Equal(t, btcutil.Amount(-500), info.Unconfirmed)
+
+	balance, err := src.Balance(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(4500), balance)
+
+	// Esplora answers 404 for addresses it never saw.
+	info, err = src.AddressInfo(ctx, testAddress(t, 2))
+	require.NoError(t, err)
+	require.False(t, info.Used())
+}
+
+// TestHistoryPaging checks that all pages of confirmed transactions are
+// fetched after the mempool transactions.
+func TestHistoryPaging(t *testing.T) {
+	t.Parallel()
+
+	addr := testAddress(t, 1)
+	server, _ := newTestServer(t, addr)
+	src := New(&Config{BaseURL: server.URL})
+
+	history, err := src.History(context.Background(), addr)
+	require.NoError(t, err)
+	require.Len(t, history, 31)
+	require.False(t, history[0].Confirmed())
+	require.Equal(t, txid(1000), history[0].TxID.String())
+	require.Equal(t, txid(29), history[30].TxID.String())
+	require.Equal(t, int64(800000-29), history[30].Height)
+}
+
+// TestUTXOsAndBroadcast checks the UTXO listing and transaction broadcast.
+func TestUTXOsAndBroadcast(t *testing.T) {
+	t.Parallel()
+
+	addr := testAddress(t, 1)
+	server, broadcasts := newTestServer(t, addr)
+	src := New(&Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	utxos, err := src.UTXOs(ctx, addr)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	require.Equal(t, uint32(1), utxos[0].OutPoint.Index)
+	require.Equal(t, btcutil.Amount(4500), utxos[0].Value)
+	require.Equal(t, int64(799990), utxos[0].Height)
+	require.Zero(t, utxos[1].Height)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	hash, err := src.Broadcast(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, txid(2000), hash.String())
+
+	var raw strings.Builder
+	require.NoError(t, tx.Serialize(hex.NewEncoder(&raw)))
+	require.Equal(t, []string{raw.String()}, *broadcasts)
+}
+
+// TestTransaction checks the lookup of raw transactions.
+func TestTransaction(t *testing.T) {
+	t.Parallel()
+
+	addr := testAddress(t, 1)
+	server, _ := newTestServer(t, addr)
+	src := New(&Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	want, _ := testTx(t)
+	txid := want.TxHash()
+	tx, err := src.Transaction(ctx, &txid)
+	require.NoError(t, err)
+	require.Equal(t, txid, tx.TxHash())
+
+	_, err = src.Transaction(ctx, &chainhash.Hash{1})
+	require.ErrorIs(t, err, blockchain.ErrTxNotFound)
+}
+
+// TestRateLimit checks the rate limit error and the spacing of requests.
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	addr := testAddress(t, 1)
+	server, _ := newTestServer(t, addr)
+
+	src := New(&Config{BaseURL: server.URL})
+	_, err := src.getJSON(context.Background(), "/address/limited", nil)
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	src = New(&Config{
+		BaseURL:      server.URL,
+		RequestDelay: 50 * time.Millisecond,
+	})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := src.AddressInfo(context.Background(), addr)
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,215 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Mock is an in-memory Source for tests. Addresses are keyed by their encoded
+// form; unknown addresses are unused.
+type Mock struct {
+	mu sync.Mutex
+
+	// Infos, Histories and Unspent hold the data returned per address.
+	Infos     map[string]*AddressInfo
+	Histories map[string][]Tx
+	Unspent   map[string][]UTXO
+
+	// Txs holds the transactions returned by Transaction.
+	Txs map[chainhash.Hash]*wire.MsgTx
+
+	// Broadcasts records the transactions passed to Broadcast.
+	Broadcasts []*wire.MsgTx
+
+	// Lookups records the addresses of every call, in order.
+	Lookups []string
+
+	// Err, if set, is returned by every call.
+	Err error
+}
+
+// A compile-time check to ensure Mock implements the Source interface.
+var _ Source = (*Mock)(nil)
+
+// NewMock returns an empty mock source.
+func NewMock() *Mock {
+	return &Mock{
+		Infos:     make(map[string]*AddressInfo),
+		Histories: make(map[string][]Tx),
+		Unspent:   make(map[string][]UTXO),
+		Txs:       make(map[chainhash.Hash]*wire.MsgTx),
+	}
+}
+
+// AddUTXO adds an unspent output to an address, updating its info and history
+// accordingly.
+func (m *Mock) AddUTXO(addr btcutil.Address, utxo UTXO) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := addr.EncodeAddress()
+	m.Unspent[key] = append(m.Unspent[key], utxo)
+	m.Histories[key] = append(m.Histories[key], Tx{
+		TxID:   utxo.OutPoint.Hash,
+		Height: utxo.Height,
+	})
+
+	info, ok := m.Infos[key]
+	if !ok {
+		info = &AddressInfo{}
+		m.Infos[key] = info
+	}
+	info.TxCount++
+	if utxo.Height > 0 {
+		info.Confirmed += utxo.Value
+	} else {
+		info.Unconfirmed += utxo.Value
+	}
+}
+
+// AddTx adds a transaction returned by Transaction, along with its outputs to
+// the given addresses as unspent outputs confirmed at height. Outputs paying
+// to other addresses are ignored.
+func (m *Mock) AddTx(tx *wire.MsgTx, height int64, addrs ...btcutil.Address) {
+	m.mu.Lock()
+	m.Txs[tx.TxHash()] = tx
+	m.mu.Unlock()
+
+	for _, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			continue
+		}
+		for i, out := range tx.TxOut {
+			if !bytes.Equal(out.PkScript, pkScript) {
+				continue
+			}
+			m.AddUTXO(addr, UTXO{
+				OutPoint: wire.OutPoint{
+					Hash:  tx.TxHash(),
+					Index: uint32(i),
+				},
+				Value:  btcutil.Amount(out.Value),
+				Height: height,
+			})
+		}
+	}
+}
+
+// lookup records a call and returns the configured error.
+func (m *Mock) lookup(addr btcutil.Address) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := addr.EncodeAddress()
+	m.Lookups = append(m.Lookups, key)
+
+	return key, m.Err
+}
+
+// AddressInfo returns the configured info of an address.
+func (m *Mock) AddressInfo(_ context.Context,
+	addr btcutil.Address) (*AddressInfo, error) {
+
+	key, err := m.lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info := AddressInfo{}
+	if i, ok := m.Infos[key]; ok {
+		info = *i
+	}
+
+	return &info, nil
+}
+
+// Balance returns the balance of the configured info of an address.
+func (m *Mock) Balance(ctx context.Context,
+	addr btcutil.Address) (btcutil.Amount, error) {
+
+	info, err := m.AddressInfo(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Balance(), nil
+}
+
+// History returns the configured history of an address.
+func (m *Mock) History(_ context.Context, addr btcutil.Address) ([]Tx,
+	error) {
+
+	key, err := m.lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Tx(nil), m.Histories[key]...), nil
+}
+
+// UTXOs returns the configured unspent outputs of an address.
+func (m *Mock) UTXOs(_ context.Context, addr btcutil.Address) ([]UTXO,
+	error) {
+
+	key, err := m.lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]UTXO(nil), m.Unspent[key]...), nil
+}
+
+// Transaction returns a transaction added with AddTx.
+func (m *Mock) Transaction(_ context.Context,
+	txid *chainhash.Hash) (*wire.MsgTx, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	tx, ok := m.Txs[*txid]
+	if !ok {
+		return nil, ErrTxNotFound
+	}
+
+	return tx, nil
+}
+
+// Broadcast records the transaction.
+func (m *Mock) Broadcast(_ context.Context,
+	tx *wire.MsgTx) (*chainhash.Hash, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	m.Broadcasts = append(m.Broadcasts, tx)
+	txid := tx.TxHash()
+
+	return &txid, nil
+}
+
+// Close does nothing.
+func (m *Mock) Close() error {
+	return nil
+}
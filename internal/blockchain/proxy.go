@@ -0,0 +1,117 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/go-socks/socks"
+)
+
+const (
+	// httpTimeout is the timeout of HTTP requests made by sources. Onion
+	// services can take a while to answer.
+	httpTimeout = 60 * time.Second
+)
+
+var (
+	// ErrOnionNeedsProxy is returned when connecting to an onion host
+	// without a SOCKS5 proxy.
+	ErrOnionNeedsProxy = errors.New("onion hosts require a SOCKS5 proxy " +
+		"such as Tor")
+)
+
+// Proxy routes the connections of sources through a SOCKS5 proxy, such as
+// the one of a Tor daemon. A nil Proxy connects directly.
+type Proxy struct {
+	// Addr is the host:port of the proxy.
+	Addr string
+
+	// All routes every connection through the proxy. Otherwise only onion
+	// hosts are, so servers on the local network stay reachable.
+	All bool
+}
+
+// IsOnion reports whether a host or host:port is a Tor onion service.
+func IsOnion(addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+
+	return strings.HasSuffix(strings.ToLower(host), ".onion")
+}
+
+// Proxies reports whether connections to addr go through the proxy.
+func (p *Proxy) Proxies(addr string) bool {
+	if p == nil || p.Addr == "" {
+		return false
+	}
+
+	return p.All || IsOnion(addr)
+}
+
+// DialContext connects to addr, through the proxy if it applies. The host name
+// is resolved by the proxy, so onion hosts and DNS lookups don't leak.
+func (p *Proxy) DialContext(ctx context.Context, network,
+	addr string) (net.Conn, error) {
+
+	if !p.Proxies(addr) {
+		if IsOnion(addr) {
+			return nil, ErrOnionNeedsProxy
+		}
+
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+
+	proxy := &socks.Proxy{Addr: p.Addr}
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		if deadline, ok := ctx.Deadline(); ok {
+			r.conn, r.err = proxy.DialTimeout(
+				network, addr, time.Until(deadline),
+			)
+		} else {
+			r.conn, r.err = proxy.Dial(network, addr)
+		}
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+
+	case <-ctx.Done():
+		// Close the connection should it still be established.
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// HTTPClient returns an HTTP client whose connections are made with
+// DialContext. Proxy settings of the environment are ignored.
+func (p *Proxy) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: httpTimeout,
+		Transport: &http.Transport{
+			DialContext:         p.DialContext,
+			TLSHandshakeTimeout: httpTimeout,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
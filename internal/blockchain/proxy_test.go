@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSOCKS is a minimal SOCKS5 proxy that connects every request to a fixed
+// target and records the requested hosts.
+type fakeSOCKS struct {
+	listener net.Listener
+	target   string
+
+	mu    sync.Mutex
+	hosts []string
+}
+
+func newFakeSOCKS(t *testing.T, target string) *fakeSOCKS {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeSOCKS{listener: listener, target: target}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeSOCKS) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// Greeting: version, number of methods and the methods. Only "no
+	// authentication" is offered.
+	greeting := make([]byte, 3)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	// Connect request with a domain name address.
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	hostPort := make([]byte, int(header[4])+2)
+	if _, err := io.ReadFull(conn, hostPort); err != nil {
+		return
+	}
+	host := string(hostPort[:header[4]])
+	port := binary.BigEndian.Uint16(hostPort[header[4]:])
+
+	f.mu.Lock()
+	f.hosts = append(f.hosts, net.JoinHostPort(host, strconv.Itoa(int(port))))
+	f.mu.Unlock()
+
+	target, err := net.Dial("tcp", f.target)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go func() {
+		_, _ = io.Copy(target, conn)
+	}()
+	_, _ = io.Copy(conn, target)
+}
+
+func (f *fakeSOCKS) requested() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.hosts...)
+}
+
+// TestProxy checks which connections are routed through the proxy.
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		},
+	))
+	defer server.Close()
+	serverAddr := server.Listener.Addr().String()
+
+	get := func(p *Proxy, url string) error {
+		resp, err := p.HTTPClient().Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "ok", string(body))
+
+		return nil
+	}
+
+	// Onion hosts go through the proxy, which resolves them.
+	socks := newFakeSOCKS(t, serverAddr)
+	proxy := &Proxy{Addr: socks.listener.Addr().String()}
+	require.NoError(t, get(proxy, "http://example.onion:8/api"))
+	require.Equal(t, []string{"example.onion:8"}, socks.requested())
+
+	// Other hosts are reached directly, unless All is set.
+	require.NoError(t, get(proxy, server.URL))
+	require.Len(t, socks.requested(), 1)
+
+	proxy.All = true
+	require.NoError(t, get(proxy, server.URL))
+	require.Equal(t, serverAddr, socks.requested()[1])
+
+	// Without a proxy, onion hosts are refused rather than looked up.
+	var direct *Proxy
+	require.NoError(t, get(direct, server.URL))
+	err := get(direct, "http://example.onion/api")
+	require.ErrorIs(t, err, ErrOnionNeedsProxy)
+}
+
+// TestProxyCancel checks that a dial through an unresponsive proxy honors the
+// context.
+func TestProxyCancel(t *testing.T) {
+	t.Parallel()
+
+	// The listener accepts connections but never answers the greeting.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	proxy := &Proxy{Addr: listener.Addr().String()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = proxy.DialContext(ctx, "tcp", "example.onion:80")
+	require.ErrorIs(t, err, context.Canceled)
+}
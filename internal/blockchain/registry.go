@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"aezeed_address_generator_gui/internal/wallet"
+)
+
+// Option is a setting of a backend, such as a server address or a password.
+// Frontends build their configuration forms and flags from the options, so a
+// new backend needs no frontend changes.
+type Option struct {
+	// Key identifies the option in the settings passed to Open.
+	Key string
+
+	// Flag is the command line flag that sets the option.
+	Flag string
+
+	// Label names the option in forms, and Usage describes it in the
+	// command line help.
+	Label string
+	Usage string
+
+	// Placeholder is a hint shown in empty form fields.
+	Placeholder string
+
+	// Secret marks options, such as passwords, whose value is hidden.
+	Secret bool
+
+	// Bool marks on/off options, set to "true" when on.
+	Bool bool
+
+	// Default returns the value used for a network when the option isn't
+	// set. It may be nil.
+	Default func(n *wallet.Network) string
+}
+
+// DefaultValue returns the default value of the option for a network.
+func (o *Option) DefaultValue(n *wallet.Network) string {
+	if o.Default == nil {
+		return ""
+	}
+
+	return o.Default(n)
+}
+
+// Backend is a registered kind of source.
+type Backend struct {
+	// ID is the short identifier of the backend, as used on the command
+	// line.
+	ID string
+
+	// Name is the display name of the backend.
+	Name string
+
+	// Options are the settings of the backend.
+	Options []Option
+
+	// Concurrency is the number of addresses that should be looked up in
+	// parallel, or the batch size of a BatchSource.
+	Concurrency int
+
+	// Open returns a source. Sources should connect lazily, so opening
+	// one is cheap.
+	Open func(cfg *OpenConfig) (Source, error)
+}
+
+// OpenConfig is what a backend needs to open a source.
+type OpenConfig struct {
+	// Network is the network the source serves.
+	Network *wallet.Network
+
+	// Settings holds a value for every option, with the defaults filled
+	// in.
+	Settings map[string]string
+
+	// Proxy, if not nil, is the SOCKS5 proxy connections should use.
+	Proxy *Proxy
+}
+
+// Settings returns the settings of the backend for a network: the given
+// values with the defaults of unset options filled in.
+func (b *Backend) Settings(n *wallet.Network,
+	values map[string]string) map[string]string {
+
+	settings := make(map[string]string, len(b.Options))
+	for _, opt := range b.Options {
+		value, ok := values[opt.Key]
+		if !ok || value == "" {
+			value = opt.DefaultValue(n)
+		}
+		settings[opt.Key] = value
+	}
+
+	return settings
+}
+
+// Registry holds the available backends.
+type Registry struct {
+	mu       sync.RWMutex
+	backends []*Backend
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a backend. IDs and names must be unique.
+func (r *Registry) Register(b *Backend) error {
+	if b.ID == "" || b.Name == "" || b.Open == nil {
+		return fmt.Errorf("backend requires an ID, a name and Open")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.backends {
+		if strings.EqualFold(existing.ID, b.ID) ||
+			existing.Name == b.Name {
+
+			return fmt.Errorf("backend %s already registered", b.ID)
+		}
+	}
+	r.backends = append(r.backends, b)
+
+	return nil
+}
+
+// Backends returns the registered backends in registration order.
+func (r *Registry) Backends() []*Backend {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*Backend(nil), r.backends...)
+}
+
+// ByID returns the backend with the given ID, ignoring case.
+func (r *Registry) ByID(id string) (*Backend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, b := range r.backends {
+		if strings.EqualFold(b.ID, id) {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown backend %q", id)
+}
+
+// ByName returns the backend with the given display name.
+func (r *Registry) ByName(name string) (*Backend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, b := range r.backends {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown backend %q", name)
+}
@@ -0,0 +1,144 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrNotSupported is returned by sources that can't perform an
+	// operation, such as a node without an address index asked for the
+	// history of an address.
+	ErrNotSupported = errors.New("operation not supported by the source")
+
+	// ErrTxNotFound is returned when a transaction is unknown to the
+	// source.
+	ErrTxNotFound = errors.New("transaction not found")
+)
+
+// AddressInfo is what a source knows about an address.
+type AddressInfo struct {
+	// TxCount is the number of transactions involving the address. Sources
+	// that only see unspent outputs report the number of those instead.
+	TxCount uint64
+
+	// Confirmed and Unconfirmed are the confirmed balance and the balance
+	// change of mempool transactions.
+	Confirmed   btcutil.Amount
+	Unconfirmed btcutil.Amount
+}
+
+// Balance returns the confirmed plus the unconfirmed balance.
+func (i *AddressInfo) Balance() btcutil.Amount {
+	return i.Confirmed + i.Unconfirmed
+}
+
+// Used reports whether the address has ever been seen on chain.
+func (i *AddressInfo) Used() bool {
+	return i.TxCount > 0 || i.Balance() > 0
+}
+
+// Tx is a transaction of an address's history.
+type Tx struct {
+	// TxID is the transaction ID.
+	TxID chainhash.Hash
+
+	// Height is the confirmation height, or zero or less for mempool
+	// transactions.
+	Height int64
+}
+
+// Confirmed reports whether the transaction is in a block.
+func (t *Tx) Confirmed() bool {
+	return t.Height > 0
+}
+
+// UTXO is an unspent output of an address.
+type UTXO struct {
+	// OutPoint locates the output.
+	OutPoint wire.OutPoint
+
+	// Value is the value of the output.
+	Value btcutil.Amount
+
+	// Height is the confirmation height, or zero or less for mempool
+	// outputs.
+	Height int64
+}
+
+// DecodeTx decodes a hex encoded raw transaction.
+func DecodeTx(rawTx string) (*wire.MsgTx, error) {
+	raw, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hex: %w", err)
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// Source is a blockchain backend addresses can be looked up on and
+// transactions broadcast through. Implementations must be safe for concurrent
+// use. Operations a source can't perform return ErrNotSupported.
+type Source interface {
+	// AddressInfo returns the transaction count and the balance of an
+	// address.
+	AddressInfo(ctx context.Context,
+		addr btcutil.Address) (*AddressInfo, error)
+
+	// Balance returns the confirmed plus the unconfirmed balance of an
+	// address.
+	Balance(ctx context.Context, addr btcutil.Address) (btcutil.Amount,
+		error)
+
+	// History returns the transactions involving an address.
+	History(ctx context.Context, addr btcutil.Address) ([]Tx, error)
+
+	// UTXOs returns the unspent outputs of an address.
+	UTXOs(ctx context.Context, addr btcutil.Address) ([]UTXO, error)
+
+	// Transaction returns a transaction by its ID. Spending legacy outputs
+	// requires the whole transaction creating them.
+	Transaction(ctx context.Context, txid *chainhash.Hash) (*wire.MsgTx,
+		error)
+
+	// Broadcast publishes a transaction and returns its ID.
+	Broadcast(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash,
+		error)
+
+	// Close releases the connections of the source.
+	Close() error
+}
+
+// BatchSource is a Source that can look up several addresses with a single
+// request.
+type BatchSource interface {
+	Source
+
+	// AddressInfos returns the info of the addresses, in order.
+	AddressInfos(ctx context.Context,
+		addrs []btcutil.Address) ([]*AddressInfo, error)
+}
+
+// HeightSource is a Source that can find the blocks mined after a time, such
+// as a full node. Rescans of a wallet can start there instead of at the
+// genesis block.
+type HeightSource interface {
+	Source
+
+	// HeightAt returns the height a rescan for the transactions made at
+	// or after t can start from.
+	HeightAt(ctx context.Context, t time.Time) (int64, error)
+}
@@ -0,0 +1,258 @@
+// Package broadcast decodes signed transactions, given as raw hex or as a
+// finalized PSBT, and checks them before they are published.
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrNotFinalized is returned for PSBTs that lack signatures.
+	ErrNotFinalized = errors.New("PSBT is not fully signed")
+
+	// ErrUnsigned is returned for transactions with unsigned inputs.
+	ErrUnsigned = errors.New("transaction has unsigned inputs")
+
+	// ErrUnknownPrevOuts is returned when the outputs spent by a
+	// transaction are needed but not all of them are known.
+	ErrUnknownPrevOuts = errors.New("spent outputs are unknown")
+)
+
+// psbtMagic starts every serialized PSBT.
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Tx is a signed transaction along with the outputs it spends, as far as
+// they are known.
+type Tx struct {
+	// MsgTx is the transaction.
+	MsgTx *wire.MsgTx
+
+	// PrevOuts are the outputs spent by each input, nil where unknown.
+	PrevOuts []*wire.TxOut
+
+	// FromPSBT reports whether the transaction was extracted from a PSBT.
+	FromPSBT bool
+}
+
+// Decode decodes a signed transaction given as raw hex, or a finalized PSBT
+// in base64 or hex.
+func Decode(s string) (*Tx, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return nil, errors.New("empty transaction")
+	}
+
+	raw, err := hex.DecodeString(s)
+	switch {
+	case err == nil && bytes.HasPrefix(raw, psbtMagic):
+		return decodePSBT(raw, false)
+
+	case err == nil:
+		tx, err := blockchain.DecodeTx(s)
+		if err != nil {
+			return nil, err
+		}
+		return newTx(tx, make([]*wire.TxOut, len(tx.TxIn)), false)
+
+	default:
+		return decodePSBT([]byte(s), true)
+	}
+}
+
+// decodePSBT finalizes a PSBT and extracts its transaction.
+func decodePSBT(raw []byte, b64 bool) (*Tx, error) {
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), b64)
+	if err != nil {
+		return nil, fmt.Errorf("neither a transaction nor a PSBT: %w",
+			err)
+	}
+
+	if !packet.IsComplete() {
+		if err := psbt.MaybeFinalizeAll(packet); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotFinalized, err)
+		}
+	}
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	prevOuts := make([]*wire.TxOut, len(tx.TxIn))
+	for i, in := range packet.Inputs {
+		outPoint := tx.TxIn[i].PreviousOutPoint
+		switch {
+		case in.WitnessUtxo != nil:
+			prevOuts[i] = in.WitnessUtxo
+
+		case in.NonWitnessUtxo != nil &&
+			in.NonWitnessUtxo.TxHash() == outPoint.Hash &&
+			int(outPoint.Index) < len(in.NonWitnessUtxo.TxOut):
+
+			prevOuts[i] = in.NonWitnessUtxo.TxOut[outPoint.Index]
+		}
+	}
+
+	return newTx(tx, prevOuts, true)
+}
+
+// newTx checks that a transaction is complete and signed.
+func newTx(tx *wire.MsgTx, prevOuts []*wire.TxOut, fromPSBT bool) (*Tx,
+	error) {
+
+	if len(tx.TxIn) == 0 || len(tx.TxOut) == 0 {
+		return nil, errors.New("transaction has no inputs or outputs")
+	}
+	for _, in := range tx.TxIn {
+		if len(in.SignatureScript) == 0 && len(in.Witness) == 0 {
+			return nil, ErrUnsigned
+		}
+	}
+
+	return &Tx{MsgTx: tx, PrevOuts: prevOuts, FromPSBT: fromPSBT}, nil
+}
+
+// LookupPrevOuts fetches the unknown spent outputs from a source. Outputs the
+// source can't return stay unknown.
+func (t *Tx) LookupPrevOuts(ctx context.Context, src blockchain.Source) error {
+	for i, in := range t.MsgTx.TxIn {
+		if t.PrevOuts[i] != nil {
+			continue
+		}
+
+		hash := in.PreviousOutPoint.Hash
+		prevTx, err := src.Transaction(ctx, &hash)
+		switch {
+		case errors.Is(err, blockchain.ErrNotSupported):
+			return nil
+
+		case errors.Is(err, blockchain.ErrTxNotFound):
+			continue
+
+		case err != nil:
+			return err
+		}
+
+		index := in.PreviousOutPoint.Index
+		if int(index) >= len(prevTx.TxOut) {
+			return fmt.Errorf("input %d spends missing output %v", i,
+				in.PreviousOutPoint)
+		}
+		t.PrevOuts[i] = prevTx.TxOut[index]
+	}
+
+	return nil
+}
+
+// Weight returns the weight of the transaction.
+func (t *Tx) Weight() int64 {
+	return int64(t.MsgTx.SerializeSizeStripped()*3 +
+		t.MsgTx.SerializeSize())
+}
+
+// VSize returns the virtual size of the transaction.
+func (t *Tx) VSize() int64 {
+	return (t.Weight() + 3) / 4
+}
+
+// OutputTotal returns the value of all outputs.
+func (t *Tx) OutputTotal() btcutil.Amount {
+	var total btcutil.Amount
+	for _, out := range t.MsgTx.TxOut {
+		total += btcutil.Amount(out.Value)
+	}
+
+	return total
+}
+
+// InputTotal returns the value of all spent outputs. ErrUnknownPrevOuts is
+// returned if any of them is unknown.
+func (t *Tx) InputTotal() (btcutil.Amount, error) {
+	var total btcutil.Amount
+	for _, prevOut := range t.PrevOuts {
+		if prevOut == nil {
+			return 0, ErrUnknownPrevOuts
+		}
+		total += btcutil.Amount(prevOut.Value)
+	}
+
+	return total, nil
+}
+
+// Fee returns the fee paid by the transaction.
+func (t *Tx) Fee() (btcutil.Amount, error) {
+	in, err := t.InputTotal()
+	if err != nil {
+		return 0, err
+	}
+	fee := in - t.OutputTotal()
+	if fee < 0 {
+		return 0, fmt.Errorf("outputs exceed inputs by %v", -fee)
+	}
+
+	return fee, nil
+}
+
+// FeeRate returns the fee rate in sat/vB.
+func (t *Tx) FeeRate() (float64, error) {
+	fee, err := t.Fee()
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(fee) / float64(t.VSize()), nil
+}
+
+// OutputAddress returns the address an output pays to, or an empty string
+// for non-standard scripts.
+func (t *Tx) OutputAddress(i int, params *chaincfg.Params) string {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+		t.MsgTx.TxOut[i].PkScript, params,
+	)
+	if err != nil || len(addrs) != 1 {
+		return ""
+	}
+
+	return addrs[0].EncodeAddress()
+}
+
+// Verify runs the scripts of every input. ErrUnknownPrevOuts is returned if
+// any spent output is unknown, as signatures commit to them.
+func (t *Tx) Verify() error {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, prevOut := range t.PrevOuts {
+		if prevOut == nil {
+			return ErrUnknownPrevOuts
+		}
+		fetcher.AddPrevOut(t.MsgTx.TxIn[i].PreviousOutPoint, prevOut)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(t.MsgTx, fetcher)
+	for i, prevOut := range t.PrevOuts {
+		vm, err := txscript.NewEngine(
+			prevOut.PkScript, t.MsgTx, i,
+			txscript.StandardVerifyFlags, nil, sigHashes,
+			prevOut.Value, fetcher,
+		)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return fmt.Errorf("input %d is invalid: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/sweep"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// newSweep builds a sweep of a P2WPKH and a P2TR output of a test wallet,
+// whose funding transactions are known to the returned mock source.
+func newSweep(t *testing.T) (*sweep.Sweep, *blockchain.Mock) {
+	t.Helper()
+
+	w, err := wallet.NewFromSeed(make([]byte, 32), wallet.MainNet)
+	require.NoError(t, err)
+
+	src := blockchain.NewMock()
+	var addrs []*wallet.DerivedAddress
+	for i, purpose := range []uint32{
+		wallet.BIP84Purpose, wallet.BIP86Purpose,
+	} {
+		addr, err := w.DeriveAddress(purpose, 0, 0, 0)
+		require.NoError(t, err)
+		pkScript, err := txscript.PayToAddrScript(addr.Address)
+		require.NoError(t, err)
+
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+			Hash: chainhash.Hash{byte(i + 1)},
+		}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(30_000, pkScript))
+		src.AddTx(tx, 100, addr.Address)
+
+		addrs = append(addrs, addr)
+	}
+
+	inputs, err := sweep.CollectInputs(context.Background(), src, addrs)
+	require.NoError(t, err)
+	dest, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+
+	s, err := sweep.Build(&sweep.Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: dest,
+		FeeRate:     3,
+	})
+	require.NoError(t, err)
+
+	return s, src
+}
+
+// txHex returns the hex encoded transaction.
+func txHex(t *testing.T, tx *wire.MsgTx) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, tx.Serialize(&buf))
+
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// TestDecodePSBT checks that finalized PSBTs are decoded with the outputs
+// they spend, and that unsigned ones are rejected.
+func TestDecodePSBT(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newSweep(t)
+	unsigned, err := s.Base64()
+	require.NoError(t, err)
+	_, err = Decode(unsigned)
+	require.ErrorIs(t, err, ErrNotFinalized)
+
+	require.NoError(t, s.Sign())
+	signed, err := s.Base64()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Packet.Serialize(&buf))
+	for _, encoded := range []string{
+		signed, hex.EncodeToString(buf.Bytes()),
+	} {
+		tx, err := Decode(encoded)
+		require.NoError(t, err)
+		require.True(t, tx.FromPSBT)
+
+		fee, err := tx.Fee()
+		require.NoError(t, err)
+		require.Equal(t, s.Fee, fee)
+		require.Equal(t, s.Amount, tx.OutputTotal())
+		require.LessOrEqual(t, tx.VSize(), s.VSize)
+		require.NoError(t, tx.Verify())
+		require.Equal(
+			t, "bc1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq9e75rs",
+			tx.OutputAddress(0, wallet.MainNet.Params),
+		)
+	}
+}
+
+// TestDecodeRawTx checks that the spent outputs of raw transactions are
+// looked up on the source before they are verified.
+func TestDecodeRawTx(t *testing.T) {
+	t.Parallel()
+
+	s, src := newSweep(t)
+	require.NoError(t, s.Sign())
+	signed, err := s.Tx()
+	require.NoError(t, err)
+
+	// Whitespace, as left by copying from a terminal, is ignored.
+	raw := txHex(t, signed)
+	tx, err := Decode(raw[:20] + "\n  " + raw[20:])
+	require.NoError(t, err)
+	require.False(t, tx.FromPSBT)
+	require.Equal(t, signed.TxHash(), tx.MsgTx.TxHash())
+
+	_, err = tx.Fee()
+	require.ErrorIs(t, err, ErrUnknownPrevOuts)
+	require.ErrorIs(t, tx.Verify(), ErrUnknownPrevOuts)
+
+	require.NoError(t, tx.LookupPrevOuts(context.Background(), src))
+	fee, err := tx.Fee()
+	require.NoError(t, err)
+	require.Equal(t, s.Fee, fee)
+	require.NoError(t, tx.Verify())
+
+	// Changing an output invalidates the signatures.
+	tx.MsgTx.TxOut[0].Value--
+	require.Error(t, tx.Verify())
+}
+
+// TestDecodeInvalid checks the rejected inputs.
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Decode("")
+	require.Error(t, err)
+
+	_, err = Decode("not a transaction")
+	require.ErrorContains(t, err, "neither a transaction nor a PSBT")
+
+	// A transaction without signatures can't be broadcast.
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil,
+		nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	_, err = Decode(txHex(t, tx))
+	require.ErrorIs(t, err, ErrUnsigned)
+}
@@ -0,0 +1,212 @@
+package chanbackup
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// addressType is the type byte lnd prefixes every serialized network address
+// with.
+type addressType byte
+
+const (
+	noAddr      addressType = 0
+	tcp4Addr    addressType = 1
+	tcp6Addr    addressType = 2
+	v2OnionAddr addressType = 3
+	v3OnionAddr addressType = 4
+)
+
+const (
+	// v2OnionLen and v3OnionLen are the lengths of the decoded service
+	// identifiers of v2 and v3 onion addresses.
+	v2OnionLen = 10
+	v3OnionLen = 35
+
+	onionSuffix = ".onion"
+)
+
+// onionEncoding is the base32 alphabet of onion service names.
+var onionEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567")
+
+// OnionAddr is a Tor onion service address.
+type OnionAddr struct {
+	// OnionService is the host of the service, including the .onion
+	// suffix.
+	OnionService string
+
+	// Port is the port the node listens on.
+	Port int
+}
+
+// Network returns the network of the address.
+func (o *OnionAddr) Network() string {
+	return "tcp"
+}
+
+// String returns the address in host:port form.
+func (o *OnionAddr) String() string {
+	return net.JoinHostPort(o.OnionService, strconv.Itoa(o.Port))
+}
+
+// OpaqueAddrs holds serialized addresses of a type this package doesn't know.
+// As the length of an unknown address can't be determined, it holds all bytes
+// from the first unknown address on.
+type OpaqueAddrs struct {
+	// Payload is the raw address data.
+	Payload []byte
+}
+
+// Network returns the network of the address.
+func (o *OpaqueAddrs) Network() string {
+	return "opaque"
+}
+
+// String returns the hex encoded payload.
+func (o *OpaqueAddrs) String() string {
+	return fmt.Sprintf("opaque:%x", o.Payload)
+}
+
+// writeAddrs serializes a list of addresses the way lnd does: a big-endian
+// uint16 total length followed by every address prefixed with its type.
+func writeAddrs(w io.Writer, addrs []net.Addr) error {
+	var b bytes.Buffer
+	for _, addr := range addrs {
+		if err := writeAddr(&b, addr); err != nil {
+			return err
+		}
+	}
+	if b.Len() > 0xffff {
+		return fmt.Errorf("serialized addresses too long: %d bytes",
+			b.Len())
+	}
+
+	if err := binary.Write(w, byteOrder, uint16(b.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// writeAddr serializes a single address including its type.
+func writeAddr(b *bytes.Buffer, addr net.Addr) error {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		if ip4 := a.IP.To4(); ip4 != nil {
+			b.WriteByte(byte(tcp4Addr))
+			b.Write(ip4)
+		} else {
+			b.WriteByte(byte(tcp6Addr))
+			b.Write(a.IP.To16())
+		}
+
+	case *OnionAddr:
+		host := strings.TrimSuffix(a.OnionService, onionSuffix)
+		service, err := onionEncoding.DecodeString(host)
+		if err != nil {
+			return fmt.Errorf("invalid onion address %v: %w", a, err)
+		}
+		switch len(service) {
+		case v2OnionLen:
+			b.WriteByte(byte(v2OnionAddr))
+		case v3OnionLen:
+			b.WriteByte(byte(v3OnionAddr))
+		default:
+			return fmt.Errorf("invalid onion address %v", a)
+		}
+		b.Write(service)
+
+	case *OpaqueAddrs:
+		b.Write(a.Payload)
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported address type %T", addr)
+	}
+
+	var port [2]byte
+	byteOrder.PutUint16(port[:], uint16(portOf(addr)))
+	b.Write(port[:])
+
+	return nil
+}
+
+// portOf returns the port of a TCP or onion address.
+func portOf(addr net.Addr) int {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.Port
+	case *OnionAddr:
+		return a.Port
+	}
+
+	return 0
+}
+
+// readAddrs is the inverse of writeAddrs.
+func readAddrs(r io.Reader) ([]net.Addr, error) {
+	var addrLen uint16
+	if err := binary.Read(r, byteOrder, &addrLen); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, addrLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	var addrs []net.Addr
+	for len(payload) > 0 {
+		var addrBytes int
+		switch addressType(payload[0]) {
+		case noAddr:
+			// A padding byte without address or port.
+			payload = payload[1:]
+			continue
+		case tcp4Addr:
+			addrBytes = net.IPv4len
+		case tcp6Addr:
+			addrBytes = net.IPv6len
+		case v2OnionAddr:
+			addrBytes = v2OnionLen
+		case v3OnionAddr:
+			addrBytes = v3OnionLen
+		default:
+			// We can't know where an unknown address ends, so the
+			// remaining data is kept as is.
+			return append(addrs, &OpaqueAddrs{Payload: payload}), nil
+		}
+
+		// Type, address and port.
+		if len(payload) < 1+addrBytes+2 {
+			return nil, fmt.Errorf("truncated address of type %d",
+				payload[0])
+		}
+		data := payload[1 : 1+addrBytes]
+		port := int(byteOrder.Uint16(payload[1+addrBytes:]))
+
+		switch addressType(payload[0]) {
+		case tcp4Addr, tcp6Addr:
+			ip := make(net.IP, addrBytes)
+			copy(ip, data)
+			addrs = append(addrs, &net.TCPAddr{IP: ip, Port: port})
+
+		default:
+			addrs = append(addrs, &OnionAddr{
+				OnionService: onionEncoding.EncodeToString(
+					data,
+				) + onionSuffix,
+				Port: port,
+			})
+		}
+		payload = payload[1+addrBytes+2:]
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,320 @@
+package chanbackup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testSeed and otherSeed are arbitrary BIP32 seeds of two different
+	// nodes.
+	testSeed  = bytes.Repeat([]byte{0x01}, 32)
+	otherSeed = bytes.Repeat([]byte{0x02}, 32)
+
+	// lndBackupMnemonic and lndBackupPassphrase are the aezeed of the lnd
+	// v0.19.1 node that wrote testdata/channel.backup. The m/1017' private
+	// key of the seed has a leading zero byte.
+	lndBackupMnemonic = "ability income blush dry picnic raise left " +
+		"absorb laugh oval violin onion obvious sunny multiply neutral " +
+		"imitate vault zone gap hunt quit hundred borrow"
+	lndBackupPassphrase = "vector passphrase"
+)
+
+func newTestKeyRing(t *testing.T, seed []byte) *wallet.Wallet {
+	t.Helper()
+
+	w, err := wallet.NewFromSeed(seed, wallet.MainNet)
+	require.NoError(t, err)
+
+	return w
+}
+
+func testPubKey(t *testing.T, b byte) *btcec.PublicKey {
+	t.Helper()
+
+	priv, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{b}, 32))
+
+	return priv.PubKey()
+}
+
+// newTestSingle returns a backup with every field set.
+func newTestSingle(t *testing.T, version SingleBackupVersion) Single {
+	t.Helper()
+
+	s := Single{
+		Version:     version,
+		IsInitiator: true,
+		ChainHash:   *chaincfg.MainNetParams.GenesisHash,
+		FundingOutpoint: wire.OutPoint{
+			Hash:  [32]byte{0xaa, 0xbb},
+			Index: 1,
+		},
+		ShortChannelID: 800000<<40 | 1234<<16 | 1,
+		RemoteNodePub:  testPubKey(t, 0x10),
+		Addresses: []net.Addr{
+			&net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 9735},
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 9736},
+			&OnionAddr{
+				OnionService: "3g2upl4pq6kufc4m.onion",
+				Port:         9735,
+			},
+			&OnionAddr{
+				OnionService: "vww6ybal4bd7szmgncyruucpgfkqahzddi37" +
+					"ktceo3ah7ngmcopnpyyd.onion",
+				Port: 9735,
+			},
+		},
+		Capacity:      1_000_000,
+		LocalCsvDelay: 144,
+		LocalKeys: LocalKeys{
+			MultiSig:       KeyLocator{wallet.KeyFamilyMultiSig, 3},
+			RevocationBase: KeyLocator{wallet.KeyFamilyRevocationBase, 3},
+			PaymentBase:    KeyLocator{wallet.KeyFamilyPaymentBase, 3},
+			DelayBase:      KeyLocator{wallet.KeyFamilyDelayBase, 3},
+			HtlcBase:       KeyLocator{wallet.KeyFamilyHtlcBase, 3},
+		},
+		RemoteCsvDelay: 720,
+		RemoteKeys: RemoteKeys{
+			MultiSig:       testPubKey(t, 0x20),
+			RevocationBase: testPubKey(t, 0x21),
+			PaymentBase:    testPubKey(t, 0x22),
+			DelayBase:      testPubKey(t, 0x23),
+			HtlcBase:       testPubKey(t, 0x24),
+		},
+		ShaChainRoot: KeyLocator{wallet.KeyFamilyRevocationRoot, 3},
+	}
+	if version == ScriptEnforcedLeaseVersion {
+		s.LeaseExpiry = 850000
+	}
+
+	return s
+}
+
+// TestSingleRoundTrip checks that every backup version survives packing and
+// unpacking.
+func TestSingleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	keyRing := newTestKeyRing(t, testSeed)
+	for v := DefaultSingleVersion; v <= SimpleTaprootFinalVersion; v++ {
+		single := newTestSingle(t, v)
+
+		var packed bytes.Buffer
+		require.NoError(t, single.PackToWriter(&packed, keyRing))
+
+		var unpacked Single
+		err := unpacked.UnpackFromReader(&packed, keyRing)
+		require.NoError(t, err)
+		require.Equal(t, single, unpacked, "version %v", v)
+	}
+}
+
+// TestSingleSerialization checks the plaintext encoding of the fixed fields
+// against lnd's layout.
+func TestSingleSerialization(t *testing.T) {
+	t.Parallel()
+
+	single := newTestSingle(t, AnchorsCommitVersion)
+	single.Addresses = []net.Addr{
+		&net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 9735},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, single.Serialize(&b))
+	raw := b.Bytes()
+
+	// Version and body length.
+	require.Equal(t, byte(AnchorsCommitVersion), raw[0])
+	require.Equal(t, len(raw)-3, int(byteOrder.Uint16(raw[1:3])))
+
+	// Initiator flag, chain hash and funding outpoint with a uint16
+	// index.
+	body := raw[3:]
+	require.Equal(t, byte(1), body[0])
+	require.Equal(t, chaincfg.MainNetParams.GenesisHash[:], body[1:33])
+	require.Equal(t, byte(0xaa), body[33])
+	require.Equal(t, "0001", hex.EncodeToString(body[65:67]))
+
+	// The single TCP address: total length, type, IP and port.
+	addrs := body[67+8+33:]
+	require.Equal(t, "0007"+"01"+"0a000001"+"2607",
+		hex.EncodeToString(addrs[:9]))
+}
+
+// TestMultiRoundTrip checks packing and unpacking of a channel.backup file.
+func TestMultiRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	keyRing := newTestKeyRing(t, testSeed)
+	multi := Multi{
+		StaticBackups: []Single{
+			newTestSingle(t, TweaklessCommitVersion),
+			newTestSingle(t, ScriptEnforcedLeaseVersion),
+			newTestSingle(t, SimpleTaprootVersion),
+		},
+	}
+
+	var packed bytes.Buffer
+	require.NoError(t, multi.PackToWriter(&packed, keyRing))
+
+	var unpacked Multi
+	require.NoError(t, unpacked.UnpackFromReader(&packed, keyRing))
+	require.Equal(t, multi, unpacked)
+}
+
+// TestUnpackLNDBackup checks that a channel.backup file written by lnd itself
+// is decrypted with the seed of the node and parsed.
+func TestUnpackLNDBackup(t *testing.T) {
+	t.Parallel()
+
+	var mnemonic crypto.Mnemonic
+	copy(mnemonic[:], strings.Fields(lndBackupMnemonic))
+	seed, err := mnemonic.ToCipherSeed([]byte(lndBackupPassphrase))
+	require.NoError(t, err)
+	keyRing, err := wallet.New(seed, wallet.MainNet)
+	require.NoError(t, err)
+
+	packed, err := os.ReadFile(filepath.Join("testdata", "channel.backup"))
+	require.NoError(t, err)
+
+	var multi Multi
+	require.NoError(t, multi.UnpackFromReader(
+		bytes.NewReader(packed), keyRing,
+	))
+	require.Len(t, multi.StaticBackups, 1)
+
+	fundingHash := sha256.Sum256([]byte("funding"))
+	shaChainRoot, err := keyRing.DeriveLNDKey(
+		wallet.KeyFamilyRevocationRoot, 0,
+	)
+	require.NoError(t, err)
+	expected := Single{
+		Version:     AnchorsCommitVersion,
+		IsInitiator: true,
+		ChainHash:   *chaincfg.MainNetParams.GenesisHash,
+		FundingOutpoint: wire.OutPoint{
+			Hash:  fundingHash,
+			Index: 1,
+		},
+		ShortChannelID: 800000<<40 | 12<<16 | 1,
+		RemoteNodePub:  testPubKey(t, 0x11),
+		Addresses: []net.Addr{
+			&net.TCPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 9735},
+		},
+		Capacity:      1_000_000,
+		LocalCsvDelay: 144,
+		LocalKeys: LocalKeys{
+			MultiSig:       KeyLocator{wallet.KeyFamilyMultiSig, 0},
+			RevocationBase: KeyLocator{wallet.KeyFamilyRevocationBase, 0},
+			PaymentBase:    KeyLocator{wallet.KeyFamilyPaymentBase, 0},
+			DelayBase:      KeyLocator{wallet.KeyFamilyDelayBase, 0},
+			HtlcBase:       KeyLocator{wallet.KeyFamilyHtlcBase, 0},
+		},
+		RemoteCsvDelay: 144,
+		RemoteKeys: RemoteKeys{
+			MultiSig:       testPubKey(t, 0x21),
+			RevocationBase: testPubKey(t, 0x22),
+			PaymentBase:    testPubKey(t, 0x23),
+			DelayBase:      testPubKey(t, 0x24),
+			HtlcBase:       testPubKey(t, 0x25),
+		},
+		ShaChainRootPub: shaChainRoot.PubKey,
+		ShaChainRoot:    KeyLocator{wallet.KeyFamilyRevocationRoot, 0},
+	}
+	require.Equal(t, expected, multi.StaticBackups[0])
+}
+
+// TestUnpackWrongSeed checks that a backup of another node is rejected.
+func TestUnpackWrongSeed(t *testing.T) {
+	t.Parallel()
+
+	multi := Multi{
+		StaticBackups: []Single{newTestSingle(t, DefaultSingleVersion)},
+	}
+	var packed bytes.Buffer
+	err := multi.PackToWriter(&packed, newTestKeyRing(t, testSeed))
+	require.NoError(t, err)
+
+	var unpacked Multi
+	err = unpacked.UnpackFromReader(
+		bytes.NewReader(packed.Bytes()), newTestKeyRing(t, otherSeed),
+	)
+	require.ErrorIs(t, err, ErrWrongSeed)
+
+	err = unpacked.UnpackFromReader(
+		bytes.NewReader(packed.Bytes()[:20]), newTestKeyRing(t, testSeed),
+	)
+	require.ErrorIs(t, err, ErrBackupTooShort)
+}
+
+// TestUnknownVersion checks that backups of unknown versions are refused
+// instead of being misparsed.
+func TestUnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	single := newTestSingle(t, SimpleTaprootFinalVersion+1)
+	err := single.Serialize(&bytes.Buffer{})
+
+	var versionErr ErrUnknownVersion
+	require.True(t, errors.As(err, &versionErr))
+	require.Equal(t, "single", versionErr.Kind)
+
+	var unpacked Single
+	err = unpacked.Deserialize(bytes.NewReader([]byte{0x08, 0x00, 0x00}))
+	require.ErrorAs(t, err, &versionErr)
+}
+
+// TestCloseTxVersionBit checks that backups carrying force close data, which
+// lnd flags in the high bit of the version, are parsed and the extra data is
+// skipped.
+func TestCloseTxVersionBit(t *testing.T) {
+	t.Parallel()
+
+	single := newTestSingle(t, AnchorsZeroFeeHtlcTxCommitVersion)
+	var b bytes.Buffer
+	require.NoError(t, single.Serialize(&b))
+
+	// Append some opaque close tx data to the body and flag it.
+	raw := append(b.Bytes(), 0xde, 0xad, 0xbe, 0xef)
+	raw[0] |= closeTxVersionMask
+	byteOrder.PutUint16(raw[1:3], byteOrder.Uint16(raw[1:3])+4)
+
+	var unpacked Single
+	require.NoError(t, unpacked.Deserialize(bytes.NewReader(raw)))
+	require.True(t, unpacked.HasCloseTx)
+	require.Equal(t, AnchorsZeroFeeHtlcTxCommitVersion, unpacked.Version)
+
+	unpacked.HasCloseTx = false
+	require.Equal(t, single, unpacked)
+}
+
+// TestUnknownAddressType checks that addresses of unknown types are kept as
+// opaque data.
+func TestUnknownAddressType(t *testing.T) {
+	t.Parallel()
+
+	raw, err := hex.DecodeString("000c" + "00" + "010a0000012607" +
+		"05aabbcc")
+	require.NoError(t, err)
+
+	addrs, err := readAddrs(bytes.NewReader(raw))
+	require.NoError(t, err)
+	require.Len(t, addrs, 2)
+	require.Equal(t, "10.0.0.1:9735", addrs[0].String())
+	require.Equal(t, "opaque:05aabbcc", addrs[1].String())
+}
@@ -0,0 +1,106 @@
+package chanbackup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// KeyRing is the source of the key that static channel backups are
+// encrypted with. It is satisfied by *wallet.Wallet.
+type KeyRing interface {
+	// DeriveLNDKey derives the key with the given index of an lnd key
+	// family.
+	DeriveLNDKey(family wallet.KeyFamily, index uint32) (*wallet.LNDKey,
+		error)
+}
+
+// genEncryptionKey derives the key used to encrypt and decrypt backups. As
+// done by lnd, this is the sha256 of the first public key of the static
+// backup key family.
+func genEncryptionKey(keyRing KeyRing) ([]byte, error) {
+	key, err := keyRing.DeriveLNDKey(wallet.KeyFamilyStaticBackup, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive backup encryption "+
+			"key: %w", err)
+	}
+
+	encryptionKey := sha256.Sum256(key.PubKey.SerializeCompressed())
+
+	return encryptionKey[:], nil
+}
+
+// encryptPayloadToWriter encrypts the payload with XChaCha20-Poly1305 and
+// writes the result to w, in the format lnd uses: a random 24 byte nonce,
+// which is also used as associated data, followed by the ciphertext.
+func encryptPayloadToWriter(payload bytes.Buffer, w io.Writer,
+	keyRing KeyRing) error {
+
+	encryptionKey, err := genEncryptionKey(keyRing)
+	if err != nil {
+		return err
+	}
+
+	var nonce [chacha20poly1305.NonceSizeX]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+
+	cipher, err := chacha20poly1305.NewX(encryptionKey)
+	if err != nil {
+		return err
+	}
+	ciphertext := cipher.Seal(nil, nonce[:], payload.Bytes(), nonce[:])
+
+	if _, err := w.Write(nonce[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(ciphertext); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// decryptPayloadFromReader reads an encrypted backup from r and decrypts it
+// with the key derived from the key ring. ErrWrongSeed is returned if the
+// backup doesn't authenticate, which almost always means it was made by
+// another seed.
+func decryptPayloadFromReader(payload io.Reader,
+	keyRing KeyRing) ([]byte, error) {
+
+	encryptionKey, err := genEncryptionKey(keyRing)
+	if err != nil {
+		return nil, err
+	}
+
+	packedBackup, err := io.ReadAll(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(packedBackup) < chacha20poly1305.NonceSizeX+
+		chacha20poly1305.Overhead {
+
+		return nil, ErrBackupTooShort
+	}
+
+	cipher, err := chacha20poly1305.NewX(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := packedBackup[:chacha20poly1305.NonceSizeX]
+	ciphertext := packedBackup[chacha20poly1305.NonceSizeX:]
+	plaintext, err := cipher.Open(nil, nonce, ciphertext, nonce)
+	if err != nil {
+		return nil, ErrWrongSeed
+	}
+
+	return plaintext, nil
+}
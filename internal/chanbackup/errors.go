@@ -0,0 +1,30 @@
+package chanbackup
+
+import "fmt"
+
+var (
+	// ErrBackupTooShort is returned if an encrypted backup is too short to
+	// even hold the nonce and the authentication tag.
+	ErrBackupTooShort = fmt.Errorf("encrypted backup is too short")
+
+	// ErrWrongSeed is returned if a backup can't be decrypted with the
+	// key derived from the seed. Either the backup belongs to another
+	// seed or it is corrupted.
+	ErrWrongSeed = fmt.Errorf("unable to decrypt backup: wrong seed or " +
+		"corrupted backup")
+)
+
+// ErrUnknownVersion is returned when a backup has a version this package
+// doesn't know how to parse.
+type ErrUnknownVersion struct {
+	// Kind is the kind of backup, either "single" or "multi".
+	Kind string
+
+	// Version is the unknown version.
+	Version byte
+}
+
+// Error returns a human-readable string describing the error.
+func (e ErrUnknownVersion) Error() string {
+	return fmt.Sprintf("unknown %s backup version %d", e.Kind, e.Version)
+}
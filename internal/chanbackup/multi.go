@@ -0,0 +1,84 @@
+package chanbackup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// MultiBackupVersion is the version of a multi channel backup.
+type MultiBackupVersion byte
+
+const (
+	// DefaultMultiVersion is the only multi backup version lnd writes.
+	DefaultMultiVersion MultiBackupVersion = 0
+)
+
+// Multi is a backup of all channels of a node, as found in lnd's
+// channel.backup file.
+type Multi struct {
+	// Version is the version of the multi backup.
+	Version MultiBackupVersion
+
+	// StaticBackups are the backups of the individual channels.
+	StaticBackups []Single
+}
+
+// PackToWriter serializes and encrypts the backup in the format of lnd's
+// channel.backup file: the version byte, a uint32 count and every single
+// backup, all encrypted as one payload.
+func (m *Multi) PackToWriter(w io.Writer, keyRing KeyRing) error {
+	if m.Version != DefaultMultiVersion {
+		return ErrUnknownVersion{Kind: "multi", Version: byte(m.Version)}
+	}
+
+	var plaintext bytes.Buffer
+	plaintext.WriteByte(byte(m.Version))
+	writeUint(&plaintext, uint32(len(m.StaticBackups)))
+	for i := range m.StaticBackups {
+		if err := m.StaticBackups[i].Serialize(&plaintext); err != nil {
+			return err
+		}
+	}
+
+	return encryptPayloadToWriter(plaintext, w, keyRing)
+}
+
+// UnpackFromReader decrypts and parses a backup packed by PackToWriter, such
+// as the contents of a channel.backup file.
+func (m *Multi) UnpackFromReader(r io.Reader, keyRing KeyRing) error {
+	plaintext, err := decryptPayloadFromReader(r, keyRing)
+	if err != nil {
+		return err
+	}
+	backupReader := bytes.NewReader(plaintext)
+
+	var version byte
+	if err := binary.Read(backupReader, byteOrder, &version); err != nil {
+		return err
+	}
+	if MultiBackupVersion(version) != DefaultMultiVersion {
+		return ErrUnknownVersion{Kind: "multi", Version: version}
+	}
+	m.Version = MultiBackupVersion(version)
+
+	var numBackups uint32
+	if err := binary.Read(backupReader, byteOrder, &numBackups); err != nil {
+		return err
+	}
+
+	// Don't trust the count for the allocation, every backup takes more
+	// than a byte anyway.
+	if int64(numBackups) > int64(backupReader.Len()) {
+		return io.ErrUnexpectedEOF
+	}
+	m.StaticBackups = make([]Single, numBackups)
+	for i := range m.StaticBackups {
+		err := m.StaticBackups[i].Deserialize(backupReader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,401 @@
+package chanbackup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// byteOrder is the byte order of all integers in a backup.
+var byteOrder = binary.BigEndian
+
+// SingleBackupVersion is the version of a single channel backup. It mirrors
+// the channel type, as each type needs slightly different data to be swept.
+type SingleBackupVersion byte
+
+const (
+	// DefaultSingleVersion is the version of legacy channels.
+	DefaultSingleVersion SingleBackupVersion = 0
+
+	// TweaklessCommitVersion is the version of channels with a static
+	// remote key.
+	TweaklessCommitVersion SingleBackupVersion = 1
+
+	// AnchorsCommitVersion is the version of anchor output channels.
+	AnchorsCommitVersion SingleBackupVersion = 2
+
+	// AnchorsZeroFeeHtlcTxCommitVersion is the version of anchor output
+	// channels with zero fee HTLC transactions.
+	AnchorsZeroFeeHtlcTxCommitVersion SingleBackupVersion = 3
+
+	// ScriptEnforcedLeaseVersion is the version of channels with a script
+	// enforced lease. Backups of this version carry the lease expiry.
+	ScriptEnforcedLeaseVersion SingleBackupVersion = 4
+
+	// SimpleTaprootVersion is the version of simple taproot channels.
+	SimpleTaprootVersion SingleBackupVersion = 5
+
+	// TapscriptRootVersion is the version of taproot channels with a
+	// tapscript root.
+	TapscriptRootVersion SingleBackupVersion = 6
+
+	// SimpleTaprootFinalVersion is the version of taproot channels using
+	// the final production scripts.
+	SimpleTaprootFinalVersion SingleBackupVersion = 7
+
+	// closeTxVersionMask is the bit lnd sets in the version byte if the
+	// backup carries the inputs of a force close transaction.
+	closeTxVersionMask = 1 << 7
+)
+
+// String returns a human-readable name of the backup version.
+func (v SingleBackupVersion) String() string {
+	switch v {
+	case DefaultSingleVersion:
+		return "legacy"
+	case TweaklessCommitVersion:
+		return "tweakless"
+	case AnchorsCommitVersion:
+		return "anchors"
+	case AnchorsZeroFeeHtlcTxCommitVersion:
+		return "anchors-zero-fee-htlc"
+	case ScriptEnforcedLeaseVersion:
+		return "script-enforced-lease"
+	case SimpleTaprootVersion:
+		return "simple-taproot"
+	case TapscriptRootVersion:
+		return "tapscript-root"
+	case SimpleTaprootFinalVersion:
+		return "simple-taproot-final"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(v))
+	}
+}
+
+// KeyLocator locates a key of our node within the lnd key families.
+type KeyLocator struct {
+	Family wallet.KeyFamily
+	Index  uint32
+}
+
+// LocalKeys are the locators of our channel keys. The keys themselves can be
+// re-derived from the seed.
+type LocalKeys struct {
+	MultiSig       KeyLocator
+	RevocationBase KeyLocator
+	PaymentBase    KeyLocator
+	DelayBase      KeyLocator
+	HtlcBase       KeyLocator
+}
+
+// RemoteKeys are the channel keys of the remote party.
+type RemoteKeys struct {
+	MultiSig       *btcec.PublicKey
+	RevocationBase *btcec.PublicKey
+	PaymentBase    *btcec.PublicKey
+	DelayBase      *btcec.PublicKey
+	HtlcBase       *btcec.PublicKey
+}
+
+// Single is the static backup of a single channel, as created by lnd. It
+// holds just enough to locate the channel and ask the remote party to force
+// close it.
+type Single struct {
+	// Version is the version of the backup.
+	Version SingleBackupVersion
+
+	// IsInitiator is true if we opened the channel.
+	IsInitiator bool
+
+	// ChainHash is the genesis hash of the chain the channel is on.
+	ChainHash chainhash.Hash
+
+	// FundingOutpoint is the outpoint of the funding transaction.
+	FundingOutpoint wire.OutPoint
+
+	// ShortChannelID is the location of the funding output in the chain,
+	// encoded as lnd's short channel ID.
+	ShortChannelID uint64
+
+	// RemoteNodePub is the identity key of the remote node.
+	RemoteNodePub *btcec.PublicKey
+
+	// Addresses are the last known addresses of the remote node.
+	Addresses []net.Addr
+
+	// Capacity is the size of the channel.
+	Capacity btcutil.Amount
+
+	// LocalCsvDelay and LocalKeys are our side of the channel config.
+	LocalCsvDelay uint16
+	LocalKeys     LocalKeys
+
+	// RemoteCsvDelay and RemoteKeys are the remote side of the channel
+	// config.
+	RemoteCsvDelay uint16
+	RemoteKeys     RemoteKeys
+
+	// ShaChainRootPub is the public key of the shachain root, if set, and
+	// ShaChainRoot its locator.
+	ShaChainRootPub *btcec.PublicKey
+	ShaChainRoot    KeyLocator
+
+	// LeaseExpiry is the height the channel lease expires at. It is only
+	// set for ScriptEnforcedLeaseVersion backups.
+	LeaseExpiry uint32
+
+	// HasCloseTx is set if the backup also carries the data to sign a
+	// force close transaction. That data itself isn't parsed.
+	HasCloseTx bool
+}
+
+// Serialize writes the plaintext backup to w: the version, the uint16 length
+// of the body and the body itself. Force close data isn't written, so
+// HasCloseTx is ignored.
+func (s *Single) Serialize(w io.Writer) error {
+	if s.Version > SimpleTaprootFinalVersion {
+		return ErrUnknownVersion{Kind: "single", Version: byte(s.Version)}
+	}
+
+	var body bytes.Buffer
+	var shaChainPub [33]byte
+	if s.ShaChainRootPub != nil {
+		copy(shaChainPub[:], s.ShaChainRootPub.SerializeCompressed())
+	}
+	if s.FundingOutpoint.Index > 0xffff {
+		return fmt.Errorf("funding output index %d too large",
+			s.FundingOutpoint.Index)
+	}
+
+	body.WriteByte(boolByte(s.IsInitiator))
+	body.Write(s.ChainHash[:])
+	body.Write(s.FundingOutpoint.Hash[:])
+	writeUint(&body, uint16(s.FundingOutpoint.Index))
+	writeUint(&body, s.ShortChannelID)
+	body.Write(s.RemoteNodePub.SerializeCompressed())
+	if err := writeAddrs(&body, s.Addresses); err != nil {
+		return err
+	}
+	writeUint(&body, uint64(s.Capacity))
+
+	writeUint(&body, s.LocalCsvDelay)
+	for _, loc := range s.LocalKeys.locators() {
+		writeUint(&body, uint32(loc.Family))
+		writeUint(&body, loc.Index)
+	}
+
+	writeUint(&body, s.RemoteCsvDelay)
+	for _, pub := range s.RemoteKeys.pubKeys() {
+		body.Write(pub.SerializeCompressed())
+	}
+
+	body.Write(shaChainPub[:])
+	writeUint(&body, uint32(s.ShaChainRoot.Family))
+	writeUint(&body, s.ShaChainRoot.Index)
+
+	if s.Version == ScriptEnforcedLeaseVersion {
+		writeUint(&body, s.LeaseExpiry)
+	}
+
+	if body.Len() > 0xffff {
+		return fmt.Errorf("single backup too long: %d bytes", body.Len())
+	}
+	if _, err := w.Write([]byte{byte(s.Version)}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, byteOrder, uint16(body.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+
+	return err
+}
+
+// Deserialize reads a plaintext backup written by Serialize or lnd. Data lnd
+// appends to the body that isn't needed here, such as the force close
+// transaction inputs, is skipped.
+func (s *Single) Deserialize(r io.Reader) error {
+	var header [3]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	s.Version = SingleBackupVersion(header[0] &^ closeTxVersionMask)
+	s.HasCloseTx = header[0]&closeTxVersionMask != 0
+	if s.Version > SimpleTaprootFinalVersion {
+		return ErrUnknownVersion{Kind: "single", Version: header[0]}
+	}
+
+	body := make([]byte, byteOrder.Uint16(header[1:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	br := bytes.NewReader(body)
+
+	var isInitiator byte
+	if err := readElements(br, &isInitiator, s.ChainHash[:],
+		s.FundingOutpoint.Hash[:]); err != nil {
+
+		return err
+	}
+	s.IsInitiator = isInitiator != 0
+
+	var outputIndex uint16
+	if err := readElements(br, &outputIndex, &s.ShortChannelID); err != nil {
+		return err
+	}
+	s.FundingOutpoint.Index = uint32(outputIndex)
+
+	var err error
+	if s.RemoteNodePub, err = readPubKey(br); err != nil {
+		return err
+	}
+	if s.Addresses, err = readAddrs(br); err != nil {
+		return err
+	}
+
+	var capacity uint64
+	if err := readElements(br, &capacity, &s.LocalCsvDelay); err != nil {
+		return err
+	}
+	s.Capacity = btcutil.Amount(capacity)
+
+	for _, loc := range s.LocalKeys.locators() {
+		if err := readLocator(br, loc); err != nil {
+			return err
+		}
+	}
+
+	if err := readElements(br, &s.RemoteCsvDelay); err != nil {
+		return err
+	}
+	remoteKeys := []**btcec.PublicKey{
+		&s.RemoteKeys.MultiSig, &s.RemoteKeys.RevocationBase,
+		&s.RemoteKeys.PaymentBase, &s.RemoteKeys.DelayBase,
+		&s.RemoteKeys.HtlcBase,
+	}
+	for _, pub := range remoteKeys {
+		if *pub, err = readPubKey(br); err != nil {
+			return err
+		}
+	}
+
+	var shaChainPub [33]byte
+	if err := readElements(br, shaChainPub[:]); err != nil {
+		return err
+	}
+	if shaChainPub != [33]byte{} {
+		s.ShaChainRootPub, err = btcec.ParsePubKey(shaChainPub[:])
+		if err != nil {
+			return err
+		}
+	}
+	if err := readLocator(br, &s.ShaChainRoot); err != nil {
+		return err
+	}
+
+	if s.Version == ScriptEnforcedLeaseVersion {
+		if err := readElements(br, &s.LeaseExpiry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PackToWriter serializes and encrypts the backup, the same way lnd exports
+// a single channel backup.
+func (s *Single) PackToWriter(w io.Writer, keyRing KeyRing) error {
+	var plaintext bytes.Buffer
+	if err := s.Serialize(&plaintext); err != nil {
+		return err
+	}
+
+	return encryptPayloadToWriter(plaintext, w, keyRing)
+}
+
+// UnpackFromReader decrypts and parses a backup packed by PackToWriter.
+func (s *Single) UnpackFromReader(r io.Reader, keyRing KeyRing) error {
+	plaintext, err := decryptPayloadFromReader(r, keyRing)
+	if err != nil {
+		return err
+	}
+
+	return s.Deserialize(bytes.NewReader(plaintext))
+}
+
+// locators returns pointers to the locators in serialization order.
+func (k *LocalKeys) locators() []*KeyLocator {
+	return []*KeyLocator{
+		&k.MultiSig, &k.RevocationBase, &k.PaymentBase, &k.DelayBase,
+		&k.HtlcBase,
+	}
+}
+
+// pubKeys returns the keys in serialization order.
+func (k *RemoteKeys) pubKeys() []*btcec.PublicKey {
+	return []*btcec.PublicKey{
+		k.MultiSig, k.RevocationBase, k.PaymentBase, k.DelayBase,
+		k.HtlcBase,
+	}
+}
+
+// writeUint writes a fixed size integer in the backup byte order. Writes to
+// a bytes.Buffer can't fail.
+func writeUint(b *bytes.Buffer, v interface{}) {
+	_ = binary.Write(b, byteOrder, v)
+}
+
+// readElements reads fixed size integers and raw byte slices in order.
+func readElements(r io.Reader, elements ...interface{}) error {
+	for _, element := range elements {
+		var err error
+		if raw, ok := element.([]byte); ok {
+			_, err = io.ReadFull(r, raw)
+		} else {
+			err = binary.Read(r, byteOrder, element)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readLocator reads a key locator.
+func readLocator(r io.Reader, loc *KeyLocator) error {
+	var family uint32
+	if err := readElements(r, &family, &loc.Index); err != nil {
+		return err
+	}
+	loc.Family = wallet.KeyFamily(family)
+
+	return nil
+}
+
+// readPubKey reads a compressed public key.
+func readPubKey(r io.Reader) (*btcec.PublicKey, error) {
+	var pub [33]byte
+	if _, err := io.ReadFull(r, pub[:]); err != nil {
+		return nil, err
+	}
+
+	return btcec.ParsePubKey(pub[:])
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,92 @@
+// Package config persists the user's settings between runs.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"aezeed_address_generator_gui/internal/fileutil"
+)
+
+const (
+	// dirName is the directory of the application in the user's
+	// configuration directory.
+	dirName = "aezeed-address-generator"
+
+	// fileName is the name of the settings file.
+	fileName = "settings.json"
+)
+
+// Proxy are the settings of the SOCKS5 proxy.
+type Proxy struct {
+	// Addr is the host:port of the proxy, empty if none is used.
+	Addr string `json:"addr,omitempty"`
+
+	// All routes every connection through the proxy, not only the ones
+	// to onion hosts.
+	All bool `json:"all,omitempty"`
+}
+
+// Settings are the persisted settings.
+type Settings struct {
+	// Network is the name of the selected network.
+	Network string `json:"network,omitempty"`
+
+	// Source is the ID of the selected blockchain source, or "offline".
+	Source string `json:"source,omitempty"`
+
+	// Sources holds the option values of the blockchain sources, by
+	// source ID and option key. Secrets such as passwords are never
+	// stored.
+	Sources map[string]map[string]string `json:"sources,omitempty"`
+
+	// Proxy is the SOCKS5 proxy used by the sources.
+	Proxy Proxy `json:"proxy"`
+
+	// SLIP132 shows the BIP49 and BIP84 account keys as ypub and zpub.
+	SLIP132 bool `json:"slip132,omitempty"`
+}
+
+// DefaultPath returns the path of the settings file in the user's
+// configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dirName, fileName), nil
+}
+
+// Load reads the settings file at path. A missing file yields empty settings.
+func Load(path string) (*Settings, error) {
+	settings := &Settings{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+
+	return settings, nil
+}
+
+// Save writes the settings to path, creating its directory if needed. The
+// file is replaced atomically, so a crash never leaves it half written.
+func (s *Settings) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fileutil.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
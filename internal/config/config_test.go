@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveLoad checks that saved settings are loaded back unchanged.
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sub", fileName)
+
+	// A missing file yields empty settings.
+	settings, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, &Settings{}, settings)
+
+	settings = &Settings{
+		Network: "testnet",
+		Source:  "blockstream",
+		Sources: map[string]map[string]string{
+			"blockstream": {"url": "http://esplora.onion/api"},
+		},
+		Proxy:   Proxy{Addr: "127.0.0.1:9050", All: true},
+		SLIP132: true,
+	}
+	require.NoError(t, settings.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, settings, loaded)
+
+	// Saving again replaces the file without leaving temporary files.
+	settings.Proxy = Proxy{}
+	require.NoError(t, settings.Save(path))
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	require.Equal(t, settings, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+// TestLoadInvalid checks that a corrupt file is reported.
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), fileName)
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "invalid settings file")
+}
@@ -14,9 +14,7 @@ var (
 // BenchmarkTomnemonic benchmarks the process of converting a cipher seed
 // (given the salt), to an enciphered mnemonic.
 func BenchmarkTomnemonic(b *testing.B) {
-	scryptN = 32768
-	scryptR = 8
-	scryptP = 1
+	setVersionParams(CipherSeedVersion, knownVersions[CipherSeedVersion])
 
 	pass := []byte("1234567890abcedfgh")
 	cipherSeed, err := New(0, nil, time.Now())
@@ -40,9 +38,7 @@ func BenchmarkTomnemonic(b *testing.B) {
 // BenchmarkToCipherSeed benchmarks the process of deciphering an existing
 // enciphered mnemonic.
 func BenchmarkToCipherSeed(b *testing.B) {
-	scryptN = 32768
-	scryptR = 8
-	scryptP = 1
+	setVersionParams(CipherSeedVersion, knownVersions[CipherSeedVersion])
 
 	pass := []byte("1234567890abcedfgh")
 	cipherSeed, err := New(0, nil, time.Now())
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"hash/crc32"
 	"io"
 	"time"
@@ -58,8 +59,8 @@ const (
 	EncipheredCipherSeedSize = 33
 
 	// CipherTextExpansion is the number of bytes that will be added as
-	// redundancy for the enciphering scheme implemented by aez. This can
-	// be seen as the size of the equivalent MAC.
+	// redundancy for the enciphering scheme implemented by aez in version
+	// zero. This can be seen as the size of the equivalent MAC.
 	CipherTextExpansion = 4
 
 	// EntropySize is the number of bytes of entropy we'll use to generate
@@ -73,14 +74,10 @@ const (
 	// SaltSize is the size of the salt we'll generate to use with scrypt
 	// to generate a key for use within aez from the user's passphrase. The
 	// role of the salt is to make the creation of rainbow tables
-	// infeasible.
+	// infeasible. This is the salt of version zero and the longest one a
+	// version can use.
 	SaltSize = 5
 
-	// adSize is the size of the encoded associated data that will be
-	// passed into aez when enciphering and deciphering the seed. The AD
-	// itself (associated data) is just the cipher seed version and salt.
-	adSize = 6
-
 	// checkSumSize is the size of the checksum applied to the final
 	// encoded ciphertext.
 	checkSumSize = 4
@@ -93,22 +90,12 @@ const (
 	// We encode our mnemonic using 24 words, so 264 bits (33 bytes).
 	BitsPerWord = 11
 
-	// saltOffset is the index within an enciphered cipher seed that marks
-	// the start of the salt.
-	saltOffset = EncipheredCipherSeedSize - checkSumSize - SaltSize
-
 	// checkSumSize is the index within an enciphered cipher seed that
 	// marks the start of the checksum.
 	checkSumOffset = EncipheredCipherSeedSize - checkSumSize
 )
 
 var (
-	// Below at the default scrypt parameters that are tied to cipher seed
-	// version zero.
-	scryptN = 32768
-	scryptR = 8
-	scryptP = 1
-
 	// crcTable is a table that presents the polynomial we'll use for
 	// computing our checksum.
 	crcTable = crc32.MakeTable(crc32.Castagnoli)
@@ -134,12 +121,16 @@ type SeedOptions struct {
 	// randomnessSource is the source of randomness that is used to generate
 	// the salt that is used for encrypting the seed.
 	randomnessSource io.Reader
+
+	// version is the external version the seed will be enciphered with.
+	version uint8
 }
 
 // DefaultOptions returns the default seed options.
 func DefaultOptions() *SeedOptions {
 	return &SeedOptions{
 		randomnessSource: rand.Reader,
+		version:          CipherSeedVersion,
 	}
 }
 
@@ -155,6 +146,15 @@ func WithRandomnessSource(src io.Reader) SeedOptionModifier {
 	}
 }
 
+// WithVersion returns an option modifier that enciphers the seed with the
+// given external version instead of CipherSeedVersion. The version must be
+// known or registered, see RegisterVersion.
+func WithVersion(version uint8) SeedOptionModifier {
+	return func(opts *SeedOptions) {
+		opts.version = version
+	}
+}
+
 // CipherSeed is a fully decoded instance of the aezeed scheme. At a high
 // level, the encoded cipher seed is the enciphering of: a version byte, a set
 // of bytes for a timestamp, the entropy which will be used to directly
@@ -195,8 +195,14 @@ type CipherSeed struct {
 	Entropy [EntropySize]byte
 
 	// salt is the salt that was used to generate the key from the user's
-	// specified passphrase.
+	// specified passphrase. Versions with shorter salts only use its
+	// leading bytes and leave the rest zero.
 	salt [SaltSize]byte
+
+	// version is the external version the seed is enciphered with, which
+	// governs how the passphrase is stretched into the key and how the
+	// seed is enciphered with it.
+	version uint8
 }
 
 // New generates a new CipherSeed instance from an optional source of entropy.
@@ -211,6 +217,13 @@ func New(internalVersion uint8, entropy *[EntropySize]byte,
 		modifier(opts)
 	}
 
+	// Make sure we know how to encipher the seed with the requested
+	// external version before doing anything else.
+	params, err := LookupVersion(opts.version)
+	if err != nil {
+		return nil, err
+	}
+
 	// If a set of entropy wasn't provided, then we'll read a set of bytes
 	// from the randomness source provided (which by default is the system's
 	// CSPRNG).
@@ -233,11 +246,13 @@ func New(internalVersion uint8, entropy *[EntropySize]byte,
 		InternalVersion: internalVersion,
 		Birthday:        birthday,
 		Entropy:         seed,
+		version:         opts.version,
 	}
 
-	// Next, we'll read a random salt that will be used with scrypt to
-	// eventually derive our key.
-	if _, err := opts.randomnessSource.Read(c.salt[:]); err != nil {
+	// Next, we'll read a random salt of the version's length that will be
+	// used with scrypt to eventually derive our key.
+	_, err = opts.randomnessSource.Read(c.salt[:params.SaltLen])
+	if err != nil {
 		return nil, err
 	}
 
@@ -283,25 +298,25 @@ func (c *CipherSeed) decode(r io.Reader) error {
 }
 
 // encodeAD returns the fully encoded associated data for use when performing
-// our current enciphering operation. The AD is: version || salt.
-func encodeAD(version uint8, salt [SaltSize]byte) [adSize]byte {
-	var ad [adSize]byte
-	ad[0] = version
-	copy(ad[1:], salt[:])
+// our current enciphering operation. The AD is: version || salt, where the
+// salt has the length of the version.
+func encodeAD(version uint8, salt []byte) []byte {
+	ad := make([]byte, 0, 1+len(salt))
+	ad = append(ad, version)
 
-	return ad
+	return append(ad, salt...)
 }
 
 // extractAD extracts an associated data from a fully encoded and enciphered
 // cipher seed. This is to be used when attempting to decrypt an enciphered
 // cipher seed.
-func extractAD(encipheredSeed [EncipheredCipherSeedSize]byte) [adSize]byte {
-	var ad [adSize]byte
-	ad[0] = encipheredSeed[0]
+func extractAD(encipheredSeed [EncipheredCipherSeedSize]byte,
+	params VersionParams) []byte {
 
-	copy(ad[1:], encipheredSeed[saltOffset:checkSumOffset])
-
-	return ad
+	return encodeAD(
+		encipheredSeed[0],
+		encipheredSeed[params.saltOffset():checkSumOffset],
+	)
 }
 
 // encipher takes a fully populated cipher seed instance, and enciphers the
@@ -311,6 +326,19 @@ func extractAD(encipheredSeed [EncipheredCipherSeedSize]byte) [adSize]byte {
 func (c *CipherSeed) encipher(pass []byte) ([EncipheredCipherSeedSize]byte,
 	error) {
 
+	params, err := LookupVersion(c.version)
+	if err != nil {
+		return [EncipheredCipherSeedSize]byte{}, err
+	}
+
+	return c.encipherWithParams(params, pass)
+}
+
+// encipherWithParams enciphers the seed with the given parameters of its
+// external version.
+func (c *CipherSeed) encipherWithParams(params VersionParams,
+	pass []byte) ([EncipheredCipherSeedSize]byte, error) {
+
 	var cipherSeedBytes [EncipheredCipherSeedSize]byte
 
 	// If the passphrase wasn't provided, then we'll use the string
@@ -322,8 +350,10 @@ func (c *CipherSeed) encipher(pass []byte) ([EncipheredCipherSeedSize]byte,
 
 	// With our salt pre-generated, we'll now run the password through a
 	// KDF to obtain the key we'll use for encryption.
+	salt := c.salt[:params.SaltLen]
 	key, err := scrypt.Key(
-		passphrase, c.salt[:], scryptN, scryptR, scryptP, keyLen,
+		passphrase, salt, params.ScryptN, params.ScryptR,
+		params.ScryptP, params.KeyLen,
 	)
 	if err != nil {
 		return cipherSeedBytes, err
@@ -339,20 +369,22 @@ func (c *CipherSeed) encipher(pass []byte) ([EncipheredCipherSeedSize]byte,
 	// With our plaintext seed encoded, we'll now construct the AD that
 	// will be passed to the encryption operation. This ensures to
 	// authenticate both the salt and the external version.
-	ad := encodeAD(CipherSeedVersion, c.salt)
+	ad := encodeAD(c.version, salt)
 
 	// With all items assembled, we'll now encipher the plaintext seed
 	// with our AD, key, and MAC size.
 	cipherSeed := seedBytes.Bytes()
 	cipherText := aez.Encrypt(
-		key, nil, [][]byte{ad[:]}, CipherTextExpansion, cipherSeed, nil,
+		key, nil, [][]byte{ad}, params.CipherTextExpansion, cipherSeed,
+		nil,
 	)
 
 	// Finally, we'll pack the {version || ciphertext || salt || checksum}
 	// seed into a byte slice for encoding as a mnemonic.
-	cipherSeedBytes[0] = CipherSeedVersion
+	saltOffset := params.saltOffset()
+	cipherSeedBytes[0] = c.version
 	copy(cipherSeedBytes[1:saltOffset], cipherText)
-	copy(cipherSeedBytes[saltOffset:], c.salt[:])
+	copy(cipherSeedBytes[saltOffset:checkSumOffset], salt)
 
 	// With the seed mostly assembled, we'll now compute a checksum all the
 	// contents.
@@ -416,6 +448,11 @@ func (c *CipherSeed) Encipher(pass []byte) ([EncipheredCipherSeedSize]byte,
 	return c.encipher(pass)
 }
 
+// Version returns the external version the seed is enciphered with.
+func (c *CipherSeed) Version() uint8 {
+	return c.version
+}
+
 // BirthdayTime returns the cipher seed's internal birthday format as a native
 // golang Time struct.
 func (c *CipherSeed) BirthdayTime() time.Time {
@@ -423,6 +460,20 @@ func (c *CipherSeed) BirthdayTime() time.Time {
 	return BitcoinGenesisDate.Add(offset)
 }
 
+// RescanTime returns the time a rescan for the funds of the seed should start
+// from. Birthdays are stored as days that begin at the genesis block's time of
+// day, around 18:15 UTC, so funds received earlier on the calendar date of the
+// birthday precede BirthdayTime. The rescan therefore starts one day earlier,
+// but never before the genesis block.
+func (c *CipherSeed) RescanTime() time.Time {
+	if c.Birthday == 0 {
+		return BitcoinGenesisDate
+	}
+
+	offset := time.Duration(c.Birthday-1) * 24 * time.Hour
+	return BitcoinGenesisDate.Add(offset)
+}
+
 // Mnemonic is a 24-word passphrase as of cipher seed version zero. This
 // passphrase encodes an encrypted seed triple (version, birthday, entropy).
 // Additionally, we also encode the salt used with scrypt to derive the key
@@ -470,9 +521,11 @@ func (m *Mnemonic) ToCipherSeed(pass []byte) (*CipherSeed, error) {
 	}
 
 	// If decryption was successful, then we'll decode into a fresh
-	// CipherSeed struct.
+	// CipherSeed struct, which keeps the external version so it can be
+	// enciphered again the same way.
 	c := CipherSeed{
-		salt: salt,
+		salt:    salt,
+		version: mnemonicToCipherText(m)[0],
 	}
 	if err := c.decode(bytes.NewReader(plainSeed[:])); err != nil {
 		return nil, err
@@ -495,14 +548,31 @@ func decipherCipherSeed(cipherSeedBytes [EncipheredCipherSeedSize]byte,
 	// Before we do anything, we'll ensure that the version is one that we
 	// understand. Otherwise, we won't be able to decrypt, or even parse
 	// the cipher seed.
-	if cipherSeedBytes[0] != CipherSeedVersion {
-		return plainSeed, salt, ErrIncorrectVersion
+	params, err := LookupVersion(cipherSeedBytes[0])
+	if err != nil {
+		return plainSeed, salt, err
 	}
 
+	return decipherWithParams(cipherSeedBytes, params, pass)
+}
+
+// decipherWithParams deciphers the cipher seed with the given parameters of
+// its external version.
+func decipherWithParams(cipherSeedBytes [EncipheredCipherSeedSize]byte,
+	params VersionParams, pass []byte) ([DecipheredCipherSeedSize]byte,
+	[SaltSize]byte, error) {
+
+	var (
+		plainSeed [DecipheredCipherSeedSize]byte
+		salt      [SaltSize]byte
+	)
+
 	// Next, we'll slice off the salt from the pass cipher seed, then
 	// snip off the end of the cipher seed, ignoring the version, and
-	// finally the checksum.
-	copy(salt[:], cipherSeedBytes[saltOffset:saltOffset+SaltSize])
+	// finally the checksum. Where the salt ends and the cipher text
+	// begins depends on the version.
+	saltOffset := params.saltOffset()
+	copy(salt[:], cipherSeedBytes[saltOffset:checkSumOffset])
 	cipherSeed := cipherSeedBytes[1:saltOffset]
 	checksum := cipherSeedBytes[checkSumOffset:]
 
@@ -517,20 +587,24 @@ func decipherCipherSeed(cipherSeedBytes [EncipheredCipherSeedSize]byte,
 
 	// With the salt separated from the cipher text, we'll now obtain the
 	// key used for encryption.
-	key, err := scrypt.Key(pass, salt[:], scryptN, scryptR, scryptP, keyLen)
+	key, err := scrypt.Key(
+		pass, salt[:params.SaltLen], params.ScryptN, params.ScryptR,
+		params.ScryptP, params.KeyLen,
+	)
 	if err != nil {
 		return plainSeed, salt, err
 	}
 
 	// We'll also extract the AD that will be required to properly pass the
 	// MAC check.
-	ad := extractAD(cipherSeedBytes)
+	ad := extractAD(cipherSeedBytes, params)
 
 	// With the key, we'll attempt to decrypt the plaintext. If the
 	// ciphertext was altered, or the passphrase is incorrect, then we'll
 	// error out.
 	plainSeedBytes, ok := aez.Decrypt(
-		key, nil, [][]byte{ad[:]}, CipherTextExpansion, cipherSeed, nil,
+		key, nil, [][]byte{ad}, params.CipherTextExpansion, cipherSeed,
+		nil,
 	)
 	if !ok {
 		return plainSeed, salt, ErrInvalidPass
@@ -583,6 +657,23 @@ func (m *Mnemonic) Decipher(pass []byte) ([DecipheredCipherSeedSize]byte,
 	return decipherCipherSeed(cipherText, passphrase)
 }
 
+// ChecksumValid reports whether all words of the mnemonic are in the word list
+// and the checksum of the cipher text they encode matches. This is the check
+// Decipher does before the expensive key derivation, so it allows candidate
+// mnemonics to be filtered cheaply.
+func (m *Mnemonic) ChecksumValid() bool {
+	for _, word := range m {
+		if _, ok := ReverseWordMap[word]; !ok {
+			return false
+		}
+	}
+
+	cipherText := mnemonicToCipherText(m)
+	checksum := crc32.Checksum(cipherText[:checkSumOffset], crcTable)
+
+	return checksum == binary.BigEndian.Uint32(cipherText[checkSumOffset:])
+}
+
 // ChangePass takes an existing mnemonic, and passphrase for said mnemonic and
 // re-enciphers the plaintext cipher seed into a brand-new mnemonic. This can
 // be used to allow users to re-encrypt the same seed with multiple pass
@@ -601,3 +692,48 @@ func (m *Mnemonic) ChangePass(oldPass, newPass []byte) (Mnemonic, error) {
 	// the new user provided passphrase.
 	return cipherSeed.ToMnemonic(newPass)
 }
+
+// ChangePassVerified is ChangePass for callers that must not lose the seed.
+// The new mnemonic is deciphered again with newPass and must hold the same
+// entropy, birthday, internal and external version as the original, otherwise
+// ErrPassChangeMismatch is returned. The seed deciphered from the new mnemonic
+// is returned along with it.
+func (m *Mnemonic) ChangePassVerified(oldPass, newPass []byte) (Mnemonic,
+	*CipherSeed, error) {
+
+	original, err := m.ToCipherSeed(oldPass)
+	if err != nil {
+		return Mnemonic{}, nil, err
+	}
+
+	// Re-encipher the seed we already deciphered, instead of going through
+	// ChangePass, which would decipher it a second time.
+	newMnemonic, err := original.ToMnemonic(newPass)
+	if err != nil {
+		return Mnemonic{}, nil, err
+	}
+
+	seed, err := newMnemonic.ToCipherSeed(newPass)
+	if err != nil {
+		return Mnemonic{}, nil, fmt.Errorf("%w: %v", ErrPassChangeMismatch,
+			err)
+	}
+
+	var mismatch string
+	switch {
+	case seed.Entropy != original.Entropy:
+		mismatch = "entropy"
+	case seed.Birthday != original.Birthday:
+		mismatch = "birthday"
+	case seed.InternalVersion != original.InternalVersion:
+		mismatch = "internal version"
+	case seed.version != original.version:
+		mismatch = "external version"
+	}
+	if mismatch != "" {
+		return Mnemonic{}, nil, fmt.Errorf("%w: the %s differs",
+			ErrPassChangeMismatch, mismatch)
+	}
+
+	return newMnemonic, seed, nil
+}
@@ -7,7 +7,9 @@ import "github.com/btcsuite/btcwallet/waddrmgr"
 func init() {
 	// For the purposes of our itest, we'll crank down the scrypt params a
 	// bit.
-	scryptN = waddrmgr.FastScryptOptions.N
-	scryptR = waddrmgr.FastScryptOptions.R
-	scryptP = waddrmgr.FastScryptOptions.P
+	params := knownVersions[CipherSeedVersion]
+	params.ScryptN = waddrmgr.FastScryptOptions.N
+	params.ScryptR = waddrmgr.FastScryptOptions.R
+	params.ScryptP = waddrmgr.FastScryptOptions.P
+	setVersionParams(CipherSeedVersion, params)
 }
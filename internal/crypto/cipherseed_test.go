@@ -331,6 +331,90 @@ func TestChangePassphraseWrongPass(t *testing.T) {
 	require.Equal(t, ErrInvalidPass, err)
 }
 
+// TestChangePassVerified checks that a verified passphrase change yields a
+// mnemonic of the same seed under the new passphrase, with the default
+// passphrase standing in for an empty one on either side.
+func TestChangePassVerified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		pass         []byte
+		oldPass      []byte
+		newPass      []byte
+		err          error
+		sameMnemonic bool
+	}{{
+		name:    "change",
+		pass:    []byte("test"),
+		oldPass: []byte("test"),
+		newPass: []byte("strongerpassyeh!"),
+	}, {
+		name:    "wrong old passphrase",
+		pass:    []byte("test"),
+		oldPass: []byte("kek"),
+		newPass: []byte("strongerpassyeh!"),
+		err:     ErrInvalidPass,
+	}, {
+		name:         "same passphrase",
+		pass:         []byte("test"),
+		oldPass:      []byte("test"),
+		newPass:      []byte("test"),
+		sameMnemonic: true,
+	}, {
+		name:    "from empty default",
+		pass:    nil,
+		oldPass: []byte{},
+		newPass: []byte("test"),
+	}, {
+		name:    "from explicit default",
+		pass:    nil,
+		oldPass: defaultPassphrase,
+		newPass: []byte("test"),
+	}, {
+		name:    "to default",
+		pass:    []byte("test"),
+		oldPass: []byte("test"),
+		newPass: nil,
+	}, {
+		name:         "empty to explicit default",
+		pass:         nil,
+		oldPass:      nil,
+		newPass:      defaultPassphrase,
+		sameMnemonic: true,
+	}}
+	for _, test := range tests {
+		cipherSeed, err := New(0, &testEntropy, time.Now())
+		require.NoError(t, err)
+		mnemonic, err := cipherSeed.ToMnemonic(test.pass)
+		require.NoError(t, err)
+
+		newMnemonic, seed, err := mnemonic.ChangePassVerified(
+			test.oldPass, test.newPass,
+		)
+		if test.err != nil {
+			require.ErrorIs(t, err, test.err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assertCipherSeedEqual(t, cipherSeed, seed)
+
+		// The salt is kept, so the mnemonic only changes along with the
+		// passphrase.
+		require.Equal(t, test.sameMnemonic, newMnemonic == mnemonic,
+			test.name)
+
+		decoded, err := newMnemonic.ToCipherSeed(test.newPass)
+		require.NoError(t, err, test.name)
+		assertCipherSeedEqual(t, cipherSeed, decoded)
+
+		if !test.sameMnemonic {
+			_, err = newMnemonic.ToCipherSeed(test.oldPass)
+			require.ErrorIs(t, err, ErrInvalidPass, test.name)
+		}
+	}
+}
+
 // TestMnemonicEncoding uses quickcheck like property based testing to ensure
 // that we're always able to fully recover the original byte stream encoded
 // into the mnemonic phrase.
@@ -552,12 +636,72 @@ func TestDecipherIncorrectMnemonic(t *testing.T) {
 	require.Equal(t, ErrIncorrectMnemonic, err)
 }
 
+// TestChecksumValid tests that the cheap checksum check agrees with the
+// deciphering.
+func TestChecksumValid(t *testing.T) {
+	t.Parallel()
+
+	cipherSeed, err := New(0, &testEntropy, time.Now())
+	require.NoError(t, err)
+	mnemonic, err := cipherSeed.ToMnemonic(nil)
+	require.NoError(t, err)
+	require.True(t, mnemonic.ChecksumValid())
+
+	// Swapping two distinct words breaks the checksum. The first word
+	// holds the version, so it is left alone.
+	swapped := mnemonic
+	for i := 10; i < NumMnemonicWords; i++ {
+		if swapped[9] != swapped[i] {
+			swapped[9], swapped[i] = swapped[i], swapped[9]
+			break
+		}
+	}
+	require.False(t, swapped.ChecksumValid())
+	_, err = swapped.ToCipherSeed(nil)
+	require.Equal(t, ErrIncorrectMnemonic, err)
+
+	unknown := mnemonic
+	unknown[5] = "notaword"
+	require.False(t, unknown.ChecksumValid())
+}
+
 // TODO(roasbeef): add test failure checksum fail is modified, new error
 
 func init() {
 	// For the purposes of our test, we'll crank down the scrypt params a
 	// bit.
-	scryptN = 16
-	scryptR = 8
-	scryptP = 1
+	params := knownVersions[CipherSeedVersion]
+	params.ScryptN = 16
+	setVersionParams(CipherSeedVersion, params)
+}
+
+// TestRescanTime checks that a rescan from the rescan time of a seed finds
+// funds received early on the calendar date of its birthday, which precede
+// BirthdayTime.
+func TestRescanTime(t *testing.T) {
+	t.Parallel()
+
+	// A birthday entered as a date is created at the last second of that
+	// date, so that it is stored as that day.
+	date := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	cipherSeed, err := New(
+		0, &testEntropy, date.Add(24*time.Hour-time.Second),
+	)
+	require.NoError(t, err)
+	require.Equal(t, date, cipherSeed.BirthdayTime().UTC().Truncate(
+		24*time.Hour,
+	))
+
+	for _, received := range []time.Time{
+		date, date.Add(9 * time.Hour), date.Add(23 * time.Hour),
+	} {
+		require.False(t, cipherSeed.RescanTime().After(received),
+			received)
+	}
+	require.True(t, cipherSeed.BirthdayTime().After(date.Add(9*time.Hour)))
+
+	// The rescan never starts before the genesis block.
+	cipherSeed, err = New(0, &testEntropy, BitcoinGenesisDate)
+	require.NoError(t, err)
+	require.Equal(t, BitcoinGenesisDate, cipherSeed.RescanTime())
 }
@@ -16,6 +16,11 @@ var (
 	// the wrong mnemonic.
 	ErrIncorrectMnemonic = fmt.Errorf("mnemonic phrase checksum doesn't " +
 		"match")
+
+	// ErrPassChangeMismatch is returned if a mnemonic re-enciphered under
+	// a new passphrase doesn't decipher to the seed of the original one.
+	ErrPassChangeMismatch = fmt.Errorf("re-enciphered mnemonic doesn't " +
+		"match the original seed")
 )
 
 // ErrUnknownMnemonicWord is returned when attempting to decipher and
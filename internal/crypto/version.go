@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VersionParams are the parameters an external cipher seed version enciphers
+// the plaintext seed with: how the passphrase is stretched into the key, and
+// how the aez cipher text and the salt share the enciphered seed. Every version
+// keeps its layout (version || ciphertext || salt || checksum) at
+// EncipheredCipherSeedSize bytes, so that it always fits into 24 words; a
+// version can only trade salt bytes for cipher text expansion.
+type VersionParams struct {
+	// ScryptN, ScryptR and ScryptP are the cost parameters of the scrypt
+	// key derivation: the CPU/memory cost, a power of two, the block size
+	// and the parallelization.
+	ScryptN int
+	ScryptR int
+	ScryptP int
+
+	// KeyLen is the size of the aez key derived from the passphrase.
+	KeyLen int
+
+	// CipherTextExpansion is the number of bytes aez adds to the
+	// plaintext seed, the size of the equivalent MAC.
+	CipherTextExpansion int
+
+	// SaltLen is the size of the scrypt salt, at most SaltSize.
+	SaltLen int
+}
+
+// saltOffset returns the index within an enciphered cipher seed that marks
+// the start of the salt.
+func (p VersionParams) saltOffset() int {
+	return checkSumOffset - p.SaltLen
+}
+
+// validate checks that the parameters can derive a key.
+func (p VersionParams) validate() error {
+	switch {
+	case p.ScryptN <= 1 || p.ScryptN&(p.ScryptN-1) != 0:
+		return fmt.Errorf("scrypt N must be a power of two greater "+
+			"than 1, got %d", p.ScryptN)
+
+	case p.ScryptR <= 0 || p.ScryptP <= 0:
+		return fmt.Errorf("scrypt r and p must be positive, got r=%d "+
+			"p=%d", p.ScryptR, p.ScryptP)
+
+	case p.KeyLen <= 0:
+		return fmt.Errorf("key length must be positive, got %d",
+			p.KeyLen)
+
+	case p.SaltLen <= 0 || p.SaltLen > SaltSize:
+		return fmt.Errorf("salt length must be between 1 and %d, got %d",
+			SaltSize, p.SaltLen)
+
+	case 1+DecipheredCipherSeedSize+p.CipherTextExpansion+p.SaltLen !=
+		checkSumOffset:
+
+		return fmt.Errorf("cipher text expansion %d and salt length %d "+
+			"don't fill the %d bytes of an enciphered seed",
+			p.CipherTextExpansion, p.SaltLen, EncipheredCipherSeedSize)
+	}
+
+	return nil
+}
+
+// knownVersions are the external versions of the aezeed scheme as defined by
+// lnd, along with their parameters.
+var knownVersions = map[uint8]VersionParams{
+	// Version zero derives a 32-byte key with scrypt(n=32768, r=8, p=1)
+	// and a 5-byte salt, and expands the seed by 4 bytes.
+	CipherSeedVersion: {
+		ScryptN:             32768,
+		ScryptR:             8,
+		ScryptP:             1,
+		KeyLen:              keyLen,
+		CipherTextExpansion: CipherTextExpansion,
+		SaltLen:             SaltSize,
+	},
+}
+
+var (
+	// versionsMtx guards versions.
+	versionsMtx sync.RWMutex
+
+	// versions are the external versions mnemonics can be enciphered and
+	// deciphered with: the known versions and the registered ones.
+	versions = func() map[uint8]VersionParams {
+		registry := make(map[uint8]VersionParams, len(knownVersions))
+		for version, params := range knownVersions {
+			registry[version] = params
+		}
+
+		return registry
+	}()
+)
+
+// RegisterVersion adds an external cipher seed version, so mnemonics of a
+// newer version of the scheme can be deciphered, and seeds enciphered with it,
+// without changing this package. A version can only be registered once.
+func RegisterVersion(version uint8, params VersionParams) error {
+	if err := params.validate(); err != nil {
+		return fmt.Errorf("invalid parameters for cipher seed version "+
+			"%d: %w", version, err)
+	}
+
+	versionsMtx.Lock()
+	defer versionsMtx.Unlock()
+
+	if _, ok := versions[version]; ok {
+		return fmt.Errorf("cipher seed version %d is already registered",
+			version)
+	}
+	versions[version] = params
+
+	return nil
+}
+
+// LookupVersion returns the parameters of an external cipher seed version.
+// ErrIncorrectVersion is returned if the version isn't known or registered.
+func LookupVersion(version uint8) (VersionParams, error) {
+	versionsMtx.RLock()
+	defer versionsMtx.RUnlock()
+
+	params, ok := versions[version]
+	if !ok {
+		return VersionParams{}, ErrIncorrectVersion
+	}
+
+	return params, nil
+}
+
+// setVersionParams replaces the parameters of a version. It allows tests to
+// crank down the scrypt costs.
+func setVersionParams(version uint8, params VersionParams) {
+	versionsMtx.Lock()
+	defer versionsMtx.Unlock()
+
+	versions[version] = params
+}
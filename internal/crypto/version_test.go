@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testVersion is an external version registered by the tests, with scrypt
+// costs low enough to be fast, a longer key than version zero, and a byte of
+// salt traded for a longer cipher text expansion.
+const testVersion = 200
+
+// testVersionParams are the parameters of testVersion.
+var testVersionParams = VersionParams{
+	ScryptN:             16,
+	ScryptR:             8,
+	ScryptP:             1,
+	KeyLen:              48,
+	CipherTextExpansion: CipherTextExpansion + 1,
+	SaltLen:             SaltSize - 1,
+}
+
+// knownVersionVectors are the test vectors of each known version, along with
+// their mnemonics when enciphered with the version's real parameters instead
+// of the cranked down ones of the other tests. The mnemonics of version zero
+// pin down that the registry derives the same mnemonics as the scheme always
+// did.
+var knownVersionVectors = map[uint8]struct {
+	vectors   []TestVector
+	mnemonics [][NumMnemonicWords]string
+}{
+	CipherSeedVersion: {
+		vectors: version0TestVectors,
+		mnemonics: [][NumMnemonicWords]string{{
+			"above", "judge", "emerge", "veteran", "reform",
+			"crunch", "system", "all", "snap", "please", "shoulder",
+			"vault", "hurt", "city", "quarter", "cover", "enlist",
+			"swear", "success", "suggest", "drink", "wagon",
+			"enrich", "body",
+		}, {
+			"absorb", "century", "submit", "father", "path", "glove",
+			"gloom", "super", "divert", "garden", "ice", "mirror",
+			"wisdom", "grass", "dice", "kit", "ugly", "castle",
+			"success", "suggest", "drink", "monster", "congress",
+			"flight",
+		}},
+	},
+}
+
+func init() {
+	if err := RegisterVersion(testVersion, testVersionParams); err != nil {
+		panic(err)
+	}
+}
+
+// TestKnownVersionVectors checks every known version against its test
+// vectors, enciphering and deciphering with the version's real parameters.
+func TestKnownVersionVectors(t *testing.T) {
+	t.Parallel()
+
+	for version, params := range knownVersions {
+		known := knownVersionVectors[version]
+		require.NotEmpty(t, known.vectors, "no test vectors for version "+
+			"%d", version)
+		require.Len(t, known.mnemonics, len(known.vectors))
+
+		for i, v := range known.vectors {
+			cipherSeed, err := New(
+				v.version, &v.entropy, v.time, WithVersion(version),
+			)
+			require.NoError(t, err)
+			cipherSeed.salt = v.salt
+
+			cipherText, err := cipherSeed.encipherWithParams(
+				params, v.password,
+			)
+			require.NoError(t, err)
+			require.Equal(t, version, cipherText[0])
+
+			mnemonic, err := cipherTextToMnemonic(cipherText)
+			require.NoError(t, err)
+			require.Equal(t, known.mnemonics[i], [NumMnemonicWords]string(
+				mnemonic,
+			))
+			require.Equal(t, v.expectedBirthday, cipherSeed.Birthday)
+
+			pass := v.password
+			if len(pass) == 0 {
+				pass = defaultPassphrase
+			}
+			plainSeed, salt, err := decipherWithParams(
+				cipherText, params, pass,
+			)
+			require.NoError(t, err)
+			require.Equal(t, v.salt, salt)
+
+			var seedBytes [DecipheredCipherSeedSize]byte
+			seedBytes[0] = v.version
+			seedBytes[1] = byte(v.expectedBirthday >> 8)
+			seedBytes[2] = byte(v.expectedBirthday)
+			copy(seedBytes[3:], v.entropy[:])
+			require.Equal(t, seedBytes, plainSeed)
+		}
+	}
+}
+
+// TestRegisteredVersion checks that a registered version enciphers and
+// deciphers seeds with its own parameters, including its shorter salt, and
+// keeps its version when the passphrase is changed.
+func TestRegisteredVersion(t *testing.T) {
+	t.Parallel()
+
+	cipherSeed, err := New(
+		0, &testEntropy, time.Now(), WithVersion(testVersion),
+	)
+	require.NoError(t, err)
+	require.Equal(t, uint8(testVersion), cipherSeed.Version())
+	require.Zero(t, cipherSeed.salt[testVersionParams.SaltLen])
+
+	pass := []byte("test")
+	cipherText, err := cipherSeed.Encipher(pass)
+	require.NoError(t, err)
+	require.Equal(t, uint8(testVersion), cipherText[0])
+
+	// The ciphertext only deciphers with the parameters of its version.
+	_, _, err = decipherWithParams(
+		cipherText, knownVersions[CipherSeedVersion], pass,
+	)
+	require.ErrorIs(t, err, ErrInvalidPass)
+
+	mnemonic, err := cipherTextToMnemonic(cipherText)
+	require.NoError(t, err)
+	decoded, err := mnemonic.ToCipherSeed(pass)
+	require.NoError(t, err)
+	assertCipherSeedEqual(t, cipherSeed, decoded)
+	require.Equal(t, uint8(testVersion), decoded.Version())
+
+	newMnemonic, err := mnemonic.ChangePass(pass, []byte("new"))
+	require.NoError(t, err)
+	newCipherText := mnemonicToCipherText(&newMnemonic)
+	require.Equal(t, uint8(testVersion), newCipherText[0])
+}
+
+// TestRegisterVersion checks that versions can't be registered twice or with
+// invalid parameters, and that unknown versions are rejected.
+func TestRegisterVersion(t *testing.T) {
+	t.Parallel()
+
+	valid := testVersionParams
+	require.Error(t, RegisterVersion(CipherSeedVersion, valid))
+	require.Error(t, RegisterVersion(testVersion, valid))
+
+	for _, modify := range []func(p *VersionParams){
+		func(p *VersionParams) { p.ScryptN = 15 },
+		func(p *VersionParams) { p.ScryptN = 1 },
+		func(p *VersionParams) { p.ScryptR = 0 },
+		func(p *VersionParams) { p.KeyLen = 0 },
+		func(p *VersionParams) { p.SaltLen = 0 },
+		func(p *VersionParams) { p.SaltLen, p.CipherTextExpansion = 6, 3 },
+		func(p *VersionParams) { p.CipherTextExpansion++ },
+	} {
+		params := valid
+		modify(&params)
+		require.Error(t, RegisterVersion(testVersion+1, params), params)
+	}
+
+	_, err := LookupVersion(testVersion + 1)
+	require.Equal(t, ErrIncorrectVersion, err)
+
+	_, err = New(0, &testEntropy, time.Now(), WithVersion(testVersion+1))
+	require.Equal(t, ErrIncorrectVersion, err)
+}
@@ -0,0 +1,107 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// inputCharset is the character set of descriptors. The position of a
+	// character determines its contribution to the checksum.
+	inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the bech32 character set the checksum is written
+	// in.
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksumLength is the number of characters of a checksum.
+	checksumLength = 8
+)
+
+// generator holds the coefficients of the BCH code of descriptor checksums.
+var generator = [5]uint64{
+	0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd,
+}
+
+// polyMod feeds one 5-bit value into the checksum state c.
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	for i, g := range generator {
+		if (c0>>i)&1 == 1 {
+			c ^= g
+		}
+	}
+
+	return c
+}
+
+// Checksum computes the BIP380 checksum of a descriptor without its
+// checksum.
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(inputCharset, ch)
+		if pos == -1 {
+			return "", fmt.Errorf("invalid character %q in descriptor",
+				ch)
+		}
+
+		// The low 5 bits of the position are fed directly, the high
+		// bits are grouped by three.
+		c = polyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			c = polyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	for i := 0; i < checksumLength; i++ {
+		c = polyMod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, checksumLength)
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>(5*(7-i)))&31]
+	}
+
+	return string(checksum), nil
+}
+
+// AddChecksum appends the checksum to a descriptor.
+func AddChecksum(desc string) (string, error) {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+
+	return desc + "#" + checksum, nil
+}
+
+// VerifyChecksum checks the checksum of a descriptor in the form
+// desc#checksum.
+func VerifyChecksum(desc string) error {
+	i := strings.LastIndexByte(desc, '#')
+	if i == -1 {
+		return fmt.Errorf("descriptor has no checksum")
+	}
+
+	want, err := Checksum(desc[:i])
+	if err != nil {
+		return err
+	}
+	if desc[i+1:] != want {
+		return fmt.Errorf("invalid descriptor checksum %q, expected %q",
+			desc[i+1:], want)
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package descriptor
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// DefaultRange is the index range of ranged descriptors imported into
+// Bitcoin Core, matching its default keypool size.
+var DefaultRange = [2]uint32{0, 999}
+
+// scriptFunctions are the script expressions of the supported purposes,
+// written around a key expression.
+var scriptFunctions = []struct {
+	purpose        uint32
+	prefix, suffix string
+}{
+	{wallet.BIP44Purpose, "pkh(", ")"},
+	{wallet.BIP49Purpose, "sh(wpkh(", "))"},
+	{wallet.BIP84Purpose, "wpkh(", ")"},
+	{wallet.BIP86Purpose, "tr(", ")"},
+}
+
+// wrap returns the script expression of a purpose around a key expression.
+func wrap(purpose uint32, key string) (string, error) {
+	for _, f := range scriptFunctions {
+		if f.purpose == purpose {
+			return f.prefix + key + f.suffix, nil
+		}
+	}
+
+	return "", wallet.ErrUnknownPurpose{Purpose: purpose}
+}
+
+// unwrap returns the purpose and key expression of a script expression.
+func unwrap(desc string) (uint32, string, error) {
+	for _, f := range scriptFunctions {
+		if strings.HasPrefix(desc, f.prefix) &&
+			strings.HasSuffix(desc, f.suffix) {
+
+			key := desc[len(f.prefix) : len(desc)-len(f.suffix)]
+			return f.purpose, key, nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("unsupported descriptor, use pkh, sh(wpkh), " +
+		"wpkh or tr")
+}
+
+// Account holds the ranged descriptors of the receive and change chains of
+// an account.
+type Account struct {
+	// Purpose is the BIP purpose of the account.
+	Purpose uint32
+
+	// Account is the account number.
+	Account uint32
+
+	// Receive and Change are the descriptors with checksums of the
+	// external and internal chains.
+	Receive string
+	Change  string
+}
+
+// ForAccount returns the descriptors of an account of a wallet, such as
+// wpkh([fingerprint/84'/0'/0']xpub.../0/*)#checksum. The key origin lets
+// signers match the keys to the seed.
+func ForAccount(w *wallet.Wallet, purpose, account uint32) (*Account, error) {
+	xpub, err := w.AccountXpub(purpose, account)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account path without its leading m is the origin of the key.
+	// Watch-only wallets may not know the master fingerprint, their
+	// descriptors are left without origin then.
+	fingerprint, err := w.MasterFingerprint()
+	var origin string
+	switch {
+	case errors.Is(err, wallet.ErrWatchOnly):
+	case err != nil:
+		return nil, err
+	default:
+		origin = "[" + fingerprint + w.AccountPath(purpose, account)[1:] +
+			"]"
+	}
+
+	chainDescriptor := func(chain uint32) (string, error) {
+		key := fmt.Sprintf("%s%s/%d/*", origin, xpub, chain)
+		desc, err := wrap(purpose, key)
+		if err != nil {
+			return "", err
+		}
+
+		return AddChecksum(desc)
+	}
+
+	a := &Account{Purpose: purpose, Account: account}
+	if a.Receive, err = chainDescriptor(wallet.ExternalChain); err != nil {
+		return nil, err
+	}
+	if a.Change, err = chainDescriptor(wallet.InternalChain); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// ForWallet returns the descriptors of an account for every purpose of the
+// wallet, in the order of wallet.Purposes.
+func ForWallet(w *wallet.Wallet, account uint32) ([]*Account, error) {
+	accounts := make([]*Account, 0, len(w.Purposes()))
+	for _, p := range w.Purposes() {
+		a, err := ForAccount(w, p.Purpose, account)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, nil
+}
+
+// ImportRequest is a request of the importdescriptors RPC of Bitcoin Core.
+type ImportRequest struct {
+	Desc      string    `json:"desc"`
+	Timestamp int64     `json:"timestamp"`
+	Active    bool      `json:"active"`
+	Internal  bool      `json:"internal"`
+	Range     [2]uint32 `json:"range"`
+}
+
+// ImportRequests returns the importdescriptors requests of the receive and
+// change descriptors of accounts. The wallet is rescanned from birthday,
+// usually the rescan time of the cipher seed, so blocks mined before the seed
+// existed are skipped.
+func ImportRequests(accounts []*Account, birthday time.Time) []ImportRequest {
+	requests := make([]ImportRequest, 0, 2*len(accounts))
+	for _, a := range accounts {
+		requests = append(requests, ImportRequest{
+			Desc:      a.Receive,
+			Timestamp: birthday.Unix(),
+			Active:    true,
+			Range:     DefaultRange,
+		}, ImportRequest{
+			Desc:      a.Change,
+			Timestamp: birthday.Unix(),
+			Active:    true,
+			Internal:  true,
+			Range:     DefaultRange,
+		})
+	}
+
+	return requests
+}
+
+// ParseWatchOnly creates a watch-only wallet from an account-level extended
+// public key or a ranged descriptor of one of its chains, such as
+// wpkh([fingerprint/84'/0'/0']xpub.../0/*). Extended keys may be SLIP-132
+// encoded, in which case their prefix tells the purpose. Otherwise
+// defaultPurpose is used for them.
+func ParseWatchOnly(s string, network *wallet.Network,
+	defaultPurpose uint32) (*wallet.Wallet, error) {
+
+	s = strings.Join(strings.Fields(s), "")
+	if !strings.Contains(s, "(") {
+		parsed, err := parseSingleKey(s, network)
+		if err != nil {
+			return nil, err
+		}
+		purpose := parsed.Purpose
+		if purpose == 0 {
+			purpose = defaultPurpose
+		}
+
+		return wallet.NewWatchOnly(purpose, parsed.Key, nil, network)
+	}
+
+	if strings.Contains(s, "#") {
+		if err := VerifyChecksum(s); err != nil {
+			return nil, err
+		}
+		s = s[:strings.LastIndexByte(s, '#')]
+	}
+	purpose, keyExpr, err := unwrap(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key must be an account key followed by the chain and the
+	// index wildcard: xpub/0/*, xpub/1/* or xpub/<0;1>/*.
+	var suffix string
+	for _, chain := range []string{"/0/*", "/1/*", "/<0;1>/*"} {
+		if strings.HasSuffix(keyExpr, chain) {
+			suffix = chain
+		}
+	}
+	if suffix == "" {
+		return nil, fmt.Errorf("descriptor must derive the addresses " +
+			"of an account, such as xpub.../0/*")
+	}
+	keyExpr = strings.TrimSuffix(keyExpr, suffix)
+
+	var fingerprint []byte
+	var originPath []uint32
+	if strings.HasPrefix(keyExpr, "[") {
+		end := strings.IndexByte(keyExpr, ']')
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated key origin")
+		}
+		fingerprint, originPath, err = parseOrigin(keyExpr[1:end])
+		if err != nil {
+			return nil, err
+		}
+		keyExpr = keyExpr[end+1:]
+	}
+
+	parsed, err := parseSingleKey(keyExpr, network)
+	if err != nil {
+		return nil, err
+	}
+	if originPath != nil {
+		err := checkOrigin(originPath, purpose, network, parsed.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return wallet.NewWatchOnly(purpose, parsed.Key, fingerprint, network)
+}
+
+// parseSingleKey parses the extended public key of a single key account.
+// Multisig cosigner keys are refused, as the addresses of a multisig wallet
+// can't be derived from one of its keys alone.
+func parseSingleKey(s string, network *wallet.Network) (*wallet.ParsedKey,
+	error) {
+
+	parsed, err := wallet.ParseExtendedKey(s, network)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Multisig {
+		return nil, fmt.Errorf("%s keys belong to multisig cosigners "+
+			"and can't watch a single key account", parsed.Prefix)
+	}
+
+	return parsed, nil
+}
+
+// parseOrigin parses a key origin, fingerprint/path.
+func parseOrigin(origin string) ([]byte, []uint32, error) {
+	fingerprintHex, path, _ := strings.Cut(origin, "/")
+	fingerprint, err := hex.DecodeString(fingerprintHex)
+	if err != nil || len(fingerprint) != 4 {
+		return nil, nil, fmt.Errorf("invalid key origin fingerprint %q",
+			fingerprintHex)
+	}
+	indexes, err := wallet.ParsePath("m/" + path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key origin: %w", err)
+	}
+
+	return fingerprint, indexes, nil
+}
+
+// checkOrigin makes sure a key origin is the account path of the purpose on
+// the network, and matches the account of the key.
+func checkOrigin(path []uint32, purpose uint32, network *wallet.Network,
+	key *hdkeychain.ExtendedKey) error {
+
+	want := []uint32{
+		purpose + hdkeychain.HardenedKeyStart,
+		network.CoinType + hdkeychain.HardenedKeyStart,
+		key.ChildIndex(),
+	}
+	if len(path) != len(want) {
+		return fmt.Errorf("key origin is not an account path " +
+			"purpose'/coin_type'/account'")
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			return fmt.Errorf("key origin doesn't match an account "+
+				"of the descriptor on %s", network)
+		}
+	}
+
+	return nil
+}
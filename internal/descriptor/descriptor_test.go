@@ -0,0 +1,178 @@
+package descriptor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestChecksum checks the checksum against the vectors of BIP380 and Bitcoin
+// Core.
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	vectors := map[string]string{
+		"raw(deadbeef)": "89f8spxm",
+		"pkh([d34db33f/44'/0'/0']xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed5" +
+			"4G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMz" +
+			"QLcgJvLJuZZvRcEL/1/*)": "ml40v0wf",
+	}
+	for desc, want := range vectors {
+		checksum, err := Checksum(desc)
+		require.NoError(t, err)
+		require.Equal(t, want, checksum, desc)
+		require.NoError(t, VerifyChecksum(desc+"#"+want))
+	}
+
+	require.Error(t, VerifyChecksum("raw(deadbeef)#89f8spxn"))
+	require.Error(t, VerifyChecksum("raw(deadbeef)"))
+	_, err := Checksum("raw(deadbeef)\n")
+	require.Error(t, err)
+}
+
+// TestForWallet checks the descriptors of every purpose of a wallet.
+func TestForWallet(t *testing.T) {
+	t.Parallel()
+
+	w, err := wallet.NewFromSeed(make([]byte, 32), wallet.TestNet)
+	require.NoError(t, err)
+	fingerprint, err := w.MasterFingerprint()
+	require.NoError(t, err)
+
+	accounts, err := ForWallet(w, 2)
+	require.NoError(t, err)
+	require.Len(t, accounts, len(wallet.Purposes))
+
+	prefixes := []string{"pkh(", "sh(wpkh(", "wpkh(", "tr("}
+	for i, a := range accounts {
+		purpose := wallet.Purposes[i].Purpose
+		require.Equal(t, purpose, a.Purpose)
+		require.Equal(t, uint32(2), a.Account)
+
+		xpub, err := w.AccountXpub(purpose, 2)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(xpub, "tpub"))
+
+		key := "[" + fingerprint + "/" + strings.TrimPrefix(
+			w.AccountPath(purpose, 2), "m/",
+		) + "]" + xpub
+		require.True(t, strings.HasPrefix(a.Receive, prefixes[i]+key+"/0/*)"))
+		require.True(t, strings.HasPrefix(a.Change, prefixes[i]+key+"/1/*)"))
+		require.NoError(t, VerifyChecksum(a.Receive))
+		require.NoError(t, VerifyChecksum(a.Change))
+	}
+
+	_, err = ForAccount(w, 45, 0)
+	require.ErrorIs(t, err, wallet.ErrUnknownPurpose{Purpose: 45})
+}
+
+// TestImportRequests checks the importdescriptors requests of the receive and
+// change descriptors.
+func TestImportRequests(t *testing.T) {
+	t.Parallel()
+
+	accounts := []*Account{{Receive: "r", Change: "c"}}
+	birthday := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	requests := ImportRequests(accounts, birthday)
+	require.Equal(t, []ImportRequest{{
+		Desc: "r", Timestamp: 1588291200, Active: true,
+		Range: DefaultRange,
+	}, {
+		Desc: "c", Timestamp: 1588291200, Active: true, Internal: true,
+		Range: DefaultRange,
+	}}, requests)
+}
+
+// TestParseWatchOnly checks that watch-only wallets created from descriptors
+// and extended keys derive the addresses of the account.
+func TestParseWatchOnly(t *testing.T) {
+	t.Parallel()
+
+	w, err := wallet.NewFromSeed(make([]byte, 32), wallet.MainNet)
+	require.NoError(t, err)
+	a, err := ForAccount(w, wallet.BIP49Purpose, 3)
+	require.NoError(t, err)
+	zpub, err := w.AccountSLIP132(wallet.BIP84Purpose, 1)
+	require.NoError(t, err)
+	xpub, err := w.AccountXpub(wallet.BIP86Purpose, 0)
+	require.NoError(t, err)
+
+	// Descriptors of either chain, with or without checksum, and keys
+	// whose purpose comes from the prefix or the default.
+	multipath := strings.Replace(
+		a.Receive[:strings.IndexByte(a.Receive, '#')], "/0/*", "/<0;1>/*",
+		1,
+	)
+	tests := []struct {
+		input   string
+		purpose uint32
+		account uint32
+	}{
+		{a.Receive, wallet.BIP49Purpose, 3},
+		{a.Change, wallet.BIP49Purpose, 3},
+		{multipath, wallet.BIP49Purpose, 3},
+		{" " + zpub + "\n", wallet.BIP84Purpose, 1},
+		{xpub, wallet.BIP86Purpose, 0},
+	}
+	for _, test := range tests {
+		watch, err := ParseWatchOnly(
+			test.input, wallet.MainNet, wallet.BIP86Purpose,
+		)
+		require.NoError(t, err, test.input)
+		purpose, account, ok := watch.WatchedAccount()
+		require.True(t, ok)
+		require.Equal(t, test.purpose, purpose)
+		require.Equal(t, test.account, account)
+
+		want, err := w.DeriveAddress(purpose, account, 1, 5)
+		require.NoError(t, err)
+		got, err := watch.DeriveAddress(purpose, account, 1, 5)
+		require.NoError(t, err)
+		require.Equal(t, want.Address, got.Address)
+	}
+
+	// The origin of descriptors is kept, so their descriptors are the
+	// same.
+	watch, err := ParseWatchOnly(a.Receive, wallet.MainNet, 0)
+	require.NoError(t, err)
+	got, err := ForAccount(watch, wallet.BIP49Purpose, 3)
+	require.NoError(t, err)
+	require.Equal(t, a, got)
+
+	// Without origin, the descriptors have none either.
+	watch, err = ParseWatchOnly(xpub, wallet.MainNet, wallet.BIP86Purpose)
+	require.NoError(t, err)
+	got, err = ForAccount(watch, wallet.BIP86Purpose, 0)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(got.Receive, "tr("+xpub+"/0/*)#"))
+
+	for _, input := range []string{
+		a.Receive[:len(a.Receive)-1] + "x",
+		strings.Replace(a.Receive, "sh(wpkh(", "sh(wsh(", 1),
+		strings.Replace(a.Change[:len(a.Change)-9], "/1/*", "/2/*", 1),
+		strings.Replace(a.Change[:len(a.Change)-9], "/49'/", "/84'/", 1),
+		strings.Replace(a.Change[:len(a.Change)-9], "/3'", "/4'", 1),
+		"wpkh(" + zpub + ")",
+	} {
+		_, err := ParseWatchOnly(input, wallet.MainNet, 0)
+		require.Error(t, err, input)
+	}
+	_, err = ParseWatchOnly(a.Receive, wallet.TestNet, 0)
+	require.Error(t, err)
+
+	// Multisig cosigner keys can't watch a single key account.
+	parsed, err := wallet.ParseExtendedKey(zpub, wallet.MainNet)
+	require.NoError(t, err)
+	multisig, err := wallet.EncodeSLIP132Multisig(
+		parsed.Key, wallet.BIP84Purpose, wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+	for _, input := range []string{multisig, "wpkh(" + multisig + "/0/*)"} {
+		_, err = ParseWatchOnly(input, wallet.MainNet, 0)
+		require.ErrorContains(t, err, "multisig", input)
+	}
+}
@@ -0,0 +1,345 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	// DefaultGapLimit is the number of consecutive unused addresses after
+	// which a chain is considered exhausted, as recommended by BIP44.
+	DefaultGapLimit uint32 = 20
+
+	// DefaultMaxAccounts is the default number of accounts scanned per
+	// purpose.
+	DefaultMaxAccounts uint32 = 10
+)
+
+// AddressStats is what a blockchain source knows about an address.
+type AddressStats struct {
+	// TxCount is the number of transactions involving the address. Sources
+	// that only see unspent outputs report the number of those instead.
+	TxCount uint64
+
+	// Balance is the confirmed and unconfirmed balance of the address.
+	Balance btcutil.Amount
+}
+
+// Used reports whether the address has ever been seen on chain.
+func (s *AddressStats) Used() bool {
+	return s.TxCount > 0 || s.Balance > 0
+}
+
+// Checker looks up addresses on a blockchain source.
+type Checker interface {
+	// CheckAddress returns the stats of a derived address.
+	CheckAddress(ctx context.Context,
+		addr *wallet.DerivedAddress) (*AddressStats, error)
+}
+
+// BatchChecker is a Checker that can look up several addresses with a single
+// request. Scan uses CheckAddresses instead of parallel CheckAddress calls for
+// checkers implementing it.
+type BatchChecker interface {
+	Checker
+
+	// CheckAddresses returns the stats of the addresses, in order.
+	CheckAddresses(ctx context.Context,
+		addrs []*wallet.DerivedAddress) ([]*AddressStats, error)
+}
+
+// Progress describes the position of a running scan.
+type Progress struct {
+	// Purpose, Account and Chain locate the chain being scanned.
+	Purpose uint32
+	Account uint32
+	Chain   uint32
+
+	// Index is the last checked address index on the chain.
+	Index uint32
+
+	// Checked is the total number of addresses checked so far, and Used
+	// the number of used addresses found.
+	Checked int
+	Used    int
+}
+
+// Config configures a discovery scan.
+type Config struct {
+	// Wallet is the wallet to scan.
+	Wallet *wallet.Wallet
+
+	// Checker looks the derived addresses up.
+	Checker Checker
+
+	// Purposes are the purposes to scan. All purposes of the wallet are
+	// scanned if empty.
+	Purposes []uint32
+
+	// GapLimit is the number of consecutive unused addresses that ends
+	// the scan of a chain. DefaultGapLimit is used if zero.
+	GapLimit uint32
+
+	// MaxAccounts is the maximum number of accounts scanned per purpose.
+	// DefaultMaxAccounts is used if zero.
+	MaxAccounts uint32
+
+	// Concurrency is the number of addresses checked in parallel, or the
+	// batch size of a BatchChecker. Sources with strict rate limits should
+	// use 1, which is the default.
+	Concurrency int
+
+	// Progress, if set, is called after every checked batch.
+	Progress func(Progress)
+}
+
+// UsedAddress is a used address found by a scan.
+type UsedAddress struct {
+	*wallet.DerivedAddress
+
+	// Stats are the stats reported by the checker.
+	Stats AddressStats
+}
+
+// AccountSummary summarizes the used addresses of a scanned account.
+type AccountSummary struct {
+	Purpose uint32
+	Account uint32
+
+	// Used is the number of used addresses in the account.
+	Used int
+
+	// Balance is the sum of the balances of the account's addresses.
+	Balance btcutil.Amount
+}
+
+// Report is the result of a discovery scan.
+type Report struct {
+	// Used lists every used address, in scan order.
+	Used []UsedAddress
+
+	// Accounts summarizes every scanned account, including the last,
+	// unused one of each purpose.
+	Accounts []AccountSummary
+
+	// Checked is the number of addresses checked.
+	Checked int
+
+	// TotalBalance is the sum of the balances of all used addresses.
+	TotalBalance btcutil.Amount
+}
+
+// Scan runs a BIP44 style discovery scan: for every purpose, accounts are
+// scanned in order, both chains of an account until GapLimit consecutive
+// unused addresses are found. The scan of a purpose ends at the first account
+// without any used address, or after MaxAccounts accounts. Watch-only wallets
+// only scan the account they watch.
+//
+// If the context is canceled, the partial report is returned along with the
+// context's error.
+func Scan(ctx context.Context, cfg *Config) (*Report, error) {
+	if cfg.Wallet == nil || cfg.Checker == nil {
+		return nil, fmt.Errorf("wallet and checker are required")
+	}
+
+	purposes := cfg.Purposes
+	if len(purposes) == 0 {
+		for _, p := range cfg.Wallet.Purposes() {
+			purposes = append(purposes, p.Purpose)
+		}
+	}
+	gapLimit := cfg.GapLimit
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+	maxAccounts := cfg.MaxAccounts
+	if maxAccounts == 0 {
+		maxAccounts = DefaultMaxAccounts
+	}
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	s := &scanner{
+		cfg:         cfg,
+		gapLimit:    gapLimit,
+		concurrency: concurrency,
+		report:      &Report{},
+	}
+	// Watch-only wallets only have the keys of one account, which is
+	// scanned whatever its number.
+	firstAccount, lastAccount := uint32(0), maxAccounts-1
+	if _, watched, ok := cfg.Wallet.WatchedAccount(); ok {
+		firstAccount, lastAccount = watched, watched
+	}
+	for _, purpose := range purposes {
+		for account := firstAccount; account <= lastAccount; account++ {
+			if !cfg.Wallet.HasAccount(purpose, account) {
+				continue
+			}
+			summary, err := s.scanAccount(ctx, purpose, account)
+			if summary != nil {
+				s.report.Accounts = append(
+					s.report.Accounts, *summary,
+				)
+			}
+			if err != nil {
+				return s.report, err
+			}
+
+			if summary.Used == 0 {
+				break
+			}
+		}
+	}
+
+	return s.report, nil
+}
+
+// scanner holds the state of a running scan.
+type scanner struct {
+	cfg         *Config
+	gapLimit    uint32
+	concurrency int
+	report      *Report
+}
+
+// scanAccount scans both chains of an account.
+func (s *scanner) scanAccount(ctx context.Context, purpose,
+	account uint32) (*AccountSummary, error) {
+
+	summary := &AccountSummary{Purpose: purpose, Account: account}
+	for _, chain := range []uint32{wallet.ExternalChain,
+		wallet.InternalChain} {
+
+		err := s.scanChain(ctx, summary, chain)
+		if err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, nil
+}
+
+// scanChain checks the addresses of a chain in batches until gapLimit
+// consecutive unused addresses were seen.
+func (s *scanner) scanChain(ctx context.Context, summary *AccountSummary,
+	chain uint32) error {
+
+	var gap, index uint32
+	for gap < s.gapLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Never check more addresses than needed to close the gap.
+		batchSize := s.gapLimit - gap
+		if batchSize > uint32(s.concurrency) {
+			batchSize = uint32(s.concurrency)
+		}
+
+		addrs := make([]*wallet.DerivedAddress, batchSize)
+		for i := range addrs {
+			addr, err := s.cfg.Wallet.DeriveAddress(
+				summary.Purpose, summary.Account, chain,
+				index+uint32(i),
+			)
+			if err != nil {
+				return err
+			}
+			addrs[i] = addr
+		}
+
+		stats, err := s.checkBatch(ctx, addrs)
+		if err != nil {
+			// Sources don't necessarily wrap the context error, so
+			// a cancellation is reported as such here.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+
+		// Process the results in order, so the gap is counted
+		// correctly.
+		for i, st := range stats {
+			s.report.Checked++
+			if !st.Used() {
+				gap++
+				continue
+			}
+
+			gap = 0
+			s.report.Used = append(s.report.Used, UsedAddress{
+				DerivedAddress: addrs[i],
+				Stats:          *st,
+			})
+			s.report.TotalBalance += st.Balance
+			summary.Used++
+			summary.Balance += st.Balance
+		}
+		index += batchSize
+
+		if s.cfg.Progress != nil {
+			s.cfg.Progress(Progress{
+				Purpose: summary.Purpose,
+				Account: summary.Account,
+				Chain:   chain,
+				Index:   index - 1,
+				Checked: s.report.Checked,
+				Used:    len(s.report.Used),
+			})
+		}
+	}
+
+	return nil
+}
+
+// checkBatch checks a batch of addresses in parallel, or with a single request
+// if the checker supports it.
+func (s *scanner) checkBatch(ctx context.Context,
+	addrs []*wallet.DerivedAddress) ([]*AddressStats, error) {
+
+	if batcher, ok := s.cfg.Checker.(BatchChecker); ok {
+		stats, err := batcher.CheckAddresses(ctx, addrs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check %s..%s: %w",
+				addrs[0].Path, addrs[len(addrs)-1].Path, err)
+		}
+		if len(stats) != len(addrs) {
+			return nil, fmt.Errorf("checker returned %d results for "+
+				"%d addresses", len(stats), len(addrs))
+		}
+
+		return stats, nil
+	}
+
+	stats := make([]*AddressStats, len(addrs))
+	errs := make([]error, len(addrs))
+
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, addr *wallet.DerivedAddress) {
+			defer wg.Done()
+
+			stats[i], errs[i] = s.cfg.Checker.CheckAddress(ctx, addr)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("unable to check %s (%s): %w",
+				addrs[i].Address, addrs[i].Path, err)
+		}
+	}
+
+	return stats, nil
+}
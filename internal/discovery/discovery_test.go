@@ -0,0 +1,287 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChecker reports the addresses at the given paths as used and counts
+// how many addresses were checked.
+type fakeChecker struct {
+	used map[string]btcutil.Amount
+
+	mu      sync.Mutex
+	checked map[string]bool
+}
+
+func newFakeChecker(used map[string]btcutil.Amount) *fakeChecker {
+	return &fakeChecker{used: used, checked: make(map[string]bool)}
+}
+
+func (f *fakeChecker) CheckAddress(_ context.Context,
+	addr *wallet.DerivedAddress) (*AddressStats, error) {
+
+	f.mu.Lock()
+	f.checked[addr.Path] = true
+	f.mu.Unlock()
+
+	balance, ok := f.used[addr.Path]
+	if !ok {
+		return &AddressStats{}, nil
+	}
+
+	return &AddressStats{TxCount: 1, Balance: balance}, nil
+}
+
+func newTestWallet(t *testing.T) *wallet.Wallet {
+	t.Helper()
+
+	w, err := wallet.NewFromSeed(make([]byte, 32), wallet.MainNet)
+	require.NoError(t, err)
+
+	return w
+}
+
+// TestScanGapLimit checks that chains are scanned until the gap limit and
+// that used addresses right before the gap closes are still found.
+func TestScanGapLimit(t *testing.T) {
+	t.Parallel()
+
+	for _, concurrency := range []int{1, 3, 20} {
+		checker := newFakeChecker(map[string]btcutil.Amount{
+			"m/84'/0'/0'/0/0": 1000,
+			"m/84'/0'/0'/0/4": 0,
+			"m/84'/0'/0'/0/9": 500,
+			"m/84'/0'/0'/1/2": 250,
+			"m/84'/0'/1'/0/3": 100,
+		})
+		report, err := Scan(context.Background(), &Config{
+			Wallet:      newTestWallet(t),
+			Checker:     checker,
+			Purposes:    []uint32{wallet.BIP84Purpose},
+			GapLimit:    5,
+			Concurrency: concurrency,
+		})
+		require.NoError(t, err)
+
+		// Index 4 is used without balance, which extends the gap so
+		// index 9 is found too. Index 15 would be out of reach.
+		var paths []string
+		for _, u := range report.Used {
+			paths = append(paths, u.Path)
+		}
+		require.Equal(t, []string{
+			"m/84'/0'/0'/0/0", "m/84'/0'/0'/0/4", "m/84'/0'/0'/0/9",
+			"m/84'/0'/0'/1/2", "m/84'/0'/1'/0/3",
+		}, paths)
+		require.Equal(t, btcutil.Amount(1850), report.TotalBalance)
+
+		// Account 2 is unused and ends the scan.
+		require.Len(t, report.Accounts, 3)
+		require.Equal(t, 4, report.Accounts[0].Used)
+		require.Equal(t, btcutil.Amount(1750), report.Accounts[0].Balance)
+		require.Zero(t, report.Accounts[2].Used)
+
+		// Chains are checked exactly up to the gap.
+		require.True(t, checker.checked["m/84'/0'/0'/0/14"])
+		require.False(t, checker.checked["m/84'/0'/0'/0/15"])
+		require.True(t, checker.checked["m/84'/0'/2'/1/4"])
+		require.False(t, checker.checked["m/84'/0'/3'/0/0"])
+		require.Equal(t, len(checker.checked), report.Checked)
+	}
+}
+
+// batchChecker is a fakeChecker that records the size of every batch.
+type batchChecker struct {
+	*fakeChecker
+
+	batches []int
+}
+
+func (b *batchChecker) CheckAddresses(ctx context.Context,
+	addrs []*wallet.DerivedAddress) ([]*AddressStats, error) {
+
+	b.batches = append(b.batches, len(addrs))
+
+	stats := make([]*AddressStats, len(addrs))
+	for i, addr := range addrs {
+		stats[i], _ = b.CheckAddress(ctx, addr)
+	}
+
+	return stats, nil
+}
+
+// TestScanBatchChecker checks that batch checkers get whole batches that
+// never exceed the remaining gap.
+func TestScanBatchChecker(t *testing.T) {
+	t.Parallel()
+
+	checker := &batchChecker{
+		fakeChecker: newFakeChecker(map[string]btcutil.Amount{
+			"m/84'/0'/0'/0/1": 1000,
+		}),
+	}
+	report, err := Scan(context.Background(), &Config{
+		Wallet:      newTestWallet(t),
+		Checker:     checker,
+		Purposes:    []uint32{wallet.BIP84Purpose},
+		GapLimit:    5,
+		Concurrency: 4,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Used, 1)
+
+	// External chain: 4 addresses with index 1 used, leaving a gap of 2,
+	// then the 3 left to close it. Every other chain: 4 + 1.
+	require.Equal(t, []int{4, 3, 4, 1, 4, 1, 4, 1}, checker.batches)
+}
+
+// TestScanSource checks a scan backed by a blockchain source.
+func TestScanSource(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	used, err := w.DeriveAddress(wallet.BIP86Purpose, 0, 1, 3)
+	require.NoError(t, err)
+
+	src := blockchain.NewMock()
+	src.AddUTXO(used.Address, blockchain.UTXO{Value: 2500, Height: 1})
+
+	checker := NewSourceChecker(src)
+	_, isBatch := checker.(BatchChecker)
+	require.False(t, isBatch)
+
+	report, err := Scan(context.Background(), &Config{
+		Wallet:   w,
+		Checker:  checker,
+		Purposes: []uint32{wallet.BIP86Purpose},
+		GapLimit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Used, 1)
+	require.Equal(t, used.Path, report.Used[0].Path)
+	require.Equal(t, btcutil.Amount(2500), report.TotalBalance)
+	require.Len(t, src.Lookups, report.Checked)
+}
+
+// TestScanMaxAccounts checks that the scan stops after MaxAccounts accounts
+// even if they are all used.
+func TestScanMaxAccounts(t *testing.T) {
+	t.Parallel()
+
+	checker := newFakeChecker(map[string]btcutil.Amount{
+		"m/44'/0'/0'/0/0": 1,
+		"m/44'/0'/1'/0/0": 1,
+		"m/44'/0'/2'/0/0": 1,
+	})
+	report, err := Scan(context.Background(), &Config{
+		Wallet:      newTestWallet(t),
+		Checker:     checker,
+		Purposes:    []uint32{wallet.BIP44Purpose},
+		GapLimit:    2,
+		MaxAccounts: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Accounts, 2)
+	require.Len(t, report.Used, 2)
+}
+
+// TestScanWatchOnly checks that a watch-only wallet only scans its account,
+// even past MaxAccounts.
+func TestScanWatchOnly(t *testing.T) {
+	t.Parallel()
+
+	xpub, err := newTestWallet(t).AccountXpub(wallet.BIP49Purpose, 1)
+	require.NoError(t, err)
+	parsed, err := wallet.ParseExtendedKey(xpub, wallet.MainNet)
+	require.NoError(t, err)
+	w, err := wallet.NewWatchOnly(
+		wallet.BIP49Purpose, parsed.Key, nil, wallet.MainNet,
+	)
+	require.NoError(t, err)
+
+	checker := newFakeChecker(map[string]btcutil.Amount{
+		"m/49'/0'/1'/1/1": 1,
+	})
+	report, err := Scan(context.Background(), &Config{
+		Wallet:      w,
+		Checker:     checker,
+		GapLimit:    2,
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []AccountSummary{{
+		Purpose: wallet.BIP49Purpose, Account: 1, Used: 1, Balance: 1,
+	}}, report.Accounts)
+	require.Equal(t, 2+4, report.Checked)
+}
+
+// cancelingChecker cancels the scan after a number of checks.
+type cancelingChecker struct {
+	cancel context.CancelFunc
+	left   int
+}
+
+func (c *cancelingChecker) CheckAddress(ctx context.Context,
+	_ *wallet.DerivedAddress) (*AddressStats, error) {
+
+	c.left--
+	if c.left == 0 {
+		c.cancel()
+	}
+
+	return &AddressStats{}, nil
+}
+
+// TestScanCancel checks that a canceled scan stops and returns the partial
+// report.
+func TestScanCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var progress []Progress
+	report, err := Scan(ctx, &Config{
+		Wallet:  newTestWallet(t),
+		Checker: &cancelingChecker{cancel: cancel, left: 3},
+		Progress: func(p Progress) {
+			progress = append(progress, p)
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, report)
+	require.Equal(t, 3, report.Checked)
+	require.Len(t, progress, 3)
+	require.Equal(t, uint32(2), progress[2].Index)
+}
+
+// TestScanCheckerError checks that source errors abort the scan.
+func TestScanCheckerError(t *testing.T) {
+	t.Parallel()
+
+	errSource := errors.New("source down")
+	_, err := Scan(context.Background(), &Config{
+		Wallet:  newTestWallet(t),
+		Checker: errChecker{errSource},
+	})
+	require.ErrorIs(t, err, errSource)
+}
+
+type errChecker struct {
+	err error
+}
+
+func (e errChecker) CheckAddress(context.Context,
+	*wallet.DerivedAddress) (*AddressStats, error) {
+
+	return nil, e.err
+}
@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"context"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// sourceChecker checks addresses on a blockchain source.
+type sourceChecker struct {
+	src blockchain.Source
+}
+
+// batchSourceChecker checks whole batches on a blockchain source that
+// supports them.
+type batchSourceChecker struct {
+	sourceChecker
+	batchSrc blockchain.BatchSource
+}
+
+// NewSourceChecker returns a Checker that looks addresses up on a blockchain
+// source. The checker is a BatchChecker if the source is a BatchSource.
+func NewSourceChecker(src blockchain.Source) Checker {
+	if batchSrc, ok := src.(blockchain.BatchSource); ok {
+		return &batchSourceChecker{
+			sourceChecker: sourceChecker{src: src},
+			batchSrc:      batchSrc,
+		}
+	}
+
+	return &sourceChecker{src: src}
+}
+
+// newStats converts the info reported by a source.
+func newStats(info *blockchain.AddressInfo) *AddressStats {
+	return &AddressStats{
+		TxCount: info.TxCount,
+		Balance: info.Balance(),
+	}
+}
+
+// CheckAddress returns the stats of a derived address.
+func (c *sourceChecker) CheckAddress(ctx context.Context,
+	addr *wallet.DerivedAddress) (*AddressStats, error) {
+
+	info, err := c.src.AddressInfo(ctx, addr.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStats(info), nil
+}
+
+// CheckAddresses returns the stats of the addresses with a single request.
+func (c *batchSourceChecker) CheckAddresses(ctx context.Context,
+	addrs []*wallet.DerivedAddress) ([]*AddressStats, error) {
+
+	btcAddrs := make([]btcutil.Address, len(addrs))
+	for i, addr := range addrs {
+		btcAddrs[i] = addr.Address
+	}
+
+	infos, err := c.batchSrc.AddressInfos(ctx, btcAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*AddressStats, len(infos))
+	for i, info := range infos {
+		stats[i] = newStats(info)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,367 @@
+package electrum
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ProtocolVersion is the Electrum protocol version negotiated with the
+	// server.
+	ProtocolVersion = "1.4"
+
+	// DefaultTimeout is the timeout of a single request or batch.
+	DefaultTimeout = 30 * time.Second
+
+	// clientName is sent to the server during version negotiation.
+	clientName = "aezeed-address-generator"
+
+	// maxLineSize is the largest response accepted, which leaves room
+	// for the history of very busy addresses.
+	maxLineSize = 32 * 1024 * 1024
+)
+
+// DialFunc opens a network connection. It allows connecting through a proxy.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn,
+	error)
+
+// Config configures a connection to an Electrum server.
+type Config struct {
+	// Server is the server address as tcp://host:port or ssl://host:port.
+	// A plain host:port uses TCP.
+	Server string
+
+	// InsecureSkipVerify disables the verification of the server's TLS
+	// certificate. Most personal servers (Electrs behind a proxy, Fulcrum)
+	// use self-signed certificates.
+	InsecureSkipVerify bool
+
+	// Timeout is the timeout of every request. DefaultTimeout is used if
+	// zero.
+	Timeout time.Duration
+
+	// Dial opens the underlying connection. A net.Dialer is used if nil.
+	Dial DialFunc
+}
+
+// Client is a connection to an Electrum server. Requests are serialized, so a
+// Client is safe for concurrent use.
+type Client struct {
+	cfg    *Config
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mu     sync.Mutex
+	nextID uint64
+}
+
+// request is a JSON-RPC request.
+type request struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// response is a JSON-RPC response.
+type response struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error returned by the server.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns a human-readable string describing the error.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("electrum server error %d: %s", e.Code, e.Message)
+}
+
+// Call is a single call of a batch. Result is filled with the decoded result,
+// or Err with the error the server returned for the call.
+type Call struct {
+	Method string
+	Params []interface{}
+	Result interface{}
+	Err    error
+}
+
+// parseServer splits a server URL into its address and whether TLS is used.
+func parseServer(server string) (string, bool, error) {
+	if !strings.Contains(server, "://") {
+		return server, false, nil
+	}
+
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid server %q: %w", server, err)
+	}
+	if u.Port() == "" {
+		return "", false, fmt.Errorf("server %q lacks a port", server)
+	}
+	switch u.Scheme {
+	case "tcp":
+		return u.Host, false, nil
+	case "ssl", "tls":
+		return u.Host, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown scheme %q, use tcp:// or "+
+			"ssl://", u.Scheme)
+	}
+}
+
+// Dial connects to an Electrum server and negotiates the protocol version.
+func Dial(ctx context.Context, cfg *Config) (*Client, error) {
+	addr, useTLS, err := parseServer(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := cfg.Dial
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", addr, err)
+	}
+
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w",
+				addr, err)
+		}
+		conn = tlsConn
+	}
+
+	c := &Client{
+		cfg:    cfg,
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, 64*1024),
+	}
+
+	var version []string
+	err = c.Call(
+		ctx, "server.version", []interface{}{clientName, ProtocolVersion},
+		&version,
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("version negotiation failed: %w", err)
+	}
+
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Features is the subset of the server.features response the client uses.
+type Features struct {
+	// GenesisHash identifies the chain the server follows.
+	GenesisHash string `json:"genesis_hash"`
+
+	// ServerVersion is the server software and its version.
+	ServerVersion string `json:"server_version"`
+}
+
+// Features returns the features advertised by the server.
+func (c *Client) Features(ctx context.Context) (*Features, error) {
+	var features Features
+	err := c.Call(ctx, "server.features", nil, &features)
+	if err != nil {
+		return nil, err
+	}
+
+	return &features, nil
+}
+
+// Call performs a single request and decodes its result into result.
+func (c *Client) Call(ctx context.Context, method string,
+	params []interface{}, result interface{}) error {
+
+	call := &Call{Method: method, Params: params, Result: result}
+	if err := c.Batch(ctx, []*Call{call}); err != nil {
+		return err
+	}
+
+	return call.Err
+}
+
+// Batch sends all calls as a single JSON-RPC batch and waits for all
+// responses. The returned error is only set for connection failures, errors
+// of individual calls are stored in the calls.
+func (c *Client) Batch(ctx context.Context, calls []*Call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byID := make(map[uint64]*Call, len(calls))
+	reqs := make([]request, 0, len(calls))
+	for _, call := range calls {
+		c.nextID++
+		params := call.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		reqs = append(reqs, request{
+			JSONRPC: "2.0",
+			ID:      c.nextID,
+			Method:  call.Method,
+			Params:  params,
+		})
+		byID[c.nextID] = call
+	}
+
+	// Single calls are sent as plain requests, as not every server
+	// accepts a batch of one.
+	var payload []byte
+	var err error
+	if len(reqs) == 1 {
+		payload, err = json.Marshal(reqs[0])
+	} else {
+		payload, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return err
+	}
+
+	timeout := c.cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// Unblock the connection if the context is canceled while waiting.
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if _, err := c.conn.Write(append(payload, '\n')); err != nil {
+		return c.connErr(ctx, err)
+	}
+
+	for len(byID) > 0 {
+		line, err := c.readLine()
+		if err != nil {
+			return c.connErr(ctx, err)
+		}
+
+		resps, err := decodeResponses(line)
+		if err != nil {
+			return err
+		}
+		for _, resp := range resps {
+			// Notifications and stale responses don't carry one
+			// of our pending IDs.
+			if resp.ID == nil {
+				continue
+			}
+			call, ok := byID[*resp.ID]
+			if !ok {
+				continue
+			}
+			delete(byID, *resp.ID)
+
+			switch {
+			case resp.Error != nil:
+				call.Err = resp.Error
+			case call.Result != nil:
+				call.Err = json.Unmarshal(resp.Result, call.Result)
+			}
+		}
+	}
+
+	return nil
+}
+
+// connErr prefers the context's error over the connection error it caused.
+func (c *Client) connErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return fmt.Errorf("electrum connection error: %w", err)
+}
+
+// readLine reads a newline terminated message.
+func (c *Client) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := c.reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxLineSize {
+			return nil, fmt.Errorf("response exceeds %d bytes",
+				maxLineSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+// decodeResponses decodes a single response or a batch of responses.
+func decodeResponses(line []byte) ([]response, error) {
+	trimmed := strings.TrimSpace(string(line))
+	if strings.HasPrefix(trimmed, "[") {
+		var resps []response
+		if err := json.Unmarshal(line, &resps); err != nil {
+			return nil, fmt.Errorf("invalid batch response: %w", err)
+		}
+		return resps, nil
+	}
+
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return []response{resp}, nil
+}
+
+// ScriptHash returns the Electrum script hash of an output script: the
+// reversed sha256 of the script, hex encoded.
+func ScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,351 @@
+package electrum
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGenesis is the genesis hash the fake server reports.
+const fakeGenesis = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+// fakeTxID is the transaction ID the fake server returns for broadcasts.
+const fakeTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// fakeServer is a minimal Electrum server answering from fixed script hash
+// data. Batch responses are sent in reverse order and every response is
+// preceded by a notification, as real servers are free to do both.
+type fakeServer struct {
+	listener net.Listener
+	history  map[string][]HistoryItem
+	balances map[string]Balance
+	unspent  map[string][]Unspent
+	txs      map[string]string
+
+	mu         sync.Mutex
+	batches    int
+	broadcasts []string
+}
+
+func newFakeServer(t *testing.T, useTLS bool) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if useTLS {
+		listener = tls.NewListener(listener, &tls.Config{
+			Certificates: []tls.Certificate{selfSignedCert(t)},
+		})
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeServer{
+		listener: listener,
+		history:  make(map[string][]HistoryItem),
+		balances: make(map[string]Balance),
+		unspent:  make(map[string][]Unspent),
+		txs:      make(map[string]string),
+	}
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var reqs []request
+		batch := len(line) > 0 && line[0] == '['
+		if batch {
+			if json.Unmarshal(line, &reqs) != nil {
+				return
+			}
+			s.mu.Lock()
+			s.batches++
+			s.mu.Unlock()
+		} else {
+			var req request
+			if json.Unmarshal(line, &req) != nil {
+				return
+			}
+			reqs = []request{req}
+		}
+
+		resps := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			resps[len(reqs)-1-i] = s.respond(req)
+		}
+
+		notification := `{"jsonrpc":"2.0","method":` +
+			`"blockchain.headers.subscribe","params":[]}` + "\n"
+		if _, err := conn.Write([]byte(notification)); err != nil {
+			return
+		}
+
+		var out []byte
+		if batch {
+			out, _ = json.Marshal(resps)
+		} else {
+			out, _ = json.Marshal(resps[0])
+		}
+		if _, err := conn.Write(append(out, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) respond(req request) map[string]interface{} {
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "server.version":
+		resp["result"] = []string{"FakeServer 1.0", ProtocolVersion}
+
+	case "server.features":
+		resp["result"] = map[string]interface{}{
+			"genesis_hash":   fakeGenesis,
+			"server_version": "FakeServer 1.0",
+			"hash_function":  "sha256",
+		}
+
+	case "blockchain.scripthash.get_history":
+		history := s.history[req.Params[0].(string)]
+		if history == nil {
+			history = []HistoryItem{}
+		}
+		resp["result"] = history
+
+	case "blockchain.scripthash.get_balance":
+		resp["result"] = s.balances[req.Params[0].(string)]
+
+	case "blockchain.scripthash.listunspent":
+		unspent := s.unspent[req.Params[0].(string)]
+		if unspent == nil {
+			unspent = []Unspent{}
+		}
+		resp["result"] = unspent
+
+	case "blockchain.transaction.get":
+		rawTx, ok := s.txs[req.Params[0].(string)]
+		if !ok {
+			resp["error"] = RPCError{
+				Code: 2, Message: "missing transaction",
+			}
+			break
+		}
+		resp["result"] = rawTx
+
+	case "blockchain.transaction.broadcast":
+		rawTx := req.Params[0].(string)
+		s.mu.Lock()
+		s.broadcasts = append(s.broadcasts, rawTx)
+		s.mu.Unlock()
+		resp["result"] = fakeTxID
+
+	default:
+		resp["error"] = RPCError{Code: -32601, Message: "unknown method"}
+	}
+
+	return resp
+}
+
+// selfSignedCert returns a throwaway certificate for 127.0.0.1.
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader, template, template, &key.PublicKey, key,
+	)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// TestScriptHash checks the script hash against the example of the protocol
+// documentation.
+func TestScriptHash(t *testing.T) {
+	t.Parallel()
+
+	pkScript, err := hex.DecodeString(
+		"76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac",
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t, "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161",
+		ScriptHash(pkScript),
+	)
+}
+
+// TestParseServer checks the accepted server address forms.
+func TestParseServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		server string
+		addr   string
+		tls    bool
+		err    bool
+	}{
+		{"electrum.local:50001", "electrum.local:50001", false, false},
+		{"tcp://127.0.0.1:50001", "127.0.0.1:50001", false, false},
+		{"ssl://electrum.local:50002", "electrum.local:50002", true, false},
+		{"ssl://electrum.local", "", false, true},
+		{"http://electrum.local:50001", "", false, true},
+	}
+	for _, test := range tests {
+		addr, useTLS, err := parseServer(test.server)
+		if test.err {
+			require.Error(t, err, test.server)
+			continue
+		}
+		require.NoError(t, err, test.server)
+		require.Equal(t, test.addr, addr)
+		require.Equal(t, test.tls, useTLS)
+	}
+}
+
+// TestGetScriptStats checks single and batched script hash requests over TCP
+// and TLS.
+func TestGetScriptStats(t *testing.T) {
+	t.Parallel()
+
+	used := []byte{0x00, 0x14, 0x01}
+	unused := []byte{0x00, 0x14, 0x02}
+
+	for _, useTLS := range []bool{false, true} {
+		server := newFakeServer(t, useTLS)
+		server.history[ScriptHash(used)] = []HistoryItem{
+			{TxHash: "aa", Height: 800000},
+			{TxHash: "bb", Height: 0, Fee: 200},
+		}
+		server.balances[ScriptHash(used)] = Balance{
+			Confirmed: 5000, Unconfirmed: -1000,
+		}
+
+		scheme := "tcp://"
+		if useTLS {
+			scheme = "ssl://"
+		}
+		ctx := context.Background()
+		client, err := Dial(ctx, &Config{
+			Server:             scheme + server.addr(),
+			InsecureSkipVerify: true,
+		})
+		require.NoError(t, err)
+		defer client.Close()
+
+		features, err := client.Features(ctx)
+		require.NoError(t, err)
+		require.Equal(t, fakeGenesis, features.GenesisHash)
+
+		history, err := client.GetHistory(ctx, ScriptHash(used))
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, int64(200), history[1].Fee)
+
+		balance, err := client.GetBalance(ctx, ScriptHash(used))
+		require.NoError(t, err)
+		require.Equal(t, int64(4000), balance.Total())
+
+		stats, err := client.GetScriptStats(ctx, [][]byte{unused, used})
+		require.NoError(t, err)
+		require.Len(t, stats, 2)
+		require.Empty(t, stats[0].History)
+		require.Zero(t, stats[0].Balance.Total())
+		require.Len(t, stats[1].History, 2)
+		require.Equal(t, int64(4000), stats[1].Balance.Total())
+
+		server.mu.Lock()
+		require.Equal(t, 1, server.batches)
+		server.mu.Unlock()
+	}
+}
+
+// TestServerError checks that errors returned by the server are reported per
+// call.
+func TestServerError(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, false)
+	ctx := context.Background()
+	client, err := Dial(ctx, &Config{Server: server.addr()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	calls := []*Call{
+		{Method: "blockchain.unknown"},
+		{Method: "server.version", Result: &[]string{}},
+	}
+	require.NoError(t, client.Batch(ctx, calls))
+
+	var rpcErr *RPCError
+	require.ErrorAs(t, calls[0].Err, &rpcErr)
+	require.Equal(t, -32601, rpcErr.Code)
+	require.NoError(t, calls[1].Err)
+}
+
+// TestCancel checks that a request waiting for an unresponsive server returns
+// once the context is canceled.
+func TestCancel(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// Accept the connection but never answer.
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 100*time.Millisecond,
+	)
+	defer cancel()
+
+	_, err = Dial(ctx, &Config{Server: listener.Addr().String()})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,164 @@
+package electrum
+
+import (
+	"context"
+	"fmt"
+)
+
+// HistoryItem is a transaction of a script hash's history.
+type HistoryItem struct {
+	// TxHash is the transaction ID.
+	TxHash string `json:"tx_hash"`
+
+	// Height is the confirmation height, 0 for unconfirmed transactions
+	// and -1 for unconfirmed transactions with unconfirmed parents.
+	Height int64 `json:"height"`
+
+	// Fee is the fee in satoshis, only set for unconfirmed transactions.
+	Fee int64 `json:"fee,omitempty"`
+}
+
+// Balance is the balance of a script hash in satoshis.
+type Balance struct {
+	Confirmed   int64 `json:"confirmed"`
+	Unconfirmed int64 `json:"unconfirmed"`
+}
+
+// Total returns the confirmed plus the unconfirmed balance.
+func (b *Balance) Total() int64 {
+	return b.Confirmed + b.Unconfirmed
+}
+
+// ScriptStats is the history and the balance of an output script.
+type ScriptStats struct {
+	History []HistoryItem
+	Balance Balance
+}
+
+// GetHistory returns the confirmed and mempool history of a script hash.
+func (c *Client) GetHistory(ctx context.Context,
+	scriptHash string) ([]HistoryItem, error) {
+
+	var history []HistoryItem
+	err := c.Call(
+		ctx, "blockchain.scripthash.get_history",
+		[]interface{}{scriptHash}, &history,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetBalance returns the balance of a script hash.
+func (c *Client) GetBalance(ctx context.Context,
+	scriptHash string) (*Balance, error) {
+
+	var balance Balance
+	err := c.Call(
+		ctx, "blockchain.scripthash.get_balance",
+		[]interface{}{scriptHash}, &balance,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetScriptStats returns the history and the balance of every output script
+// with a single batch request.
+func (c *Client) GetScriptStats(ctx context.Context,
+	pkScripts [][]byte) ([]*ScriptStats, error) {
+
+	stats := make([]*ScriptStats, len(pkScripts))
+	calls := make([]*Call, 0, 2*len(pkScripts))
+	for i, pkScript := range pkScripts {
+		stats[i] = &ScriptStats{}
+		params := []interface{}{ScriptHash(pkScript)}
+		calls = append(calls,
+			&Call{
+				Method: "blockchain.scripthash.get_history",
+				Params: params,
+				Result: &stats[i].History,
+			},
+			&Call{
+				Method: "blockchain.scripthash.get_balance",
+				Params: params,
+				Result: &stats[i].Balance,
+			},
+		)
+	}
+
+	if err := c.Batch(ctx, calls); err != nil {
+		return nil, err
+	}
+	for i, call := range calls {
+		if call.Err != nil {
+			return nil, fmt.Errorf("%s of script %d failed: %w",
+				call.Method, i/2, call.Err)
+		}
+	}
+
+	return stats, nil
+}
+
+// Unspent is an unspent output of a script hash.
+type Unspent struct {
+	TxHash string `json:"tx_hash"`
+	TxPos  uint32 `json:"tx_pos"`
+	Value  int64  `json:"value"`
+
+	// Height is the confirmation height, 0 for unconfirmed outputs.
+	Height int64 `json:"height"`
+}
+
+// ListUnspent returns the unspent outputs of a script hash.
+func (c *Client) ListUnspent(ctx context.Context,
+	scriptHash string) ([]Unspent, error) {
+
+	var unspent []Unspent
+	err := c.Call(
+		ctx, "blockchain.scripthash.listunspent",
+		[]interface{}{scriptHash}, &unspent,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return unspent, nil
+}
+
+// BroadcastTransaction publishes a hex encoded raw transaction and returns its
+// ID.
+func (c *Client) BroadcastTransaction(ctx context.Context,
+	rawTx string) (string, error) {
+
+	var txid string
+	err := c.Call(
+		ctx, "blockchain.transaction.broadcast", []interface{}{rawTx},
+		&txid,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return txid, nil
+}
+
+// GetTransaction returns the hex encoded raw transaction with the given ID.
+func (c *Client) GetTransaction(ctx context.Context,
+	txid string) (string, error) {
+
+	var rawTx string
+	err := c.Call(
+		ctx, "blockchain.transaction.get", []interface{}{txid, false},
+		&rawTx,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return rawTx, nil
+}
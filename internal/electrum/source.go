@@ -0,0 +1,304 @@
+package electrum
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Source is a blockchain source backed by an Electrum server. It connects on
+// first use and reconnects after connection errors.
+type Source struct {
+	cfg     *Config
+	genesis *chainhash.Hash
+
+	mu     sync.Mutex
+	client *Client
+}
+
+// A compile-time check to ensure Source implements the blockchain.BatchSource
+// interface.
+var _ blockchain.BatchSource = (*Source)(nil)
+
+// NewSource returns a source for the server in cfg, which must follow the
+// chain with the given genesis block.
+func NewSource(cfg *Config, genesis *chainhash.Hash) *Source {
+	return &Source{cfg: cfg, genesis: genesis}
+}
+
+// getClient returns the connection to the server, connecting if needed.
+func (s *Source) getClient(ctx context.Context) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	client, err := Dial(ctx, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the server follows the expected chain, so balances of
+	// another network are never reported.
+	features, err := client.Features(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("unable to query server features: %w", err)
+	}
+	if features.GenesisHash != s.genesis.String() {
+		client.Close()
+		return nil, fmt.Errorf("server follows the chain with genesis "+
+			"%s, expected %s", features.GenesisHash, s.genesis)
+	}
+	s.client = client
+
+	return client, nil
+}
+
+// do runs f with a connection to the server. Connection errors close the
+// connection, so the next call reconnects, while errors returned by the
+// server keep it.
+func (s *Source) do(ctx context.Context, f func(*Client) error) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = f(client)
+	var rpcErr *RPCError
+	if err != nil && !errors.As(err, &rpcErr) {
+		s.mu.Lock()
+		if s.client == client {
+			s.client.Close()
+			s.client = nil
+		}
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+// addressScriptHash returns the script hash of an address.
+func addressScriptHash(addr btcutil.Address) (string, error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+
+	return ScriptHash(pkScript), nil
+}
+
+// AddressInfos returns the info of all addresses with a single batch request.
+func (s *Source) AddressInfos(ctx context.Context,
+	addrs []btcutil.Address) ([]*blockchain.AddressInfo, error) {
+
+	pkScripts := make([][]byte, len(addrs))
+	for i, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		pkScripts[i] = pkScript
+	}
+
+	var stats []*ScriptStats
+	err := s.do(ctx, func(c *Client) error {
+		var err error
+		stats, err = c.GetScriptStats(ctx, pkScripts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*blockchain.AddressInfo, len(stats))
+	for i, st := range stats {
+		infos[i] = &blockchain.AddressInfo{
+			TxCount:     uint64(len(st.History)),
+			Confirmed:   btcutil.Amount(st.Balance.Confirmed),
+			Unconfirmed: btcutil.Amount(st.Balance.Unconfirmed),
+		}
+	}
+
+	return infos, nil
+}
+
+// AddressInfo returns the transaction count and the balance of an address.
+func (s *Source) AddressInfo(ctx context.Context,
+	addr btcutil.Address) (*blockchain.AddressInfo, error) {
+
+	infos, err := s.AddressInfos(ctx, []btcutil.Address{addr})
+	if err != nil {
+		return nil, err
+	}
+
+	return infos[0], nil
+}
+
+// Balance returns the balance of an address.
+func (s *Source) Balance(ctx context.Context,
+	addr btcutil.Address) (btcutil.Amount, error) {
+
+	scriptHash, err := addressScriptHash(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance *Balance
+	err = s.do(ctx, func(c *Client) error {
+		var err error
+		balance, err = c.GetBalance(ctx, scriptHash)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return btcutil.Amount(balance.Total()), nil
+}
+
+// History returns the transactions involving an address.
+func (s *Source) History(ctx context.Context,
+	addr btcutil.Address) ([]blockchain.Tx, error) {
+
+	scriptHash, err := addressScriptHash(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []HistoryItem
+	err = s.do(ctx, func(c *Client) error {
+		var err error
+		items, err = c.GetHistory(ctx, scriptHash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]blockchain.Tx, 0, len(items))
+	for _, item := range items {
+		txid, err := chainhash.NewHashFromStr(item.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", item.TxHash,
+				err)
+		}
+		history = append(history, blockchain.Tx{
+			TxID:   *txid,
+			Height: item.Height,
+		})
+	}
+
+	return history, nil
+}
+
+// UTXOs returns the unspent outputs of an address.
+func (s *Source) UTXOs(ctx context.Context,
+	addr btcutil.Address) ([]blockchain.UTXO, error) {
+
+	scriptHash, err := addressScriptHash(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var unspent []Unspent
+	err = s.do(ctx, func(c *Client) error {
+		var err error
+		unspent, err = c.ListUnspent(ctx, scriptHash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]blockchain.UTXO, 0, len(unspent))
+	for _, u := range unspent {
+		txid, err := chainhash.NewHashFromStr(u.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", u.TxHash, err)
+		}
+		utxos = append(utxos, blockchain.UTXO{
+			OutPoint: wire.OutPoint{Hash: *txid, Index: u.TxPos},
+			Value:    btcutil.Amount(u.Value),
+			Height:   u.Height,
+		})
+	}
+
+	return utxos, nil
+}
+
+// Transaction returns a transaction by its ID.
+func (s *Source) Transaction(ctx context.Context,
+	txid *chainhash.Hash) (*wire.MsgTx, error) {
+
+	var rawTx string
+	err := s.do(ctx, func(c *Client) error {
+		var err error
+		rawTx, err = c.GetTransaction(ctx, txid.String())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := blockchain.DecodeTx(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.TxHash() != *txid {
+		return nil, fmt.Errorf("server returned transaction %v instead "+
+			"of %v", tx.TxHash(), txid)
+	}
+
+	return tx, nil
+}
+
+// Broadcast publishes a transaction.
+func (s *Source) Broadcast(ctx context.Context,
+	tx *wire.MsgTx) (*chainhash.Hash, error) {
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	var txid string
+	err := s.do(ctx, func(c *Client) error {
+		var err error
+		txid, err = c.BroadcastTransaction(
+			ctx, hex.EncodeToString(buf.Bytes()),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(txid)
+}
+
+// Close closes the connection to the server.
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+
+	return err
+}
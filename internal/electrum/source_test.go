@@ -0,0 +1,142 @@
+package electrum
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func testAddress(t *testing.T, b byte) (btcutil.Address, string) {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		append(make([]byte, 19), b), &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	return addr, ScriptHash(pkScript)
+}
+
+// TestSource checks the blockchain source operations against the fake server.
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	used, usedHash := testAddress(t, 1)
+	unused, _ := testAddress(t, 2)
+
+	server := newFakeServer(t, false)
+	server.history[usedHash] = []HistoryItem{
+		{TxHash: fakeTxID, Height: 800000},
+		{TxHash: fakeTxID, Height: 0},
+	}
+	server.balances[usedHash] = Balance{Confirmed: 3000, Unconfirmed: 500}
+	server.unspent[usedHash] = []Unspent{
+		{TxHash: fakeTxID, TxPos: 1, Value: 3000, Height: 800000},
+	}
+
+	src := NewSource(
+		&Config{Server: server.addr()},
+		chaincfg.MainNetParams.GenesisHash,
+	)
+	defer src.Close()
+	ctx := context.Background()
+
+	infos, err := src.AddressInfos(ctx, []btcutil.Address{unused, used})
+	require.NoError(t, err)
+	require.False(t, infos[0].Used())
+	require.Equal(t, uint64(2), infos[1].TxCount)
+	require.Equal(t, btcutil.Amount(3500), infos[1].Balance())
+
+	balance, err := src.Balance(ctx, used)
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(3500), balance)
+
+	history, err := src.History(ctx, used)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.True(t, history[0].Confirmed())
+	require.False(t, history[1].Confirmed())
+
+	utxos, err := src.UTXOs(ctx, used)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, fakeTxID, utxos[0].OutPoint.Hash.String())
+	require.Equal(t, uint32(1), utxos[0].OutPoint.Index)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	txid, err := src.Broadcast(ctx, tx)
+	require.NoError(t, err)
+	require.Equal(t, fakeTxID, txid.String())
+
+	var raw strings.Builder
+	require.NoError(t, tx.Serialize(hex.NewEncoder(&raw)))
+	server.mu.Lock()
+	require.Equal(t, []string{raw.String()}, server.broadcasts)
+	server.mu.Unlock()
+
+	// The raw transaction is looked up and checked against its ID.
+	prevTxID := tx.TxHash()
+	server.txs[prevTxID.String()] = raw.String()
+	prevTx, err := src.Transaction(ctx, &prevTxID)
+	require.NoError(t, err)
+	require.Equal(t, prevTxID, prevTx.TxHash())
+
+	server.txs[fakeTxID] = raw.String()
+	fakeHash, err := chainhash.NewHashFromStr(fakeTxID)
+	require.NoError(t, err)
+	_, err = src.Transaction(ctx, fakeHash)
+	require.ErrorContains(t, err, "instead of")
+}
+
+// TestSourceWrongChain checks that servers of another network are rejected.
+func TestSourceWrongChain(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, false)
+	src := NewSource(
+		&Config{Server: server.addr()},
+		chaincfg.TestNet3Params.GenesisHash,
+	)
+	addr, _ := testAddress(t, 1)
+	_, err := src.AddressInfo(context.Background(), addr)
+	require.ErrorContains(t, err, "genesis")
+}
+
+// TestSourceReconnect checks that a broken connection is replaced on the next
+// call.
+func TestSourceReconnect(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeServer(t, false)
+	src := NewSource(
+		&Config{Server: server.addr()},
+		chaincfg.MainNetParams.GenesisHash,
+	)
+	defer src.Close()
+	ctx := context.Background()
+	addr, _ := testAddress(t, 1)
+
+	_, err := src.AddressInfo(ctx, addr)
+	require.NoError(t, err)
+
+	src.mu.Lock()
+	src.client.conn.Close()
+	src.mu.Unlock()
+
+	_, err = src.AddressInfo(ctx, addr)
+	require.Error(t, err)
+	_, err = src.AddressInfo(ctx, addr)
+	require.NoError(t, err)
+}
@@ -0,0 +1,306 @@
+// Package entropy turns randomness gathered by hand, such as dice rolls or coin
+// flips, or entropy generated elsewhere, into the entropy of an aezeed cipher
+// seed, running statistical checks that catch input that is far from random.
+package entropy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"aezeed_address_generator_gui/internal/crypto"
+)
+
+const (
+	// MinBits is the entropy an input must carry to fill a cipher seed.
+	MinBits = crypto.EntropySize * 8
+
+	// checkAlpha is the probability with which a truly random input fails
+	// a check.
+	checkAlpha = 0.001
+)
+
+// Kind is the kind of randomness an input holds.
+type Kind int
+
+const (
+	// Hex is entropy written in hexadecimal. Exactly EntropySize bytes are
+	// used as they are, longer entropy is hashed.
+	Hex Kind = iota
+
+	// Dice are rolls of a six-sided die, written as the digits 1 to 6.
+	Dice
+
+	// Coins are coin flips, written as 0 and 1 or as H and T.
+	Coins
+)
+
+// alphabet returns the symbols of an input of the kind, in the order of their
+// values.
+func (k Kind) alphabet() string {
+	switch k {
+	case Hex:
+		return "0123456789abcdef"
+
+	case Dice:
+		return "123456"
+
+	default:
+		return "01"
+	}
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Hex:
+		return "hex"
+
+	case Dice:
+		return "dice"
+
+	case Coins:
+		return "coins"
+
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// ErrInsufficientEntropy is returned when an input carries less than MinBits
+// of entropy.
+var ErrInsufficientEntropy = errors.New("not enough entropy for a cipher seed")
+
+// ErrOddHexLength is returned when hexadecimal entropy doesn't have a whole
+// number of bytes.
+var ErrOddHexLength = errors.New("hex entropy has an odd number of digits")
+
+// InvalidSymbolError is returned when an input has a character that isn't a
+// symbol of its kind.
+type InvalidSymbolError struct {
+	// Symbol is the invalid character.
+	Symbol rune
+
+	// Position is the position (starting from one) of the character in
+	// the input.
+	Position int
+}
+
+// Error returns a human-readable string describing the error.
+func (e InvalidSymbolError) Error() string {
+	return fmt.Sprintf("invalid symbol %q at position %d", e.Symbol,
+		e.Position)
+}
+
+// CheckName identifies a statistical check.
+type CheckName string
+
+const (
+	// CheckDistribution is a chi-squared test of how evenly the symbols
+	// occur. Its value is the chi-squared statistic.
+	CheckDistribution CheckName = "distribution"
+
+	// CheckRuns checks the longest run of a repeated symbol. Its value is
+	// the length of the run.
+	CheckRuns CheckName = "runs"
+)
+
+// Check is the outcome of a statistical check of an input. A check passes
+// when its value doesn't exceed its limit, which a truly random input exceeds
+// with a probability of 0.1%.
+type Check struct {
+	Name   CheckName
+	Value  float64
+	Limit  float64
+	Passed bool
+}
+
+// Result is the entropy of an input along with what was checked about it.
+type Result struct {
+	// Entropy is the entropy of the cipher seed.
+	Entropy [crypto.EntropySize]byte
+
+	// Symbols is the number of symbols of the input, and Bits the
+	// entropy they carry if they are truly random.
+	Symbols int
+	Bits    float64
+
+	// Hashed reports whether the input was hashed with SHA-256 into the
+	// entropy rather than used as it is.
+	Hashed bool
+
+	// Checks are the statistical checks of the input.
+	Checks []Check
+}
+
+// Passed reports whether every check passed.
+func (r *Result) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse turns an input of the given kind into the entropy of a cipher seed.
+// Whitespace and the separators ",", "-" and "." are ignored, as is a leading
+// "0x" of hexadecimal entropy. Dice rolls and coin flips are hashed, the way
+// hardware wallets do, so their bias is spread over the whole entropy.
+func Parse(kind Kind, input string) (*Result, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if kind == Hex {
+		input = strings.TrimPrefix(input, "0x")
+	}
+
+	values, err := symbols(kind, input)
+	if err != nil {
+		return nil, err
+	}
+
+	sides := len(kind.alphabet())
+	result := &Result{
+		Symbols: len(values),
+		Bits:    float64(len(values)) * math.Log2(float64(sides)),
+		Checks: []Check{
+			distributionCheck(values, sides),
+			runsCheck(values, sides),
+		},
+	}
+	if result.Bits < MinBits {
+		return nil, fmt.Errorf("%w: got %.1f bits, need %d",
+			ErrInsufficientEntropy, result.Bits, MinBits)
+	}
+
+	var normalized strings.Builder
+	for _, v := range values {
+		normalized.WriteByte(kind.alphabet()[v])
+	}
+
+	switch {
+	case kind != Hex:
+		hash := sha256.Sum256([]byte(normalized.String()))
+		copy(result.Entropy[:], hash[:])
+		result.Hashed = true
+
+	case len(values)%2 != 0:
+		return nil, ErrOddHexLength
+
+	default:
+		entropy, err := hex.DecodeString(normalized.String())
+		if err != nil {
+			return nil, err
+		}
+		if len(entropy) > crypto.EntropySize {
+			hash := sha256.Sum256(entropy)
+			entropy = hash[:]
+			result.Hashed = true
+		}
+		copy(result.Entropy[:], entropy)
+	}
+
+	return result, nil
+}
+
+// symbols returns the values of the symbols of an input.
+func symbols(kind Kind, input string) ([]int, error) {
+	var values []int
+	for i, r := range []rune(input) {
+		if unicode.IsSpace(r) || strings.ContainsRune(",-.", r) {
+			continue
+		}
+		if kind == Coins {
+			switch r {
+			case 'h':
+				r = '1'
+
+			case 't':
+				r = '0'
+			}
+		}
+
+		v := strings.IndexRune(kind.alphabet(), r)
+		if v < 0 {
+			return nil, InvalidSymbolError{Symbol: r, Position: i + 1}
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// distributionCheck runs a chi-squared test of the counts of each symbol
+// against those of a uniform distribution.
+func distributionCheck(values []int, sides int) Check {
+	counts := make([]int, sides)
+	for _, v := range values {
+		counts[v]++
+	}
+
+	expected := float64(len(values)) / float64(sides)
+	var chi2 float64
+	for _, count := range counts {
+		diff := float64(count) - expected
+		chi2 += diff * diff / expected
+	}
+
+	limit := chiSquaredLimit(sides - 1)
+	return Check{
+		Name:   CheckDistribution,
+		Value:  chi2,
+		Limit:  limit,
+		Passed: chi2 <= limit,
+	}
+}
+
+// chiSquaredLimit returns the value the chi-squared statistic with the given
+// degrees of freedom exceeds with a probability of checkAlpha.
+func chiSquaredLimit(degrees int) float64 {
+	switch degrees {
+	case 1:
+		return 10.828
+
+	case 5:
+		return 20.515
+
+	default:
+		return 37.697
+	}
+}
+
+// runsCheck finds the longest run of a repeated symbol. Its limit is the
+// longest run expected at least once in 1/checkAlpha random inputs of the same
+// length.
+func runsCheck(values []int, sides int) Check {
+	longest, run := 0, 0
+	for i, v := range values {
+		if i > 0 && v == values[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	// A run of at least k symbols starts at a given symbol with a
+	// probability of about (1-p)·p^(k-1), where p is the probability of
+	// repeating the previous symbol.
+	p := 1 / float64(sides)
+	expected := float64(len(values)) * (1 - p)
+	limit := 1
+	for expected*math.Pow(p, float64(limit)) >= checkAlpha {
+		limit++
+	}
+
+	return Check{
+		Name:   CheckRuns,
+		Value:  float64(longest),
+		Limit:  float64(limit),
+		Passed: longest <= limit,
+	}
+}
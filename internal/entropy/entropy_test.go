@@ -0,0 +1,112 @@
+package entropy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseHex checks that hex entropy of a cipher seed's size is used as it
+// is and that longer entropy is hashed.
+func TestParseHex(t *testing.T) {
+	t.Parallel()
+
+	result, err := Parse(Hex, " 0x81b637d8 fcd2c6da-6359e6963113a117 ")
+	require.NoError(t, err)
+	require.False(t, result.Hashed)
+	require.Equal(t, 32, result.Symbols)
+	require.Equal(t, float64(MinBits), result.Bits)
+	require.Equal(t, "81b637d8fcd2c6da6359e6963113a117",
+		hex.EncodeToString(result.Entropy[:]))
+	require.True(t, result.Passed())
+
+	long := "9e885d952ad362caeb4efe34a8e91bd2" +
+		"a33b3f69b50c0c2fa7aee7d1c2f1a93b"
+	result, err = Parse(Hex, strings.ToUpper(long))
+	require.NoError(t, err)
+	require.True(t, result.Hashed)
+
+	raw, _ := hex.DecodeString(long)
+	hash := sha256.Sum256(raw)
+	require.Equal(t, hash[:16], result.Entropy[:])
+
+	_, err = Parse(Hex, "81b637d8fcd2c6da6359e6963113a1")
+	require.ErrorIs(t, err, ErrInsufficientEntropy)
+
+	_, err = Parse(Hex, "81b637d8fcd2c6da6359e6963113a117f")
+	require.ErrorIs(t, err, ErrOddHexLength)
+}
+
+// TestParseDice checks that dice rolls are hashed as written and that fewer
+// rolls than needed for a cipher seed are refused.
+func TestParseDice(t *testing.T) {
+	t.Parallel()
+
+	rolls := "35214 62531 44625 13266 15342 " +
+		"52146 31652 42351 64213 25416"
+	result, err := Parse(Dice, rolls)
+	require.NoError(t, err)
+	require.True(t, result.Hashed)
+	require.Equal(t, 50, result.Symbols)
+	require.Greater(t, result.Bits, float64(MinBits))
+	require.True(t, result.Passed())
+
+	hash := sha256.Sum256([]byte(strings.ReplaceAll(rolls, " ", "")))
+	require.Equal(t, hash[:16], result.Entropy[:])
+
+	_, err = Parse(Dice, rolls[:len(rolls)-1])
+	require.ErrorIs(t, err, ErrInsufficientEntropy)
+
+	_, err = Parse(Dice, "1234567")
+	require.Equal(t, InvalidSymbolError{Symbol: '7', Position: 7}, err)
+}
+
+// TestParseCoins checks that coin flips written as H and T are the same as
+// those written as 1 and 0.
+func TestParseCoins(t *testing.T) {
+	t.Parallel()
+
+	flips := strings.Repeat("1101001110010110", 8)
+	result, err := Parse(Coins, flips)
+	require.NoError(t, err)
+	require.Equal(t, float64(MinBits), result.Bits)
+
+	letters := strings.NewReplacer("1", "H", "0", "t").Replace(flips)
+	other, err := Parse(Coins, letters)
+	require.NoError(t, err)
+	require.Equal(t, result.Entropy, other.Entropy)
+
+	_, err = Parse(Coins, flips[1:])
+	require.ErrorIs(t, err, ErrInsufficientEntropy)
+}
+
+// TestChecks checks that inputs far from random fail the checks.
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	result, err := Parse(Hex, strings.Repeat("0", 32))
+	require.NoError(t, err)
+	require.False(t, result.Passed())
+	require.Equal(t, []Check{
+		{Name: CheckDistribution, Value: 480, Limit: 37.697},
+		{Name: CheckRuns, Value: 32, Limit: 4},
+	}, result.Checks)
+
+	// Rolls that are half ones are uneven but have no long runs.
+	result, err = Parse(Dice, strings.Repeat("1213141512", 6))
+	require.NoError(t, err)
+	require.False(t, result.Checks[0].Passed)
+	require.True(t, result.Checks[1].Passed)
+	require.Equal(t, float64(7), result.Checks[1].Limit)
+
+	// Flips with a run of 20 heads are even but fail the runs check.
+	flips := strings.Repeat("1", 20) + strings.Repeat("0", 20) +
+		strings.Repeat("10", 44)
+	result, err = Parse(Coins, flips)
+	require.NoError(t, err)
+	require.True(t, result.Checks[0].Passed)
+	require.False(t, result.Checks[1].Passed)
+}
@@ -0,0 +1,43 @@
+// Package fileutil holds file helpers shared by the packages that persist
+// state on disk.
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicWriteFile writes data to path with the given permissions, creating
+// its directory if needed. The data is written to a temporary file in the
+// same directory, synced and renamed over path, so an interruption never
+// leaves the file half written.
+func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,33 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAtomicWriteFile checks that the file and its directory are created,
+// that an existing file is replaced and that no temporary file is left.
+func TestAtomicWriteFile(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "sub")
+	path := filepath.Join(dir, "state.json")
+
+	require.NoError(t, AtomicWriteFile(path, []byte("first"), 0600))
+	require.NoError(t, AtomicWriteFile(path, []byte("second"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,172 @@
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/crypto"
+)
+
+// MaxWindow is the largest number of words RecoverOrder permutes, which
+// bounds a search to 10! = 3628800 candidates.
+const MaxWindow = 10
+
+// RecoverSwap recovers a mnemonic that has two adjacent words swapped, by
+// swapping back every pair of adjacent words. Every word must be in the word
+// list.
+func RecoverSwap(ctx context.Context, words []string, pass []byte,
+	report func(Progress)) (*Result, error) {
+
+	m, err := knownMnemonic(words)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []int
+	for i := 0; i < crypto.NumMnemonicWords-1; i++ {
+		if m[i] != m[i+1] {
+			pairs = append(pairs, i)
+		}
+	}
+
+	return search(ctx, len(pairs), pass, report, func(emit func(Candidate) bool) {
+		for _, i := range pairs {
+			swapped := m
+			swapped[i], swapped[i+1] = m[i+1], m[i]
+			c := Candidate{
+				Mnemonic: swapped,
+				Position: i,
+				Span:     2,
+				Original: m[i] + " " + m[i+1],
+				Word:     m[i+1] + " " + m[i],
+				Distance: 2,
+			}
+			if !emit(c) {
+				return
+			}
+		}
+	})
+}
+
+// RecoverOrder recovers a mnemonic whose words lost their order within a
+// window of size words starting at index start, such as a row of the sheet
+// it was written on, by trying every distinct order of those words. Every
+// word must be in the word list, and the window can't be larger than
+// MaxWindow.
+func RecoverOrder(ctx context.Context, words []string, pass []byte, start,
+	size int, report func(Progress)) (*Result, error) {
+
+	m, err := knownMnemonic(words)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case size < 2 || size > MaxWindow:
+		return nil, fmt.Errorf("window must have between 2 and %d words, "+
+			"got %d", MaxWindow, size)
+
+	case start < 0 || start+size > crypto.NumMnemonicWords:
+		return nil, fmt.Errorf("window of %d words at index %d is "+
+			"outside the mnemonic", size, start)
+	}
+
+	original := append([]string(nil), m[start:start+size]...)
+	window := append([]string(nil), original...)
+	sort.Strings(window)
+
+	// Every order but the one given is a candidate.
+	total := permutations(window) - 1
+
+	return search(ctx, total, pass, report, func(emit func(Candidate) bool) {
+		for ok := true; ok; ok = nextPermutation(window) {
+			moved := 0
+			for i, word := range window {
+				if word != original[i] {
+					moved++
+				}
+			}
+			if moved == 0 {
+				continue
+			}
+
+			candidate := m
+			copy(candidate[start:], window)
+			c := Candidate{
+				Mnemonic: candidate,
+				Position: start,
+				Span:     size,
+				Original: strings.Join(original, " "),
+				Word:     strings.Join(window, " "),
+				Distance: moved,
+			}
+			if !emit(c) {
+				return
+			}
+		}
+	})
+}
+
+// knownMnemonic returns the mnemonic of 24 words that are all in the word
+// list, failing with ErrChecksumValid if its checksum is already valid.
+func knownMnemonic(words []string) (crypto.Mnemonic, error) {
+	var m crypto.Mnemonic
+	words = normalize(words)
+	if len(words) != crypto.NumMnemonicWords {
+		return m, fmt.Errorf("expected %d words, got %d",
+			crypto.NumMnemonicWords, len(words))
+	}
+	for i, word := range words {
+		if !knownWord(word) {
+			return m, crypto.ErrUnknownMnemonicWord{
+				Word:  word,
+				Index: uint8(i),
+			}
+		}
+	}
+
+	copy(m[:], words)
+	if m.ChecksumValid() {
+		return m, ErrChecksumValid
+	}
+
+	return m, nil
+}
+
+// permutations returns the number of distinct orders of the words, some of
+// which may be repeated.
+func permutations(words []string) int {
+	count := 1
+	repeats := make(map[string]int)
+	for i, word := range words {
+		repeats[word]++
+		count = count * (i + 1) / repeats[word]
+	}
+
+	return count
+}
+
+// nextPermutation rearranges the words into the next order in lexicographic
+// sequence, returning false once the last order was reached. Starting from
+// sorted words, it visits every distinct order exactly once.
+func nextPermutation(words []string) bool {
+	i := len(words) - 2
+	for i >= 0 && words[i] >= words[i+1] {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+
+	j := len(words) - 1
+	for words[j] <= words[i] {
+		j--
+	}
+	words[i], words[j] = words[j], words[i]
+	for l, r := i+1, len(words)-1; l < r; l, r = l+1, r-1 {
+		words[l], words[r] = words[r], words[l]
+	}
+
+	return true
+}
@@ -0,0 +1,122 @@
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecoverSwap checks the recovery of two adjacent words written in
+// swapped order.
+func TestRecoverSwap(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newTestMnemonic(t)
+	words := append([]string(nil), mnemonic[:]...)
+	words[10], words[11] = words[11], words[10]
+
+	var last Progress
+	result, err := RecoverSwap(
+		context.Background(), words, testPass, func(p Progress) {
+			last = p
+		},
+	)
+	require.NoError(t, err)
+	found := requireRecovered(t, result, mnemonic, seed)
+	require.Equal(t, 10, found.Position)
+	require.Equal(t, 2, found.Span)
+	require.Equal(t, words[10]+" "+words[11], found.Original)
+	require.Equal(t, last.Total, last.Checked)
+}
+
+// TestRecoverOrder checks the recovery of a window of words written in the
+// wrong order.
+func TestRecoverOrder(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newTestMnemonic(t)
+	words := append([]string(nil), mnemonic[:]...)
+	for i := 0; i < 3; i++ {
+		words[12+i], words[17-i] = words[17-i], words[12+i]
+	}
+
+	var last Progress
+	result, err := RecoverOrder(
+		context.Background(), words, testPass, 12, 6, func(p Progress) {
+			last = p
+		},
+	)
+	require.NoError(t, err)
+	found := requireRecovered(t, result, mnemonic, seed)
+	require.Equal(t, 12, found.Position)
+	require.Equal(t, 6, found.Span)
+	require.Equal(t, permutations(words[12:18])-1, result.Checked)
+	require.Equal(t, last.Total, last.Checked)
+	require.Equal(t, result.ChecksumMatches, last.Deciphered)
+}
+
+// TestRecoverOrderErrors checks the mnemonics and windows that can't be
+// recovered.
+func TestRecoverOrderErrors(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, _ := newTestMnemonic(t)
+	ctx := context.Background()
+
+	_, err := RecoverSwap(ctx, mnemonic[:], testPass, nil)
+	require.ErrorIs(t, err, ErrChecksumValid)
+
+	words := append([]string(nil), mnemonic[:]...)
+	words[0], words[1] = words[1], words[0]
+	if words[0] == words[1] {
+		words[0], words[2] = words[2], words[0]
+	}
+
+	_, err = RecoverSwap(ctx, words[1:], testPass, nil)
+	require.ErrorContains(t, err, "got 23")
+
+	_, err = RecoverOrder(ctx, words, testPass, 0, 1, nil)
+	require.ErrorContains(t, err, "between 2")
+
+	_, err = RecoverOrder(ctx, words, testPass, 0, MaxWindow+1, nil)
+	require.ErrorContains(t, err, "between 2")
+
+	_, err = RecoverOrder(ctx, words, testPass, 20, 5, nil)
+	require.ErrorContains(t, err, "outside")
+
+	unknown := append([]string(nil), words...)
+	unknown[4] = "notaword"
+	_, err = RecoverOrder(ctx, unknown, testPass, 0, 4, nil)
+	wordErr := &crypto.ErrUnknownMnemonicWord{}
+	require.ErrorAs(t, err, wordErr)
+	require.Equal(t, uint8(4), wordErr.Index)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = RecoverOrder(canceled, words, testPass, 0, MaxWindow, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestNextPermutation checks that every distinct order of words with repeats
+// is visited once.
+func TestNextPermutation(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"a", "a", "b", "c"}
+	seen := make(map[[4]string]bool)
+	for ok := true; ok; ok = nextPermutation(words) {
+		var order [4]string
+		copy(order[:], words)
+		require.False(t, seen[order], "%v visited twice", order)
+		seen[order] = true
+	}
+
+	require.Len(t, seen, 12)
+	require.Equal(t, 12, permutations(words))
+	require.Equal(t, 3628800, permutations([]string{
+		"a", "b", "c", "d", "e", "f", "g", "h", "i", "j",
+	}))
+}
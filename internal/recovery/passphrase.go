@@ -0,0 +1,411 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"aezeed_address_generator_gui/internal/crypto"
+)
+
+const (
+	// MaxTailLen is the longest tail of digits or suffix characters a
+	// passphrase search appends to its candidates.
+	MaxTailLen = 8
+
+	// digitChars are the characters of appended numbers.
+	digitChars = "0123456789"
+)
+
+// PassphraseConfig describes the candidates of a passphrase search. Each
+// candidate is the Prefix, a variant of one of the Words, and a tail. They are
+// tried in order of likelihood: shorter tails first, the word as given before
+// its capitalization variants, and the words in the order given.
+type PassphraseConfig struct {
+	// Words are the passphrases to try, or the parts of them that are
+	// known, most likely first. Without words, the Prefix is tried alone.
+	Words []string
+
+	// Prefix is the known start of the passphrase, prepended to every
+	// candidate.
+	Prefix string
+
+	// Capitalize also tries each word in lower case, in upper case and
+	// with only its first letter in upper case.
+	Capitalize bool
+
+	// Digits also tries every number of up to Digits digits, including
+	// leading zeros, appended to each candidate.
+	Digits int
+
+	// SuffixChars and SuffixLen also try every unknown suffix of up to
+	// SuffixLen characters of SuffixChars appended to each candidate.
+	SuffixChars string
+	SuffixLen   int
+
+	// Skip is the number of candidates a previous run of the same search
+	// already tried, which are skipped to resume it.
+	Skip int
+
+	// Progress, if set, is called from one goroutine at a time as
+	// candidates are tried.
+	Progress func(PassphraseProgress)
+}
+
+// PassphraseProgress reports how far a passphrase search went.
+type PassphraseProgress struct {
+	// Tried is the number of candidates tried, counting the skipped ones,
+	// out of Total.
+	Tried int
+	Total int
+
+	// Checkpoint is the number of leading candidates that were all tried,
+	// which a resumed search can skip.
+	Checkpoint int
+
+	// Elapsed is the time the search has been running, and Remaining the
+	// time it is expected to take to try the other candidates, measured
+	// from the cost of those tried so far. Remaining is zero until the
+	// first candidate is tried.
+	Elapsed   time.Duration
+	Remaining time.Duration
+}
+
+// PassphraseResult is the outcome of a passphrase search.
+type PassphraseResult struct {
+	// Found reports whether a passphrase deciphered the mnemonic, in which
+	// case it is Passphrase and Seed is the cipher seed.
+	Found      bool
+	Passphrase string
+	Seed       *crypto.CipherSeed
+
+	// Tried, Total and Checkpoint are as in PassphraseProgress.
+	Tried      int
+	Total      int
+	Checkpoint int
+}
+
+// passJob is a candidate handed to a worker, with its index in the order of
+// the search.
+type passJob struct {
+	index int
+	pass  string
+}
+
+// RecoverPassphrase searches for the passphrase of a mnemonic among the
+// candidates described by cfg, on all CPU cores. Since every candidate costs a
+// scrypt key derivation, the mnemonic's checksum must already be valid. A
+// canceled search returns how far it went, along with the context's error,
+// and can be resumed by setting Skip to the result's Checkpoint.
+func RecoverPassphrase(ctx context.Context, m crypto.Mnemonic,
+	cfg *PassphraseConfig) (*PassphraseResult, error) {
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if !m.ChecksumValid() {
+		return nil, crypto.ErrIncorrectMnemonic
+	}
+
+	variants := cfg.variants()
+	total := cfg.tailCount() * len(variants)
+	result := &PassphraseResult{
+		Total:      total,
+		Tried:      min(cfg.Skip, total),
+		Checkpoint: min(cfg.Skip, total),
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		start    = time.Now()
+		tried    int
+		done     = make(map[int]bool)
+		firstErr error
+	)
+
+	// finish records a tried candidate, advancing the checkpoint past
+	// every leading candidate that was tried.
+	finish := func(job passJob, seed *crypto.CipherSeed, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		tried++
+		result.Tried++
+		done[job.index] = true
+		for done[result.Checkpoint] {
+			delete(done, result.Checkpoint)
+			result.Checkpoint++
+		}
+
+		switch {
+		case errors.Is(err, crypto.ErrInvalidPass):
+
+		case err != nil:
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unable to decipher "+
+					"mnemonic: %w", err)
+				cancel()
+			}
+
+		case !result.Found:
+			result.Found = true
+			result.Passphrase = job.pass
+			result.Seed = seed
+			cancel()
+		}
+
+		if cfg.Progress != nil {
+			elapsed := time.Since(start)
+			left := total - result.Tried
+			cfg.Progress(PassphraseProgress{
+				Tried:      result.Tried,
+				Total:      total,
+				Checkpoint: result.Checkpoint,
+				Elapsed:    elapsed,
+				Remaining:  elapsed / time.Duration(tried) * time.Duration(left),
+			})
+		}
+	}
+
+	jobs := make(chan passJob)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				seed, err := m.ToCipherSeed([]byte(job.pass))
+				finish(job, seed, err)
+			}
+		}()
+	}
+
+	index := 0
+	cfg.tails(func(tail string) bool {
+		for _, variant := range variants {
+			if index < cfg.Skip {
+				index++
+				continue
+			}
+
+			job := passJob{index: index, pass: variant + tail}
+			select {
+			case jobs <- job:
+				index++
+
+			case <-searchCtx.Done():
+				return false
+			}
+		}
+
+		return true
+	})
+	close(jobs)
+	wg.Wait()
+
+	switch {
+	case result.Found:
+		return result, nil
+
+	case firstErr != nil:
+		return result, firstErr
+	}
+
+	return result, ctx.Err()
+}
+
+// validate checks that the candidates of the configuration are well defined.
+func (c *PassphraseConfig) validate() error {
+	switch {
+	case c.Digits < 0 || c.Digits > MaxTailLen:
+		return fmt.Errorf("digits must be between 0 and %d", MaxTailLen)
+
+	case c.SuffixLen < 0 || c.SuffixLen > MaxTailLen:
+		return fmt.Errorf("suffix length must be between 0 and %d",
+			MaxTailLen)
+
+	case c.SuffixLen > 0 && c.SuffixChars == "":
+		return errors.New("suffix characters are required for an " +
+			"unknown suffix")
+
+	case c.Skip < 0:
+		return errors.New("skipped candidates can't be negative")
+	}
+
+	return nil
+}
+
+// variants returns the prefixed words and their capitalization variants, in
+// the order they are tried. Variant i of every word comes before variant i+1
+// of any word, and variants equal to an earlier one are left out.
+func (c *PassphraseConfig) variants() []string {
+	words := c.Words
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	perWord := make([][]string, len(words))
+	rounds := 0
+	for i, word := range words {
+		forms := []string{word}
+		if c.Capitalize {
+			forms = append(forms, strings.ToLower(word),
+				strings.ToUpper(word), title(word))
+		}
+
+		seen := make(map[string]bool)
+		for _, form := range forms {
+			if !seen[form] {
+				seen[form] = true
+				perWord[i] = append(perWord[i], form)
+			}
+		}
+		rounds = max(rounds, len(perWord[i]))
+	}
+
+	var variants []string
+	for round := 0; round < rounds; round++ {
+		for _, forms := range perWord {
+			if round < len(forms) {
+				variants = append(variants, c.Prefix+forms[round])
+			}
+		}
+	}
+
+	return variants
+}
+
+// title returns the word in lower case with its first letter in upper case.
+func title(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if size == 0 {
+		return word
+	}
+
+	return string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+}
+
+// suffixChars returns the distinct characters of unknown suffixes.
+func (c *PassphraseConfig) suffixChars() []rune {
+	var chars []rune
+	seen := make(map[rune]bool)
+	for _, r := range c.SuffixChars {
+		if !seen[r] {
+			seen[r] = true
+			chars = append(chars, r)
+		}
+	}
+
+	return chars
+}
+
+// tails calls yield with every tail appended to the candidates, shortest
+// first, until it returns false: no tail, then the numbers, then the unknown
+// suffixes that aren't numbers already tried.
+func (c *PassphraseConfig) tails(yield func(string) bool) {
+	if !yield("") {
+		return
+	}
+
+	digits := []rune(digitChars)
+	for length := 1; length <= c.Digits; length++ {
+		if !odometer(digits, length, yield) {
+			return
+		}
+	}
+
+	chars := c.suffixChars()
+	for length := 1; length <= c.SuffixLen; length++ {
+		more := odometer(chars, length, func(tail string) bool {
+			if length <= c.Digits && isNumber(tail) {
+				return true
+			}
+			return yield(tail)
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// tailCount returns the number of tails yielded by tails.
+func (c *PassphraseConfig) tailCount() int {
+	count := 1
+	for length := 1; length <= c.Digits; length++ {
+		count += pow(len(digitChars), length)
+	}
+
+	chars := c.suffixChars()
+	numbers := 0
+	for _, r := range chars {
+		if strings.ContainsRune(digitChars, r) {
+			numbers++
+		}
+	}
+	for length := 1; length <= c.SuffixLen; length++ {
+		count += pow(len(chars), length)
+		if length <= c.Digits {
+			count -= pow(numbers, length)
+		}
+	}
+
+	return count
+}
+
+// odometer calls yield with every string of length characters of chars, in
+// order, until it returns false, which it reports.
+func odometer(chars []rune, length int, yield func(string) bool) bool {
+	digits := make([]int, length)
+	tail := make([]rune, length)
+	for {
+		for i, d := range digits {
+			tail[i] = chars[d]
+		}
+		if !yield(string(tail)) {
+			return false
+		}
+
+		i := length - 1
+		for ; i >= 0; i-- {
+			digits[i]++
+			if digits[i] < len(chars) {
+				break
+			}
+			digits[i] = 0
+		}
+		if i < 0 {
+			return true
+		}
+	}
+}
+
+// isNumber reports whether a tail has only digits.
+func isNumber(tail string) bool {
+	for _, r := range tail {
+		if !strings.ContainsRune(digitChars, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pow returns base raised to exp.
+func pow(base, exp int) int {
+	result := 1
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+
+	return result
+}
@@ -0,0 +1,203 @@
+package recovery
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newPassMnemonic returns a new mnemonic enciphered with pass, and its cipher
+// seed.
+func newPassMnemonic(t *testing.T, pass string) (crypto.Mnemonic,
+	*crypto.CipherSeed) {
+
+	t.Helper()
+
+	entropy := [crypto.EntropySize]byte{8, 7, 6, 5, 4, 3, 2, 1}
+	seed, err := crypto.New(0, &entropy, time.Now())
+	require.NoError(t, err)
+	mnemonic, err := seed.ToMnemonic([]byte(pass))
+	require.NoError(t, err)
+
+	return mnemonic, seed
+}
+
+// candidates returns every candidate of a configuration, in order.
+func candidates(cfg *PassphraseConfig) []string {
+	var all []string
+	variants := cfg.variants()
+	cfg.tails(func(tail string) bool {
+		for _, variant := range variants {
+			all = append(all, variant+tail)
+		}
+		return true
+	})
+
+	return all
+}
+
+// TestPassphraseCandidates checks the order of the candidates and that none
+// is repeated.
+func TestPassphraseCandidates(t *testing.T) {
+	t.Parallel()
+
+	cfg := &PassphraseConfig{
+		Words:       []string{"satoshi", "Bitcoin"},
+		Prefix:      "my",
+		Capitalize:  true,
+		Digits:      1,
+		SuffixChars: "!a1",
+		SuffixLen:   2,
+	}
+	all := candidates(cfg)
+	require.Equal(t, []string{
+		"mysatoshi", "myBitcoin", "mySATOSHI", "mybitcoin",
+		"mySatoshi", "myBITCOIN",
+		"mysatoshi0", "myBitcoin0",
+	}, all[:8])
+	require.Equal(t, "mysatoshi!", all[6*11])
+
+	// 1 + 10 numbers + 3 + 9 suffixes, less the suffix "1" already
+	// tried as a number.
+	require.Equal(t, 22, cfg.tailCount())
+	require.Len(t, all, cfg.tailCount()*6)
+
+	seen := make(map[string]bool)
+	for _, c := range all {
+		require.False(t, seen[c], "%s repeated", c)
+		seen[c] = true
+	}
+
+	// Without words, the prefix is tried with every tail.
+	cfg = &PassphraseConfig{Prefix: "pin", Digits: 2}
+	all = candidates(cfg)
+	require.Len(t, all, 111)
+	require.Equal(t, []string{"pin", "pin0"}, all[:2])
+	require.Equal(t, "pin99", all[110])
+}
+
+// TestRecoverPassphrase checks that a passphrase is found among the variants
+// of a word list.
+func TestRecoverPassphrase(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newPassMnemonic(t, "Hunter4")
+
+	var last PassphraseProgress
+	result, err := RecoverPassphrase(
+		context.Background(), mnemonic, &PassphraseConfig{
+			Words:      []string{"password", "hunter"},
+			Capitalize: true,
+			Digits:     1,
+			Progress: func(p PassphraseProgress) {
+				last = p
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.True(t, result.Found)
+	require.Equal(t, "Hunter4", result.Passphrase)
+	require.Equal(t, seed.Entropy, result.Seed.Entropy)
+	require.Equal(t, 66, result.Total)
+	require.Equal(t, 66, last.Total)
+	require.Positive(t, last.Elapsed)
+}
+
+// TestRecoverPassphraseResume checks that a canceled search resumes from its
+// checkpoint, and that the candidates before it are skipped.
+func TestRecoverPassphraseResume(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, _ := newPassMnemonic(t, "pin7")
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := &PassphraseConfig{
+		Prefix: "pin",
+		Digits: 1,
+		Progress: func(p PassphraseProgress) {
+			require.Positive(t, p.Remaining+p.Elapsed)
+			if p.Tried >= 3 {
+				cancel()
+			}
+		},
+	}
+	result, err := RecoverPassphrase(ctx, mnemonic, cfg)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, result.Found)
+	require.GreaterOrEqual(t, result.Checkpoint, 1)
+	require.LessOrEqual(t, result.Checkpoint, result.Tried)
+
+	cfg.Skip = result.Checkpoint
+	cfg.Progress = nil
+	result, err = RecoverPassphrase(context.Background(), mnemonic, cfg)
+	require.NoError(t, err)
+	require.True(t, result.Found)
+	require.Equal(t, "pin7", result.Passphrase)
+
+	// Skipping past the passphrase exhausts the search.
+	cfg.Skip = 9
+	result, err = RecoverPassphrase(context.Background(), mnemonic, cfg)
+	require.NoError(t, err)
+	require.False(t, result.Found)
+	require.Equal(t, result.Total, result.Tried)
+	require.Equal(t, result.Total, result.Checkpoint)
+}
+
+// TestRecoverPassphraseErrors checks the searches that can't run.
+func TestRecoverPassphraseErrors(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, _ := newPassMnemonic(t, "x")
+	ctx := context.Background()
+
+	_, err := RecoverPassphrase(ctx, mnemonic, &PassphraseConfig{
+		Digits: MaxTailLen + 1,
+	})
+	require.ErrorContains(t, err, "digits")
+
+	_, err = RecoverPassphrase(ctx, mnemonic, &PassphraseConfig{
+		SuffixLen: 2,
+	})
+	require.ErrorContains(t, err, "suffix characters")
+
+	wrong := mnemonic
+	wrong[3], wrong[4] = wrong[4], wrong[3]
+	if wrong == mnemonic {
+		wrong[3] = "zoo"
+	}
+	_, err = RecoverPassphrase(ctx, wrong, &PassphraseConfig{})
+	require.ErrorIs(t, err, crypto.ErrIncorrectMnemonic)
+}
+
+// TestPassphraseState checks that a saved state is loaded back, and that the
+// search ID tells searches apart.
+func TestPassphraseState(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sub", "state.json")
+	state, err := LoadPassphraseState(path)
+	require.NoError(t, err)
+	require.Nil(t, state)
+
+	mnemonic, _ := newPassMnemonic(t, "x")
+	cfg := &PassphraseConfig{Words: []string{"a", "b"}, Digits: 2}
+	state = &PassphraseState{
+		Search:     cfg.SearchID(mnemonic),
+		Checkpoint: 42,
+		Total:      333,
+	}
+	require.NoError(t, state.Save(path))
+	loaded, err := LoadPassphraseState(path)
+	require.NoError(t, err)
+	require.Equal(t, state, loaded)
+
+	// Skip and Progress don't change the search, its candidates do.
+	cfg.Skip = 42
+	require.Equal(t, state.Search, cfg.SearchID(mnemonic))
+	cfg.Words = []string{"ab"}
+	require.NotEqual(t, state.Search, cfg.SearchID(mnemonic))
+}
@@ -0,0 +1,276 @@
+// Package recovery finds aezeed mnemonics that were written down with errors,
+// by trying the mnemonics close to the one given. The checksum of the
+// mnemonic filters the candidates cheaply, so the expensive deciphering only
+// runs for the few that match it.
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"aezeed_address_generator_gui/internal/crypto"
+)
+
+const (
+	// Unknown is the placeholder of a word of a mnemonic that couldn't be
+	// read.
+	Unknown = "?"
+
+	// batchSize is the number of candidates handed to a worker at once.
+	batchSize = 1024
+)
+
+var (
+	// ErrChecksumValid is returned when the mnemonic to recover already
+	// has a valid checksum, so its words are most likely right and the
+	// passphrase is what's wrong.
+	ErrChecksumValid = errors.New("the mnemonic checksum is valid, " +
+		"check the passphrase")
+
+	// ErrTooManyErrors is returned when a mnemonic has more errors than
+	// the recovery can fix.
+	ErrTooManyErrors = errors.New("too many unknown words to recover")
+)
+
+// Candidate is a mnemonic found by a recovery that deciphers with the
+// passphrase.
+type Candidate struct {
+	// Mnemonic is the recovered mnemonic.
+	Mnemonic crypto.Mnemonic
+
+	// Seed is the cipher seed the mnemonic deciphers to.
+	Seed *crypto.CipherSeed
+
+	// Position is the index of the word that was replaced or inserted, or
+	// of the first of the words that were reordered.
+	Position int
+
+	// Span is the number of words starting at Position that were changed:
+	// one for a replaced or inserted word, more for reordered words.
+	Span int
+
+	// Original is the word that was replaced, empty for a missing word,
+	// or the reordered words as given, separated by spaces.
+	Original string
+
+	// Word is the word found at Position, or the reordered words as found.
+	Word string
+
+	// Distance is the edit distance between Original and Word for a single
+	// word, or the number of words that moved for reordered words.
+	Distance int
+}
+
+// Progress reports how far a recovery went.
+type Progress struct {
+	// Checked is the number of candidate mnemonics whose checksum was
+	// verified, out of Total.
+	Checked int
+	Total   int
+
+	// Deciphered is the number of checksum matches deciphered so far, out
+	// of the Matches found so far.
+	Deciphered int
+	Matches    int
+}
+
+// Result is the outcome of a recovery.
+type Result struct {
+	// Candidates are the mnemonics that decipher with the passphrase,
+	// closest to the original first.
+	Candidates []Candidate
+
+	// Checked is the number of candidate mnemonics tried.
+	Checked int
+
+	// ChecksumMatches is the number of candidates with a valid checksum.
+	// Matches that don't decipher hint at a wrong passphrase.
+	ChecksumMatches int
+}
+
+// normalize lower cases and trims the words of a mnemonic.
+func normalize(words []string) []string {
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = strings.ToLower(strings.TrimSpace(word))
+	}
+
+	return normalized
+}
+
+// knownWord reports whether a word is in the word list.
+func knownWord(word string) bool {
+	_, ok := crypto.ReverseWordMap[word]
+	return ok
+}
+
+// searcher checks candidates on all CPU cores, collecting those that
+// decipher.
+type searcher struct {
+	pass   []byte
+	report func(Progress)
+	cancel func()
+
+	// mu guards the fields below, and serializes the progress reports.
+	mu       sync.Mutex
+	progress Progress
+	seen     map[crypto.Mnemonic]bool
+	found    []Candidate
+	err      error
+}
+
+// search checks the checksum of every candidate produced by generate, on all
+// CPU cores, and deciphers those that match with the passphrase. The
+// generator stops early when emit returns false, and total is the number of
+// candidates it produces. Progress is reported from one goroutine at a time.
+func search(ctx context.Context, total int, pass []byte,
+	report func(Progress), generate func(emit func(Candidate) bool)) (*Result,
+	error) {
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &searcher{
+		pass:     pass,
+		report:   report,
+		cancel:   cancel,
+		progress: Progress{Total: total},
+		seen:     make(map[crypto.Mnemonic]bool),
+	}
+
+	batches := make(chan []Candidate, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				if searchCtx.Err() == nil {
+					s.check(searchCtx, batch)
+				}
+			}
+		}()
+	}
+
+	batch := make([]Candidate, 0, batchSize)
+	send := func() bool {
+		if searchCtx.Err() != nil {
+			return false
+		}
+		select {
+		case batches <- batch:
+			batch = make([]Candidate, 0, batchSize)
+			return true
+
+		case <-searchCtx.Done():
+			return false
+		}
+	}
+	generate(func(c Candidate) bool {
+		batch = append(batch, c)
+		if len(batch) < batchSize {
+			return true
+		}
+		return send()
+	})
+	if len(batch) > 0 {
+		send()
+	}
+	close(batches)
+	wg.Wait()
+
+	sort.Slice(s.found, func(i, j int) bool {
+		a, b := s.found[i], s.found[j]
+		switch {
+		case a.Distance != b.Distance:
+			return a.Distance < b.Distance
+		case a.Position != b.Position:
+			return a.Position < b.Position
+		default:
+			return a.Word < b.Word
+		}
+	})
+	result := &Result{
+		Candidates:      s.found,
+		Checked:         s.progress.Checked,
+		ChecksumMatches: s.progress.Matches,
+	}
+	if s.err != nil {
+		return result, s.err
+	}
+
+	return result, ctx.Err()
+}
+
+// update applies a change to the progress and reports it, with the lock held
+// so reports arrive in order.
+func (s *searcher) update(change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change()
+	if s.report != nil {
+		s.report(s.progress)
+	}
+}
+
+// check filters a batch of candidates by their checksum and deciphers those
+// that match and weren't seen before. An unexpected error cancels the search.
+func (s *searcher) check(ctx context.Context, batch []Candidate) {
+	var matches []Candidate
+	for _, c := range batch {
+		if c.Mnemonic.ChecksumValid() {
+			matches = append(matches, c)
+		}
+	}
+
+	// The same mnemonic may be produced more than once, e.g. by
+	// inserting a word next to an equal one, so matches are deduplicated
+	// before the expensive step.
+	var fresh []Candidate
+	s.update(func() {
+		s.progress.Checked += len(batch)
+		for _, c := range matches {
+			if s.seen[c.Mnemonic] {
+				continue
+			}
+			s.seen[c.Mnemonic] = true
+			fresh = append(fresh, c)
+		}
+		s.progress.Matches += len(fresh)
+	})
+
+	for _, c := range fresh {
+		if ctx.Err() != nil {
+			return
+		}
+
+		seed, err := c.Mnemonic.ToCipherSeed(s.pass)
+		s.update(func() {
+			s.progress.Deciphered++
+			switch {
+			// A wrong candidate may match the checksum by chance, the
+			// MAC of the cipher text tells it apart.
+			case errors.Is(err, crypto.ErrInvalidPass),
+				errors.Is(err, crypto.ErrIncorrectVersion):
+
+			case err != nil:
+				if s.err == nil {
+					s.err = fmt.Errorf("unable to decipher "+
+						"candidate: %w", err)
+					s.cancel()
+				}
+
+			default:
+				c.Seed = seed
+				s.found = append(s.found, c)
+			}
+		})
+	}
+}
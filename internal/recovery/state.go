@@ -0,0 +1,85 @@
+package recovery
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/fileutil"
+)
+
+// PassphraseState is the progress of a passphrase search, saved so an
+// interrupted search can be resumed.
+type PassphraseState struct {
+	// Search identifies the search, see SearchID.
+	Search string `json:"search"`
+
+	// Checkpoint is the number of leading candidates that were all tried.
+	Checkpoint int `json:"checkpoint"`
+
+	// Total is the number of candidates of the search.
+	Total int `json:"total"`
+}
+
+// SearchID identifies a passphrase search of a mnemonic, so its saved state
+// is only used to resume the same search. It is a hash that doesn't reveal
+// the mnemonic or the candidates.
+func (c *PassphraseConfig) SearchID(m crypto.Mnemonic) string {
+	h := sha256.New()
+	writeField := func(s string) {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(s)))
+		h.Write(size[:])
+		h.Write([]byte(s))
+	}
+
+	for _, word := range m {
+		writeField(word)
+	}
+	writeField(c.Prefix)
+	writeField(fmt.Sprintf("%t %d %d", c.Capitalize, c.Digits,
+		c.SuffixLen))
+	writeField(c.SuffixChars)
+	for _, word := range c.Words {
+		writeField(word)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadPassphraseState reads the state saved at path. A missing file yields a
+// nil state.
+func LoadPassphraseState(path string) (*PassphraseState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &PassphraseState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid passphrase search state %s: %w",
+			path, err)
+	}
+
+	return state, nil
+}
+
+// Save writes the state to path, creating its directory if needed. The file
+// is replaced atomically, so an interruption never leaves it half written.
+func (s *PassphraseState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fileutil.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,158 @@
+package recovery
+
+import (
+	"context"
+	"fmt"
+
+	"aezeed_address_generator_gui/internal/crypto"
+)
+
+// wordSlot is a position of the mnemonic where a word is replaced or
+// inserted.
+type wordSlot struct {
+	position int
+	original string
+	insert   bool
+}
+
+// RecoverWord recovers a mnemonic with a single wrong or missing word. The
+// words may have:
+//
+//   - one word that isn't in the word list, either misspelled or Unknown,
+//     which is replaced by every word of the list,
+//   - one word missing, so 23 words are given, and every word of the list is
+//     inserted at every position,
+//   - one wrong word that is in the word list, which is replaced by every
+//     other word of the list at every position.
+//
+// Every candidate whose checksum matches is then deciphered with the
+// passphrase, and those that decipher are returned, the closest in edit
+// distance to the original word first. A canceled recovery returns the
+// candidates found so far along with the context's error.
+func RecoverWord(ctx context.Context, words []string, pass []byte,
+	report func(Progress)) (*Result, error) {
+
+	words = normalize(words)
+	var unknown []int
+	for i, word := range words {
+		if !knownWord(word) {
+			unknown = append(unknown, i)
+		}
+	}
+
+	var slots []wordSlot
+	switch {
+	case len(words) != crypto.NumMnemonicWords &&
+		len(words) != crypto.NumMnemonicWords-1:
+
+		return nil, fmt.Errorf("expected %d or %d words, got %d",
+			crypto.NumMnemonicWords, crypto.NumMnemonicWords-1,
+			len(words))
+
+	case len(unknown) > 1,
+		len(unknown) == 1 && len(words) < crypto.NumMnemonicWords:
+
+		return nil, ErrTooManyErrors
+
+	case len(unknown) == 1:
+		slots = []wordSlot{{
+			position: unknown[0], original: words[unknown[0]],
+		}}
+
+	case len(words) < crypto.NumMnemonicWords:
+		for i := 0; i < crypto.NumMnemonicWords; i++ {
+			slots = append(slots, wordSlot{position: i, insert: true})
+		}
+
+	default:
+		var m crypto.Mnemonic
+		copy(m[:], words)
+		if m.ChecksumValid() {
+			return nil, ErrChecksumValid
+		}
+		for i, word := range words {
+			slots = append(slots, wordSlot{position: i, original: word})
+		}
+	}
+
+	total := 0
+	for _, slot := range slots {
+		total += len(crypto.DefaultWordList)
+		if knownWord(slot.original) {
+			total--
+		}
+	}
+
+	return search(ctx, total, pass, report, func(emit func(Candidate) bool) {
+		for _, slot := range slots {
+			for _, word := range crypto.DefaultWordList {
+				if word == slot.original {
+					continue
+				}
+
+				c := Candidate{
+					Mnemonic: fillSlot(words, slot, word),
+					Position: slot.position,
+					Span:     1,
+					Original: slot.original,
+					Word:     word,
+					Distance: editDistance(slot.original, word),
+				}
+				if !emit(c) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// fillSlot returns the mnemonic with word replaced or inserted at a slot.
+func fillSlot(words []string, slot wordSlot, word string) crypto.Mnemonic {
+	var m crypto.Mnemonic
+	if !slot.insert {
+		copy(m[:], words)
+		m[slot.position] = word
+
+		return m
+	}
+
+	copy(m[:slot.position], words[:slot.position])
+	m[slot.position] = word
+	copy(m[slot.position+1:], words[slot.position:])
+
+	return m
+}
+
+// editDistance returns the optimal string alignment distance between two
+// words: the number of inserted, deleted or substituted letters, or swapped
+// adjacent letters, that turn one into the other.
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(
+				rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost,
+			)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] &&
+				a[i-2] == b[j-1] {
+
+				d = min(d, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = d
+		}
+	}
+
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,181 @@
+package recovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPass = []byte("test")
+
+// newTestMnemonic returns a new mnemonic enciphered with testPass, and its
+// cipher seed.
+func newTestMnemonic(t *testing.T) (crypto.Mnemonic, *crypto.CipherSeed) {
+	t.Helper()
+
+	entropy := [crypto.EntropySize]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	seed, err := crypto.New(0, &entropy, time.Now())
+	require.NoError(t, err)
+	mnemonic, err := seed.ToMnemonic(testPass)
+	require.NoError(t, err)
+
+	return mnemonic, seed
+}
+
+// requireRecovered checks that a recovery found exactly the original
+// mnemonic.
+func requireRecovered(t *testing.T, result *Result, want crypto.Mnemonic,
+	seed *crypto.CipherSeed) *Candidate {
+
+	t.Helper()
+
+	require.Len(t, result.Candidates, 1)
+	found := &result.Candidates[0]
+	require.Equal(t, want, found.Mnemonic)
+	require.Equal(t, seed.Entropy, found.Seed.Entropy)
+	require.Equal(t, seed.Birthday, found.Seed.Birthday)
+
+	return found
+}
+
+// TestRecoverWordMisspelled checks the recovery of a word that isn't in the
+// word list, including the Unknown placeholder.
+func TestRecoverWordMisspelled(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newTestMnemonic(t)
+	for _, position := range []int{0, 11, 23} {
+		words := append([]string(nil), mnemonic[:]...)
+		words[position] += "x"
+
+		result, err := RecoverWord(
+			context.Background(), words, testPass, nil,
+		)
+		require.NoError(t, err)
+		found := requireRecovered(t, result, mnemonic, seed)
+		require.Equal(t, position, found.Position)
+		require.Equal(t, mnemonic[position]+"x", found.Original)
+		require.Equal(t, mnemonic[position], found.Word)
+		require.Equal(t, 1, found.Distance)
+		require.Equal(t, len(crypto.DefaultWordList), result.Checked)
+
+		words[position] = Unknown
+		result, err = RecoverWord(
+			context.Background(), words, testPass, nil,
+		)
+		require.NoError(t, err)
+		requireRecovered(t, result, mnemonic, seed)
+	}
+}
+
+// TestRecoverWordMissing checks the recovery of a mnemonic missing a word.
+func TestRecoverWordMissing(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newTestMnemonic(t)
+	words := append([]string(nil), mnemonic[:7]...)
+	words = append(words, mnemonic[8:]...)
+
+	var last Progress
+	result, err := RecoverWord(
+		context.Background(), words, testPass, func(p Progress) {
+			last = p
+		},
+	)
+	require.NoError(t, err)
+	found := requireRecovered(t, result, mnemonic, seed)
+	require.Empty(t, found.Original)
+	require.Equal(t, mnemonic[7], found.Word)
+	require.Equal(t, last.Total, last.Checked)
+	require.Equal(t, result.ChecksumMatches, last.Deciphered)
+}
+
+// TestRecoverWordWrong checks the recovery of a wrong word that is in the
+// word list, at an unknown position.
+func TestRecoverWordWrong(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, seed := newTestMnemonic(t)
+	words := append([]string(nil), mnemonic[:]...)
+	words[15] = "zoo"
+	if mnemonic[15] == "zoo" {
+		words[15] = "abandon"
+	}
+
+	result, err := RecoverWord(
+		context.Background(), words, testPass, nil,
+	)
+	require.NoError(t, err)
+	found := requireRecovered(t, result, mnemonic, seed)
+	require.Equal(t, 15, found.Position)
+	require.Equal(t,
+		crypto.NumMnemonicWords*(len(crypto.DefaultWordList)-1),
+		result.Checked)
+}
+
+// TestRecoverWordWrongPass checks that candidates matching the checksum are
+// reported even if none deciphers with the passphrase.
+func TestRecoverWordWrongPass(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, _ := newTestMnemonic(t)
+	words := append([]string(nil), mnemonic[:]...)
+	words[3] = Unknown
+
+	result, err := RecoverWord(
+		context.Background(), words, []byte("wrong"), nil,
+	)
+	require.NoError(t, err)
+	require.Empty(t, result.Candidates)
+	require.GreaterOrEqual(t, result.ChecksumMatches, 1)
+}
+
+// TestRecoverWordErrors checks the mnemonics that can't be recovered.
+func TestRecoverWordErrors(t *testing.T) {
+	t.Parallel()
+
+	mnemonic, _ := newTestMnemonic(t)
+	ctx := context.Background()
+
+	_, err := RecoverWord(ctx, mnemonic[:], testPass, nil)
+	require.ErrorIs(t, err, ErrChecksumValid)
+
+	words := append([]string(nil), mnemonic[:]...)
+	words[0], words[1] = Unknown, Unknown
+	_, err = RecoverWord(ctx, words, testPass, nil)
+	require.ErrorIs(t, err, ErrTooManyErrors)
+
+	_, err = RecoverWord(ctx, words[2:], testPass, nil)
+	require.ErrorContains(t, err, "got 22")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = RecoverWord(canceled, mnemonic[1:], testPass, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestEditDistance checks the distance of common transcription errors.
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"absent", "absent", 0},
+		{"absnet", "absent", 1},
+		{"absen", "absent", 1},
+		{"abxsent", "absent", 1},
+		{"obsent", "absent", 1},
+		{"", "zoo", 3},
+		{"ability", "able", 4},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.distance, editDistance(test.a, test.b),
+			"%s -> %s", test.a, test.b)
+	}
+}
@@ -0,0 +1,498 @@
+// Package sweep builds transactions moving all funds of wallet addresses to a
+// single destination, as PSBTs that are either signed with the wallet's keys
+// or left for an external signer.
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// MinFeeRate is the lowest accepted fee rate in sat/vB, the default
+	// minimum relay fee of most nodes.
+	MinFeeRate = 1.0
+
+	// txVersion is the version of sweep transactions.
+	txVersion = 2
+
+	// rbfSequence is the sequence of the inputs. It signals replaceability,
+	// so a sweep stuck with a low fee can be bumped.
+	rbfSequence = wire.MaxTxInSequenceNum - 2
+)
+
+var (
+	// ErrNoInputs is returned when there is nothing to sweep.
+	ErrNoInputs = errors.New("no unspent outputs to sweep")
+
+	// ErrDust is returned when the swept amount doesn't cover the fee
+	// with a relayable output left.
+	ErrDust = errors.New("amount after fee is below the dust limit")
+)
+
+// scriptType is the kind of output script of a wallet address.
+type scriptType uint8
+
+const (
+	p2pkh scriptType = iota
+	p2shP2wpkh
+	p2wpkh
+	p2tr
+)
+
+// String returns the name of the script type.
+func (t scriptType) String() string {
+	switch t {
+	case p2pkh:
+		return "P2PKH"
+	case p2shP2wpkh:
+		return "P2SH-P2WPKH"
+	case p2wpkh:
+		return "P2WPKH"
+	default:
+		return "P2TR"
+	}
+}
+
+// addressScriptType returns the script type of a wallet address. P2SH
+// addresses are assumed to wrap P2WPKH, the only kind a wallet derives.
+func addressScriptType(addr btcutil.Address) (scriptType, error) {
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return p2pkh, nil
+	case *btcutil.AddressScriptHash:
+		return p2shP2wpkh, nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return p2wpkh, nil
+	case *btcutil.AddressTaproot:
+		return p2tr, nil
+	default:
+		return 0, fmt.Errorf("unsupported address type %T", addr)
+	}
+}
+
+// Input is an unspent output of a wallet address.
+type Input struct {
+	// Address is the wallet address owning the output, along with the key
+	// that spends it.
+	Address *wallet.DerivedAddress
+
+	// UTXO is the output.
+	UTXO blockchain.UTXO
+
+	// PrevTx is the transaction creating the output. It is required for
+	// P2PKH inputs, and included for P2SH-P2WPKH and P2WPKH inputs if
+	// known, as hardware signers verify input amounts with it.
+	PrevTx *wire.MsgTx
+}
+
+// pkScript returns the output script of the input.
+func (i *Input) pkScript() ([]byte, error) {
+	return txscript.PayToAddrScript(i.Address.Address)
+}
+
+// CollectInputs looks up the unspent outputs of the addresses on a source,
+// along with the previous transactions the PSBT needs.
+func CollectInputs(ctx context.Context, src blockchain.Source,
+	addrs []*wallet.DerivedAddress) ([]*Input, error) {
+
+	prevTxs := make(map[chainhash.Hash]*wire.MsgTx)
+	var inputs []*Input
+	for _, addr := range addrs {
+		t, err := addressScriptType(addr.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		utxos, err := src.UTXOs(ctx, addr.Address)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list outputs of %s: %w",
+				addr.Address, err)
+		}
+
+		for _, utxo := range utxos {
+			input := &Input{Address: addr, UTXO: utxo}
+			inputs = append(inputs, input)
+			if t == p2tr {
+				continue
+			}
+
+			hash := utxo.OutPoint.Hash
+			prevTx, ok := prevTxs[hash]
+			if !ok {
+				prevTx, err = src.Transaction(ctx, &hash)
+				switch {
+				// Segwit inputs can be signed without the
+				// previous transaction.
+				case err != nil && t != p2pkh &&
+					(errors.Is(err, blockchain.ErrNotSupported) ||
+						errors.Is(err, blockchain.ErrTxNotFound)):
+
+					continue
+
+				case err != nil:
+					return nil, fmt.Errorf("unable to fetch "+
+						"transaction %v: %w", hash, err)
+				}
+				prevTxs[hash] = prevTx
+			}
+			input.PrevTx = prevTx
+		}
+	}
+
+	return inputs, nil
+}
+
+// Config configures a sweep.
+type Config struct {
+	// Wallet is the wallet the inputs belong to.
+	Wallet *wallet.Wallet
+
+	// Inputs are the outputs to sweep.
+	Inputs []*Input
+
+	// Destination receives the swept funds.
+	Destination btcutil.Address
+
+	// FeeRate is the fee rate in sat/vB.
+	FeeRate float64
+}
+
+// Sweep is a sweep transaction.
+type Sweep struct {
+	// Packet is the PSBT of the transaction.
+	Packet *psbt.Packet
+
+	// Inputs are the swept outputs, in the order of the transaction's
+	// inputs.
+	Inputs []*Input
+
+	// Total is the value of all inputs, Fee the fee paid and Amount what
+	// the destination receives.
+	Total  btcutil.Amount
+	Fee    btcutil.Amount
+	Amount btcutil.Amount
+
+	// VSize is the estimated virtual size of the signed transaction.
+	VSize int64
+}
+
+// Build creates the unsigned PSBT of a sweep. Every input carries its UTXO,
+// key origin and scripts, so any PSBT signer holding the seed can sign it.
+func Build(cfg *Config) (*Sweep, error) {
+	if len(cfg.Inputs) == 0 {
+		return nil, ErrNoInputs
+	}
+	if math.IsNaN(cfg.FeeRate) || cfg.FeeRate < MinFeeRate {
+		return nil, fmt.Errorf("fee rate must be at least %v sat/vB",
+			MinFeeRate)
+	}
+	if !cfg.Destination.IsForNet(cfg.Wallet.NetParams()) {
+		return nil, fmt.Errorf("destination %s is not a %s address",
+			cfg.Destination, cfg.Wallet.Network())
+	}
+	fingerprint, err := cfg.Wallet.MasterKeyFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	destScript, err := txscript.PayToAddrScript(cfg.Destination)
+	if err != nil {
+		return nil, err
+	}
+	output := wire.NewTxOut(0, destScript)
+
+	var total btcutil.Amount
+	types := make([]scriptType, len(cfg.Inputs))
+	outPoints := make([]*wire.OutPoint, len(cfg.Inputs))
+	sequences := make([]uint32, len(cfg.Inputs))
+	seen := make(map[wire.OutPoint]bool, len(cfg.Inputs))
+	for i, input := range cfg.Inputs {
+		types[i], err = addressScriptType(input.Address.Address)
+		if err != nil {
+			return nil, err
+		}
+		if seen[input.UTXO.OutPoint] {
+			return nil, fmt.Errorf("output %v is spent twice",
+				input.UTXO.OutPoint)
+		}
+		seen[input.UTXO.OutPoint] = true
+
+		outPoint := input.UTXO.OutPoint
+		outPoints[i] = &outPoint
+		sequences[i] = rbfSequence
+		total += input.UTXO.Value
+	}
+
+	vsize := virtualSize(estimateWeight(types, []*wire.TxOut{output}))
+	fee := btcutil.Amount(math.Ceil(cfg.FeeRate * float64(vsize)))
+	output.Value = int64(total - fee)
+	if dust := dustThreshold(output); total-fee < dust {
+		return nil, fmt.Errorf("%w: %v - fee %v < %v", ErrDust, total,
+			fee, dust)
+	}
+
+	packet, err := psbt.New(
+		outPoints, []*wire.TxOut{output}, txVersion, 0, sequences,
+	)
+	if err != nil {
+		return nil, err
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return nil, err
+	}
+	for i, input := range cfg.Inputs {
+		err := addInput(updater, i, input, types[i], fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("input %v: %w", input.UTXO.OutPoint,
+				err)
+		}
+	}
+
+	return &Sweep{
+		Packet: packet,
+		Inputs: cfg.Inputs,
+		Total:  total,
+		Fee:    fee,
+		Amount: total - fee,
+		VSize:  vsize,
+	}, nil
+}
+
+// addInput adds the UTXO, the scripts and the key origin of an input to the
+// PSBT.
+func addInput(u *psbt.Updater, i int, input *Input, t scriptType,
+	fingerprint uint32) error {
+
+	pkScript, err := input.pkScript()
+	if err != nil {
+		return err
+	}
+	pubKey, err := input.Address.Key.ECPubKey()
+	if err != nil {
+		return err
+	}
+	path, err := wallet.ParsePath(input.Address.Path)
+	if err != nil {
+		return err
+	}
+
+	if input.PrevTx != nil {
+		outPoint := input.UTXO.OutPoint
+		if input.PrevTx.TxHash() != outPoint.Hash ||
+			int(outPoint.Index) >= len(input.PrevTx.TxOut) {
+
+			return fmt.Errorf("previous transaction doesn't match")
+		}
+		prevOut := input.PrevTx.TxOut[outPoint.Index]
+		if prevOut.Value != int64(input.UTXO.Value) ||
+			string(prevOut.PkScript) != string(pkScript) {
+
+			return fmt.Errorf("previous output doesn't match the " +
+				"address and value")
+		}
+		if err := u.AddInNonWitnessUtxo(input.PrevTx, i); err != nil {
+			return err
+		}
+	} else if t == p2pkh {
+		return fmt.Errorf("spending a P2PKH output requires the " +
+			"previous transaction")
+	}
+
+	if t != p2pkh {
+		txOut := wire.NewTxOut(int64(input.UTXO.Value), pkScript)
+		if err := u.AddInWitnessUtxo(txOut, i); err != nil {
+			return err
+		}
+	}
+
+	switch t {
+	case p2tr:
+		xOnly := schnorr.SerializePubKey(pubKey)
+		pInput := &u.Upsbt.Inputs[i]
+		pInput.TaprootInternalKey = xOnly
+		pInput.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{{
+			XOnlyPubKey:          xOnly,
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		}}
+
+		return nil
+
+	case p2shP2wpkh:
+		redeemScript, err := p2wpkhScript(pubKey.SerializeCompressed())
+		if err != nil {
+			return err
+		}
+		if err := u.AddInRedeemScript(redeemScript, i); err != nil {
+			return err
+		}
+	}
+
+	return u.AddInBip32Derivation(
+		fingerprint, path, pubKey.SerializeCompressed(), i,
+	)
+}
+
+// p2wpkhScript returns the P2WPKH script of a public key.
+func p2wpkhScript(pubKey []byte) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(btcutil.Hash160(pubKey)).
+		Script()
+}
+
+// Sign signs every input with the keys of the wallet addresses and finalizes
+// the PSBT. The signed transaction is verified before returning.
+func (s *Sweep) Sign() error {
+	tx := s.Packet.UnsignedTx
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	pkScripts := make([][]byte, len(s.Inputs))
+	for i, input := range s.Inputs {
+		pkScript, err := input.pkScript()
+		if err != nil {
+			return err
+		}
+		pkScripts[i] = pkScript
+		fetcher.AddPrevOut(input.UTXO.OutPoint, wire.NewTxOut(
+			int64(input.UTXO.Value), pkScript,
+		))
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	updater, err := psbt.NewUpdater(s.Packet)
+	if err != nil {
+		return err
+	}
+	for i, input := range s.Inputs {
+		err := signInput(
+			updater, sigHashes, i, input, pkScripts[i],
+		)
+		if err != nil {
+			return fmt.Errorf("unable to sign input %v: %w",
+				input.UTXO.OutPoint, err)
+		}
+	}
+
+	if err := psbt.MaybeFinalizeAll(s.Packet); err != nil {
+		return fmt.Errorf("unable to finalize PSBT: %w", err)
+	}
+
+	// Run the scripts, so a transaction that wouldn't be valid is never
+	// handed out.
+	signedTx, err := s.Tx()
+	if err != nil {
+		return err
+	}
+	for i, input := range s.Inputs {
+		vm, err := txscript.NewEngine(
+			pkScripts[i], signedTx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, int64(input.UTXO.Value), fetcher,
+		)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return fmt.Errorf("signature of input %d is invalid: %w",
+				i, err)
+		}
+	}
+
+	return nil
+}
+
+// signInput adds the signature of an input to the PSBT.
+func signInput(u *psbt.Updater, sigHashes *txscript.TxSigHashes, i int,
+	input *Input, pkScript []byte) error {
+
+	t, err := addressScriptType(input.Address.Address)
+	if err != nil {
+		return err
+	}
+	privKey, err := input.Address.Key.ECPrivKey()
+	if err != nil {
+		return err
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	tx := u.Upsbt.UnsignedTx
+	amount := int64(input.UTXO.Value)
+
+	switch t {
+	case p2pkh:
+		sig, err := txscript.RawTxInSignature(
+			tx, i, pkScript, txscript.SigHashAll, privKey,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = u.Sign(i, sig, pubKey, nil, nil)
+		return err
+
+	case p2shP2wpkh:
+		redeemScript, err := p2wpkhScript(pubKey)
+		if err != nil {
+			return err
+		}
+		sig, err := txscript.RawTxInWitnessSignature(
+			tx, sigHashes, i, amount, redeemScript,
+			txscript.SigHashAll, privKey,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = u.Sign(i, sig, pubKey, redeemScript, nil)
+		return err
+
+	case p2wpkh:
+		sig, err := txscript.RawTxInWitnessSignature(
+			tx, sigHashes, i, amount, pkScript, txscript.SigHashAll,
+			privKey,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = u.Sign(i, sig, pubKey, nil, nil)
+		return err
+
+	default:
+		// BIP86 keys commit to an empty script tree.
+		sig, err := txscript.RawTxInTaprootSignature(
+			tx, sigHashes, i, amount, pkScript, []byte{},
+			txscript.SigHashDefault, privKey,
+		)
+		if err != nil {
+			return err
+		}
+		u.Upsbt.Inputs[i].TaprootKeySpendSig = sig
+		return nil
+	}
+}
+
+// Signed reports whether every input is signed and finalized.
+func (s *Sweep) Signed() bool {
+	return s.Packet.IsComplete()
+}
+
+// Tx returns the signed transaction of a finalized PSBT.
+func (s *Sweep) Tx() (*wire.MsgTx, error) {
+	return psbt.Extract(s.Packet)
+}
+
+// Base64 returns the base64 encoded PSBT, as accepted by wallets and
+// signers.
+func (s *Sweep) Base64() (string, error) {
+	return s.Packet.B64Encode()
+}
@@ -0,0 +1,239 @@
+package sweep
+
+import (
+	"context"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// fundAddresses derives an address of every purpose and funds each with an
+// output of its own transaction.
+func fundAddresses(t *testing.T, w *wallet.Wallet, src *blockchain.Mock,
+	value int64) []*wallet.DerivedAddress {
+
+	t.Helper()
+
+	var addrs []*wallet.DerivedAddress
+	for i, purpose := range []uint32{
+		wallet.BIP44Purpose, wallet.BIP49Purpose, wallet.BIP84Purpose,
+		wallet.BIP86Purpose,
+	} {
+		addr, err := w.DeriveAddress(purpose, 0, 0, 0)
+		require.NoError(t, err)
+		pkScript, err := txscript.PayToAddrScript(addr.Address)
+		require.NoError(t, err)
+
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+			Hash: chainhash.Hash{byte(i + 1)},
+		}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(value, pkScript))
+		src.AddTx(tx, 100, addr.Address)
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs
+}
+
+func newTestWallet(t *testing.T) *wallet.Wallet {
+	t.Helper()
+
+	w, err := wallet.NewFromSeed(make([]byte, 32), wallet.MainNet)
+	require.NoError(t, err)
+
+	return w
+}
+
+// destination returns an address outside of the test wallet.
+func destination(t *testing.T) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), wallet.MainNet.Params,
+	)
+	require.NoError(t, err)
+
+	return addr
+}
+
+// TestSweepSign checks that inputs of every address type are signed and that
+// the fee matches the size of the signed transaction.
+func TestSweepSign(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	src := blockchain.NewMock()
+	addrs := fundAddresses(t, w, src, 50_000)
+
+	inputs, err := CollectInputs(context.Background(), src, addrs)
+	require.NoError(t, err)
+	require.Len(t, inputs, 4)
+	require.NotNil(t, inputs[0].PrevTx)
+	require.Nil(t, inputs[3].PrevTx)
+
+	s, err := Build(&Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: destination(t),
+		FeeRate:     2,
+	})
+	require.NoError(t, err)
+	require.False(t, s.Signed())
+	require.Equal(t, btcutil.Amount(200_000), s.Total)
+	require.Equal(t, s.Total-s.Fee, s.Amount)
+	require.Equal(t, btcutil.Amount(2*s.VSize), s.Fee)
+
+	require.NoError(t, s.Sign())
+	require.True(t, s.Signed())
+
+	tx, err := s.Tx()
+	require.NoError(t, err)
+	require.Len(t, tx.TxIn, 4)
+	require.Len(t, tx.TxOut, 1)
+	require.Equal(t, int64(s.Amount), tx.TxOut[0].Value)
+	for _, in := range tx.TxIn {
+		require.Equal(t, uint32(rbfSequence), in.Sequence)
+	}
+
+	// The estimate may only exceed the actual size, by at most a byte
+	// per input for shorter signatures.
+	actual := virtualSize(blockchainWeight(tx))
+	require.GreaterOrEqual(t, s.VSize, actual)
+	require.LessOrEqual(t, s.VSize-actual, int64(len(tx.TxIn)))
+
+	encoded, err := s.Base64()
+	require.NoError(t, err)
+	require.NotEmpty(t, encoded)
+}
+
+// blockchainWeight returns the weight of a transaction.
+func blockchainWeight(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+}
+
+// TestBuildUnsigned checks that an unsigned PSBT carries what an external
+// signer needs to sign every input.
+func TestBuildUnsigned(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	src := blockchain.NewMock()
+	addrs := fundAddresses(t, w, src, 50_000)
+	inputs, err := CollectInputs(context.Background(), src, addrs)
+	require.NoError(t, err)
+
+	s, err := Build(&Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: destination(t),
+		FeeRate:     1,
+	})
+	require.NoError(t, err)
+
+	fingerprint, err := w.MasterKeyFingerprint()
+	require.NoError(t, err)
+
+	pInputs := s.Packet.Inputs
+	require.NotNil(t, pInputs[0].NonWitnessUtxo)
+	require.Nil(t, pInputs[0].WitnessUtxo)
+	require.NotEmpty(t, pInputs[1].RedeemScript)
+	require.NotNil(t, pInputs[2].WitnessUtxo)
+	for i := 0; i < 3; i++ {
+		require.Len(t, pInputs[i].Bip32Derivation, 1)
+		derivation := pInputs[i].Bip32Derivation[0]
+		require.Equal(t, fingerprint, derivation.MasterKeyFingerprint)
+		path, err := wallet.ParsePath(addrs[i].Path)
+		require.NoError(t, err)
+		require.Equal(t, path, derivation.Bip32Path)
+	}
+
+	require.Len(t, pInputs[3].TaprootInternalKey, 32)
+	require.Len(t, pInputs[3].TaprootBip32Derivation, 1)
+	require.Equal(
+		t, fingerprint,
+		pInputs[3].TaprootBip32Derivation[0].MasterKeyFingerprint,
+	)
+}
+
+// TestBuildErrors checks the rejected sweeps.
+func TestBuildErrors(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	src := blockchain.NewMock()
+	addrs := fundAddresses(t, w, src, 1000)
+	inputs, err := CollectInputs(context.Background(), src, addrs)
+	require.NoError(t, err)
+
+	_, err = Build(&Config{
+		Wallet: w, Destination: destination(t), FeeRate: 1,
+	})
+	require.ErrorIs(t, err, ErrNoInputs)
+
+	// A P2PKH input of 1000 sats can't pay for itself at 10 sat/vB.
+	_, err = Build(&Config{
+		Wallet:      w,
+		Inputs:      inputs[:1],
+		Destination: destination(t),
+		FeeRate:     10,
+	})
+	require.ErrorIs(t, err, ErrDust)
+
+	_, err = Build(&Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: destination(t),
+		FeeRate:     0.5,
+	})
+	require.ErrorContains(t, err, "fee rate")
+
+	testAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), wallet.TestNet.Params,
+	)
+	require.NoError(t, err)
+	_, err = Build(&Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: testAddr,
+		FeeRate:     1,
+	})
+	require.ErrorContains(t, err, "is not a")
+
+	// P2PKH inputs can't be spent without their previous transaction.
+	noPrev := *inputs[0]
+	noPrev.PrevTx = nil
+	_, err = Build(&Config{
+		Wallet:      w,
+		Inputs:      []*Input{&noPrev},
+		Destination: destination(t),
+		FeeRate:     1,
+	})
+	require.ErrorContains(t, err, "previous transaction")
+}
+
+// TestCollectInputsNoTx checks that segwit inputs are collected from sources
+// that can't return transactions, while P2PKH inputs fail.
+func TestCollectInputsNoTx(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	src := blockchain.NewMock()
+	addrs := fundAddresses(t, w, src, 50_000)
+	src.Txs = nil
+
+	inputs, err := CollectInputs(context.Background(), src, addrs[1:])
+	require.NoError(t, err)
+	require.Len(t, inputs, 3)
+
+	_, err = CollectInputs(context.Background(), src, addrs[:1])
+	require.ErrorIs(t, err, blockchain.ErrTxNotFound)
+}
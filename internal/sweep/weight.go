@@ -0,0 +1,102 @@
+package sweep
+
+import (
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// The weights below assume signatures of maximum size, so the
+	// estimated fee is never too low.
+
+	// inputBaseSize is the size of an input without its script: the
+	// outpoint, the sequence and the length of the signature script.
+	inputBaseSize = 36 + 4 + 1
+
+	// p2pkhScriptSigSize is the size of a P2PKH signature script: a DER
+	// signature with its sighash flag and a compressed public key.
+	p2pkhScriptSigSize = 1 + 73 + 1 + 33
+
+	// nestedScriptSigSize is the size of the signature script of a
+	// P2SH-P2WPKH input, which pushes the P2WPKH redeem script.
+	nestedScriptSigSize = 1 + 22
+
+	// p2wpkhWitnessSize is the size of a P2WPKH witness: the number of
+	// elements, the signature and the public key.
+	p2wpkhWitnessSize = 1 + 1 + 73 + 1 + 33
+
+	// taprootKeySpendWitnessSize is the size of a P2TR key spend witness
+	// with a default sighash signature.
+	taprootKeySpendWitnessSize = 1 + 1 + 64
+
+	// dustRelayFeeRate is the default dust relay fee of Bitcoin Core in
+	// sat/vB.
+	dustRelayFeeRate = 3
+)
+
+// inputWeight returns the estimated weight of an input of the given type.
+func inputWeight(t scriptType) int64 {
+	switch t {
+	case p2pkh:
+		return 4 * (inputBaseSize + p2pkhScriptSigSize)
+	case p2shP2wpkh:
+		return 4*(inputBaseSize+nestedScriptSigSize) + p2wpkhWitnessSize
+	case p2wpkh:
+		return 4*inputBaseSize + p2wpkhWitnessSize
+	default:
+		return 4*inputBaseSize + taprootKeySpendWitnessSize
+	}
+}
+
+// estimateWeight returns the estimated weight of a signed transaction
+// spending inputs of the given types to the given outputs.
+func estimateWeight(inputs []scriptType, outputs []*wire.TxOut) int64 {
+	size := 4 + 4 + int64(wire.VarIntSerializeSize(uint64(len(inputs)))) +
+		int64(wire.VarIntSerializeSize(uint64(len(outputs))))
+	for _, out := range outputs {
+		size += int64(out.SerializeSize())
+	}
+	weight := 4 * size
+
+	segwit := false
+	for _, t := range inputs {
+		weight += inputWeight(t)
+		if t != p2pkh {
+			segwit = true
+		}
+	}
+
+	// Segwit transactions have a marker and a flag, and legacy inputs
+	// of such a transaction an empty witness.
+	if segwit {
+		weight += 2
+		for _, t := range inputs {
+			if t == p2pkh {
+				weight++
+			}
+		}
+	}
+
+	return weight
+}
+
+// virtualSize converts a weight to virtual bytes, rounding up.
+func virtualSize(weight int64) int64 {
+	return (weight + 3) / 4
+}
+
+// dustThreshold returns the smallest output value Bitcoin Core relays by
+// default: the cost of creating and spending the output at the dust relay
+// fee. Like Bitcoin Core, spending is assumed to take a 107 byte signature
+// script, or witness for witness programs.
+func dustThreshold(out *wire.TxOut) btcutil.Amount {
+	size := out.SerializeSize() + inputBaseSize
+	if txscript.IsWitnessProgram(out.PkScript) {
+		size += 107 / 4
+	} else {
+		size += 107
+	}
+
+	return btcutil.Amount(size * dustRelayFeeRate)
+}
@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// GenerateLegacyAddress generates a P2PKH address from a derived key.
+func GenerateLegacyAddress(key *hdkeychain.ExtendedKey,
+	netParams *chaincfg.Params) (btcutil.Address, error) {
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()), netParams,
+	)
+}
+
+// GenerateNestedSegWitAddress generates a P2SH-P2WPKH address from a derived
+// key.
+func GenerateNestedSegWitAddress(key *hdkeychain.ExtendedKey,
+	netParams *chaincfg.Params) (btcutil.Address, error) {
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	// The redeem script is the P2WPKH witness program, which is then
+	// wrapped in a P2SH output.
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	witnessScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(pubKeyHash).
+		Script()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build witness script: %w", err)
+	}
+
+	return btcutil.NewAddressScriptHashFromHash(
+		btcutil.Hash160(witnessScript), netParams,
+	)
+}
+
+// GenerateNativeSegWitAddress generates a P2WPKH address from a derived key.
+func GenerateNativeSegWitAddress(key *hdkeychain.ExtendedKey,
+	netParams *chaincfg.Params) (btcutil.Address, error) {
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()), netParams,
+	)
+}
+
+// GenerateTaprootAddress generates a BIP86 P2TR key-path-only address from a
+// derived key.
+func GenerateTaprootAddress(key *hdkeychain.ExtendedKey,
+	netParams *chaincfg.Params) (btcutil.Address, error) {
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	taprootKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+	return btcutil.NewAddressTaproot(
+		schnorr.SerializePubKey(taprootKey), netParams,
+	)
+}
+
+// GenerateAddress generates the address type that matches the given BIP
+// purpose from a derived key.
+func GenerateAddress(key *hdkeychain.ExtendedKey, purpose uint32,
+	netParams *chaincfg.Params) (btcutil.Address, error) {
+
+	switch purpose {
+	case BIP44Purpose:
+		return GenerateLegacyAddress(key, netParams)
+	case BIP49Purpose:
+		return GenerateNestedSegWitAddress(key, netParams)
+	case BIP84Purpose:
+		return GenerateNativeSegWitAddress(key, netParams)
+	case BIP86Purpose:
+		return GenerateTaprootAddress(key, netParams)
+	default:
+		return nil, ErrUnknownPurpose{Purpose: purpose}
+	}
+}
@@ -0,0 +1,113 @@
+package wallet
+
+import "time"
+
+const (
+	// extrapolatedBlockInterval is the block interval assumed past the last
+	// known block when estimating block times. It is shorter than the
+	// average between any two known blocks, the shortest being about 8.4
+	// minutes from 2012 to 2014, so the estimated time of a block is
+	// earlier than its real time.
+	extrapolatedBlockInterval = 8 * time.Minute
+
+	// extrapolationMargin is taken off the extrapolated block times, to
+	// allow for runs of fast blocks and for block times that are off.
+	extrapolationMargin = 24 * time.Hour
+)
+
+// BlockTime is the height of a block and the time it was mined.
+type BlockTime struct {
+	Height int64
+	Time   time.Time
+}
+
+// mainNetBlockTimes are the genesis block, the halvings and a few round
+// heights of the main network, close enough together that the block rate
+// between them is steady.
+var mainNetBlockTimes = []BlockTime{
+	{0, time.Unix(1231006505, 0)},
+	{100000, time.Unix(1293623863, 0)},
+	{210000, time.Unix(1354116278, 0)},
+	{300000, time.Unix(1399703554, 0)},
+	{420000, time.Unix(1468082773, 0)},
+	{500000, time.Unix(1513622125, 0)},
+	{630000, time.Unix(1589225023, 0)},
+	{700000, time.Unix(1631333672, 0)},
+	{800000, time.Unix(1690168629, 0)},
+	{840000, time.Unix(1713571767, 0)},
+}
+
+// CanEstimateHeights reports whether heights and times of the network can be
+// estimated offline.
+func (n *Network) CanEstimateHeights() bool {
+	return len(n.BlockTimes) > 0
+}
+
+// EstimateHeight estimates the height of the first block mined at or after t,
+// interpolating between the known blocks of the network and extrapolating
+// past the last one at the target block rate. It returns false if the network
+// has no known blocks.
+func (n *Network) EstimateHeight(t time.Time) (int64, bool) {
+	if !n.CanEstimateHeights() {
+		return 0, false
+	}
+
+	first := n.BlockTimes[0]
+	if !t.After(first.Time) {
+		return first.Height, true
+	}
+	for i := 1; i < len(n.BlockTimes); i++ {
+		prev, next := n.BlockTimes[i-1], n.BlockTimes[i]
+		if t.After(next.Time) {
+			continue
+		}
+		elapsed := t.Sub(prev.Time)
+		span := next.Time.Sub(prev.Time)
+		blocks := next.Height - prev.Height
+
+		return prev.Height + int64(float64(blocks)*
+			float64(elapsed)/float64(span)), true
+	}
+
+	last := n.BlockTimes[len(n.BlockTimes)-1]
+	blocks := t.Sub(last.Time) / n.Params.TargetTimePerBlock
+
+	return last.Height + int64(blocks), true
+}
+
+// EstimateTime estimates the time the block at height was mined, the inverse
+// of EstimateHeight between the known blocks of the network. Past the last
+// one, it assumes blocks faster than they have ever been mined and takes off
+// a margin, so that the estimate doesn't fall after the real time of the
+// block and is safe to start a rescan from. It returns false if the network
+// has no known blocks.
+func (n *Network) EstimateTime(height int64) (time.Time, bool) {
+	if !n.CanEstimateHeights() {
+		return time.Time{}, false
+	}
+
+	first := n.BlockTimes[0]
+	if height <= first.Height {
+		return first.Time, true
+	}
+	for i := 1; i < len(n.BlockTimes); i++ {
+		prev, next := n.BlockTimes[i-1], n.BlockTimes[i]
+		if height > next.Height {
+			continue
+		}
+		span := next.Time.Sub(prev.Time)
+		fraction := float64(height-prev.Height) /
+			float64(next.Height-prev.Height)
+
+		return prev.Time.Add(time.Duration(float64(span) * fraction)), true
+	}
+
+	last := n.BlockTimes[len(n.BlockTimes)-1]
+	elapsed := time.Duration(height-last.Height)*extrapolatedBlockInterval -
+		extrapolationMargin
+	if elapsed < 0 {
+		return last.Time, true
+	}
+
+	return last.Time.Add(elapsed), true
+}
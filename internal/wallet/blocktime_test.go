@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEstimateHeight checks the estimates at, between and past the known
+// blocks of the main network.
+func TestEstimateHeight(t *testing.T) {
+	t.Parallel()
+
+	for _, block := range mainNetBlockTimes {
+		height, ok := MainNet.EstimateHeight(block.Time)
+		require.True(t, ok)
+		require.Equal(t, block.Height, height)
+
+		blockTime, ok := MainNet.EstimateTime(block.Height)
+		require.True(t, ok)
+		require.Equal(t, block.Time, blockTime)
+	}
+
+	// Halfway in time between two known blocks, the height is halfway
+	// between theirs.
+	prev, next := mainNetBlockTimes[7], mainNetBlockTimes[8]
+	middle := prev.Time.Add(next.Time.Sub(prev.Time) / 2)
+	height, ok := MainNet.EstimateHeight(middle)
+	require.True(t, ok)
+	require.Equal(t, (prev.Height+next.Height)/2, height)
+
+	blockTime, ok := MainNet.EstimateTime((prev.Height + next.Height) / 2)
+	require.True(t, ok)
+	require.WithinDuration(t, middle, blockTime, time.Second)
+
+	// Past the last known block, a block is expected every ten minutes
+	// when estimating heights.
+	last := mainNetBlockTimes[len(mainNetBlockTimes)-1]
+	height, ok = MainNet.EstimateHeight(last.Time.Add(24 * time.Hour))
+	require.True(t, ok)
+	require.Equal(t, last.Height+144, height)
+
+	// Block times are estimated early instead, and not before the last
+	// known block.
+	blockTime, ok = MainNet.EstimateTime(last.Height + 144)
+	require.True(t, ok)
+	require.Equal(t, last.Time, blockTime)
+
+	blockTime, ok = MainNet.EstimateTime(last.Height + 1000)
+	require.True(t, ok)
+	require.Equal(t, last.Time.Add(1000*8*time.Minute-24*time.Hour),
+		blockTime)
+
+	height, ok = MainNet.EstimateHeight(time.Unix(0, 0))
+	require.True(t, ok)
+	require.Zero(t, height)
+
+	_, ok = TestNet.EstimateHeight(last.Time)
+	require.False(t, ok)
+	_, ok = RegTest.EstimateTime(100)
+	require.False(t, ok)
+}
+
+// TestEstimateTimeEarly checks that extrapolated block times aren't later than
+// the real ones, by pretending in turn that each known block of the main
+// network is the last one and estimating the times of the later ones.
+func TestEstimateTimeEarly(t *testing.T) {
+	t.Parallel()
+
+	for i := range mainNetBlockTimes {
+		network := *MainNet
+		network.BlockTimes = mainNetBlockTimes[:i+1]
+		for _, block := range mainNetBlockTimes[i+1:] {
+			blockTime, ok := network.EstimateTime(block.Height)
+			require.True(t, ok)
+			require.False(
+				t, blockTime.After(block.Time),
+				"block %d estimated from block %d",
+				block.Height, mainNetBlockTimes[i].Height,
+			)
+		}
+	}
+}
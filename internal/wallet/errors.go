@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrAddressNotFound is returned if an address couldn't be found
+	// within the searched derivation paths of a wallet.
+	ErrAddressNotFound = fmt.Errorf("address not found in wallet")
+
+	// ErrWatchOnly is returned by watch-only wallets for keys they don't
+	// have: private keys, and the keys of other accounts.
+	ErrWatchOnly = fmt.Errorf("not available in a watch-only wallet")
+)
+
+// ErrUnknownPurpose is returned when a BIP purpose that the wallet doesn't
+// know how to turn into addresses is requested.
+type ErrUnknownPurpose struct {
+	// Purpose is the unsupported BIP purpose.
+	Purpose uint32
+}
+
+// Error returns a human-readable string describing the error.
+func (e ErrUnknownPurpose) Error() string {
+	return fmt.Sprintf("unknown purpose %d", e.Purpose)
+}
+
+// ErrInvalidAccount is returned for account numbers that don't fit a hardened
+// derivation index.
+type ErrInvalidAccount struct {
+	// Account is the invalid account number.
+	Account uint32
+}
+
+// Error returns a human-readable string describing the error.
+func (e ErrInvalidAccount) Error() string {
+	return fmt.Sprintf("invalid account %d, the maximum is %d", e.Account,
+		MaxAccount)
+}
+
+// ErrWrongNetwork is returned when a valid address of a different network
+// than the wallet's is used.
+type ErrWrongNetwork struct {
+	// Address is the offending address.
+	Address string
+
+	// Expected is the name of the wallet's network.
+	Expected string
+
+	// Detected lists the networks the address is valid for. Test
+	// networks share their encodings, so more than one can match.
+	Detected []string
+}
+
+// Error returns a human-readable string describing the error.
+func (e ErrWrongNetwork) Error() string {
+	return fmt.Sprintf("address %s belongs to %s, not %s", e.Address,
+		strings.Join(e.Detected, "/"), e.Expected)
+}
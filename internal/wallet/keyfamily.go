@@ -0,0 +1,164 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	// LNDPurpose is the purpose lnd derives all of its non-wallet keys
+	// under, at m/1017'/coinType'/keyFamily'/0/index.
+	LNDPurpose uint32 = 1017
+)
+
+// KeyFamily is one of lnd's key families. Each family is a distinct hardened
+// branch below LNDPurpose that is used for a single kind of key.
+type KeyFamily uint32
+
+const (
+	// KeyFamilyMultiSig is the family of the channel funding multisig
+	// keys.
+	KeyFamilyMultiSig KeyFamily = 0
+
+	// KeyFamilyRevocationBase is the family of the revocation base points.
+	KeyFamilyRevocationBase KeyFamily = 1
+
+	// KeyFamilyHtlcBase is the family of the HTLC base points.
+	KeyFamilyHtlcBase KeyFamily = 2
+
+	// KeyFamilyPaymentBase is the family of the payment base points.
+	KeyFamilyPaymentBase KeyFamily = 3
+
+	// KeyFamilyDelayBase is the family of the delay base points.
+	KeyFamilyDelayBase KeyFamily = 4
+
+	// KeyFamilyRevocationRoot is the family of the revocation roots the
+	// per-commitment secrets are derived from.
+	KeyFamilyRevocationRoot KeyFamily = 5
+
+	// KeyFamilyNodeKey is the family of the node identity key. The key at
+	// index 0 is the node's public key on the network.
+	KeyFamilyNodeKey KeyFamily = 6
+
+	// KeyFamilyStaticBackup is the family of the key that encrypts static
+	// channel backups.
+	KeyFamilyStaticBackup KeyFamily = 7
+
+	// KeyFamilyTowerSession is the family of the watchtower session keys.
+	KeyFamilyTowerSession KeyFamily = 8
+)
+
+// KeyFamilyInfo describes one of lnd's key families.
+type KeyFamilyInfo struct {
+	// Family is the key family number.
+	Family KeyFamily
+
+	// Name is a short human-readable name of the family.
+	Name string
+}
+
+// KeyFamilies lists the lnd key families, in numerical order.
+var KeyFamilies = []KeyFamilyInfo{
+	{KeyFamilyMultiSig, "Multisig"},
+	{KeyFamilyRevocationBase, "Revocation Base"},
+	{KeyFamilyHtlcBase, "HTLC Base"},
+	{KeyFamilyPaymentBase, "Payment Base"},
+	{KeyFamilyDelayBase, "Delay Base"},
+	{KeyFamilyRevocationRoot, "Revocation Root"},
+	{KeyFamilyNodeKey, "Node Key"},
+	{KeyFamilyStaticBackup, "Static Backup"},
+	{KeyFamilyTowerSession, "Tower Session"},
+}
+
+// LNDKey is a key derived from one of lnd's key families.
+type LNDKey struct {
+	// Family and Index locate the key within the wallet.
+	Family KeyFamily
+	Index  uint32
+
+	// Path is the full BIP32 derivation path of the key.
+	Path string
+
+	// PubKey is the public key, as lnd would announce or use it.
+	PubKey *btcec.PublicKey
+
+	// Key is the extended key the public key belongs to.
+	Key *hdkeychain.ExtendedKey
+}
+
+// KeyFamilyPath returns the derivation path of a key family.
+func (w *Wallet) KeyFamilyPath(family KeyFamily) string {
+	return w.AccountPath(LNDPurpose, uint32(family))
+}
+
+// DeriveLNDKey derives the key at m/1017'/coinType'/family'/0/index, the way
+// lnd's btcwallet keychain does. btcwallet derives with hdkeychain's
+// DeriveNonStandard, which differs from BIP32 when the parent private key has
+// a leading zero byte, but it stores the coin type and account keys
+// serialized, which pads them back to 32 bytes. So only the steps btcwallet
+// takes from a key still in memory are affected: the coin type key, and the
+// account key of family 0, which is created together with the lnd scope.
+func (w *Wallet) DeriveLNDKey(family KeyFamily,
+	index uint32) (*LNDKey, error) {
+
+	if w.masterKey == nil {
+		return nil, ErrWatchOnly
+	}
+
+	purposeKey, err := w.masterKey.DeriveNonStandard( // nolint:staticcheck
+		LNDPurpose + hdkeychain.HardenedKeyStart,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive purpose key: %w", err)
+	}
+	coinTypeKey, err := purposeKey.DeriveNonStandard( // nolint:staticcheck
+		w.coinType + hdkeychain.HardenedKeyStart,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive coin type key: %w", err)
+	}
+
+	// lnd uses the key family as the account and always derives from the
+	// external branch.
+	deriveAccount := coinTypeKey.Derive
+	if family == KeyFamilyMultiSig {
+		deriveAccount = coinTypeKey.DeriveNonStandard // nolint:staticcheck
+	}
+	key, err := deriveAccount(uint32(family) + hdkeychain.HardenedKeyStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive account key: %w", err)
+	}
+	for _, child := range []uint32{ExternalChain, index} {
+		key, err = key.Derive(child)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive lnd key: %w",
+				err)
+		}
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return &LNDKey{
+		Family: family,
+		Index:  index,
+		Path: fmt.Sprintf("%s/%d/%d", w.KeyFamilyPath(family),
+			ExternalChain, index),
+		PubKey: pubKey,
+		Key:    key,
+	}, nil
+}
+
+// NodePubKey returns the identity public key of the lnd node the seed
+// belongs to.
+func (w *Wallet) NodePubKey() (*btcec.PublicKey, error) {
+	nodeKey, err := w.DeriveLNDKey(KeyFamilyNodeKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeKey.PubKey, nil
+}
@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"slices"
+	"strings"
+	"testing"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// lndKeyVectors are aezeed mnemonics and the keys lnd v0.19.1 derived from
+// them on mainnet, at index 0 of the given key families.
+var lndKeyVectors = []struct {
+	mnemonic   string
+	passphrase string
+	pubKeys    map[KeyFamily]string
+
+	// bip32Mismatch are the families whose key plain BIP32 derivation
+	// gets wrong for the seed.
+	bip32Mismatch []KeyFamily
+}{{
+	mnemonic: "abandon visa goose inflict appear charge abandon account " +
+		"arrow keep bind push lesson shock behind someone bar saddle " +
+		"discover limit fame garlic undo brain",
+	passphrase: "vector passphrase",
+	pubKeys: map[KeyFamily]string{
+		KeyFamilyMultiSig: "036d214b3f946cb165d65c983e61518a1054c943f2" +
+			"0f224da45c528f704970a4e9",
+		KeyFamilyNodeKey: "0337edfb30eddc5725590fc8200635fb4adb5b738f" +
+			"73504e7afb5896175e8cd9d8",
+	},
+}, {
+	// The m/1017' private key of this seed has a leading zero byte.
+	mnemonic: "ability income blush dry picnic raise left absorb laugh " +
+		"oval violin onion obvious sunny multiply neutral imitate vault " +
+		"zone gap hunt quit hundred borrow",
+	passphrase: "vector passphrase",
+	pubKeys: map[KeyFamily]string{
+		KeyFamilyMultiSig: "0398ed9b8fefaa25cb6c787bd3c5a5da8a733d30fb" +
+			"731561de4c2bb7ce2d753ea1",
+		KeyFamilyNodeKey: "03cf44215722931c8cfc6a7f7a60cbeea5e79d4481" +
+			"4924db0523a5f0f2e7d9096f",
+	},
+	bip32Mismatch: []KeyFamily{KeyFamilyMultiSig, KeyFamilyNodeKey},
+}, {
+	// The m/1017'/0' private key of this seed has a leading zero byte,
+	// which only matters for the first account lnd creates.
+	mnemonic: "about unlock stereo member tag already dentist hazard " +
+		"reason addict lunch olympic account bless lazy phrase vacuum " +
+		"horse urge nation famous appear profit steel",
+	passphrase: "vector passphrase",
+	pubKeys: map[KeyFamily]string{
+		KeyFamilyMultiSig: "02e4ef00ea9a998d0bc3f87d6a4626913b795d1201" +
+			"890aaf3c97a4ae3876b368cc",
+		KeyFamilyNodeKey: "02cc9954ca0b0f591891f3fea5648e79426df150b3" +
+			"09a2a089a431e5b260510586",
+	},
+	bip32Mismatch: []KeyFamily{KeyFamilyMultiSig},
+}}
+
+// TestDeriveLNDKey checks key family keys against the keys lnd itself derived
+// from the same seeds.
+func TestDeriveLNDKey(t *testing.T) {
+	t.Parallel()
+
+	for _, vector := range lndKeyVectors {
+		var mnemonic crypto.Mnemonic
+		copy(mnemonic[:], strings.Fields(vector.mnemonic))
+		seed, err := mnemonic.ToCipherSeed([]byte(vector.passphrase))
+		require.NoError(t, err)
+
+		w, err := New(seed, MainNet)
+		require.NoError(t, err)
+		for family, pubKey := range vector.pubKeys {
+			key, err := w.DeriveLNDKey(family, 0)
+			require.NoError(t, err)
+			require.Equal(
+				t, pubKey,
+				hex.EncodeToString(key.PubKey.SerializeCompressed()),
+				"family %d of %v", family, vector.mnemonic,
+			)
+
+			// Make sure the vector still exercises the case it is
+			// meant for.
+			bip32Key, err := DeriveChildKey(
+				w.MasterKey(), LNDPurpose, CoinTypeBitcoin,
+				uint32(family), ExternalChain, 0,
+			)
+			require.NoError(t, err)
+			bip32Pub, err := bip32Key.ECPubKey()
+			require.NoError(t, err)
+			require.Equal(
+				t, slices.Contains(vector.bip32Mismatch, family),
+				!bip32Pub.IsEqual(key.PubKey),
+				"family %d of %v", family, vector.mnemonic,
+			)
+		}
+	}
+
+	w := newTestWallet(t)
+	key, err := w.DeriveLNDKey(KeyFamilyStaticBackup, 3)
+	require.NoError(t, err)
+	require.Equal(t, KeyFamilyStaticBackup, key.Family)
+	require.Equal(t, uint32(3), key.Index)
+	require.Equal(t, "m/1017'/0'/7'/0/3", key.Path)
+}
+
+// TestNodePubKey checks that the node identity key is the first key of the
+// node key family and follows the network's coin type.
+func TestNodePubKey(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	nodePub, err := w.NodePubKey()
+	require.NoError(t, err)
+
+	nodeKey, err := w.DeriveLNDKey(KeyFamilyNodeKey, 0)
+	require.NoError(t, err)
+	require.Equal(t, "m/1017'/0'/6'/0/0", nodeKey.Path)
+	require.True(t, nodePub.IsEqual(nodeKey.PubKey))
+
+	testnet, err := NewFromSeed(testBIP39Seed, TestNet)
+	require.NoError(t, err)
+	testnetKey, err := testnet.DeriveLNDKey(KeyFamilyNodeKey, 0)
+	require.NoError(t, err)
+	require.Equal(t, "m/1017'/1'/6'/0/0", testnetKey.Path)
+	require.False(t, nodePub.IsEqual(testnetKey.PubKey))
+}
@@ -0,0 +1,150 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	// CoinTypeTestnet is the BIP44 coin type shared by all test networks.
+	CoinTypeTestnet uint32 = 1
+)
+
+// Network bundles the chain parameters of a bitcoin network with the
+// defaults the tool uses to talk to it.
+type Network struct {
+	// Name is the short identifier of the network, as used on the
+	// command line.
+	Name string
+
+	// Params are the btcd chain parameters. They determine the address
+	// encodings (base58 versions and bech32 HRP) and the extended key
+	// prefixes (xpub/tpub).
+	Params *chaincfg.Params
+
+	// CoinType is the BIP44 coin type used in derivation paths.
+	CoinType uint32
+
+	// EsploraURL is the base URL of the default public Esplora API for
+	// the network. It is empty if there is no public instance.
+	EsploraURL string
+
+	// RPCHost is the default host:port of a local bitcoind RPC server.
+	RPCHost string
+
+	// ElectrumServer is the default address of a local Electrum server,
+	// using the ports of Electrs.
+	ElectrumServer string
+
+	// BlockTimes are known blocks of the network, in order, from which
+	// heights and times are estimated offline. It is empty for test
+	// networks, whose block rate is too irregular to estimate.
+	BlockTimes []BlockTime
+}
+
+var (
+	// MainNet is the bitcoin main network.
+	MainNet = &Network{
+		Name:           "mainnet",
+		Params:         &chaincfg.MainNetParams,
+		CoinType:       CoinTypeBitcoin,
+		EsploraURL:     "https://blockstream.info/api",
+		RPCHost:        "127.0.0.1:8332",
+		ElectrumServer: "tcp://127.0.0.1:50001",
+		BlockTimes:     mainNetBlockTimes,
+	}
+
+	// TestNet is the bitcoin test network (version 3).
+	TestNet = &Network{
+		Name:           "testnet",
+		Params:         &chaincfg.TestNet3Params,
+		CoinType:       CoinTypeTestnet,
+		EsploraURL:     "https://blockstream.info/testnet/api",
+		RPCHost:        "127.0.0.1:18332",
+		ElectrumServer: "tcp://127.0.0.1:60001",
+	}
+
+	// SigNet is the default public signet.
+	SigNet = &Network{
+		Name:           "signet",
+		Params:         &chaincfg.SigNetParams,
+		CoinType:       CoinTypeTestnet,
+		EsploraURL:     "https://mempool.space/signet/api",
+		RPCHost:        "127.0.0.1:38332",
+		ElectrumServer: "tcp://127.0.0.1:60601",
+	}
+
+	// RegTest is the local regression test network.
+	RegTest = &Network{
+		Name:           "regtest",
+		Params:         &chaincfg.RegressionNetParams,
+		CoinType:       CoinTypeTestnet,
+		RPCHost:        "127.0.0.1:18443",
+		ElectrumServer: "tcp://127.0.0.1:60401",
+	}
+
+	// Networks lists all supported networks, in display order.
+	Networks = []*Network{MainNet, TestNet, SigNet, RegTest}
+)
+
+// NetworkByName returns the supported network with the given name.
+func NetworkByName(name string) (*Network, error) {
+	for _, n := range Networks {
+		if strings.EqualFold(n.Name, name) {
+			return n, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown network %q", name)
+}
+
+// String returns the name of the network.
+func (n *Network) String() string {
+	return n.Name
+}
+
+// isFor reports whether the address decodes as one of this network's
+// addresses.
+func (n *Network) isFor(address string) (btcutil.Address, bool) {
+	addr, err := btcutil.DecodeAddress(address, n.Params)
+	if err != nil || !addr.IsForNet(n.Params) {
+		return nil, false
+	}
+
+	return addr, true
+}
+
+// DecodeAddress decodes an address and makes sure it belongs to this network.
+// ErrWrongNetwork is returned for valid addresses of other networks.
+func (n *Network) DecodeAddress(address string) (btcutil.Address, error) {
+	if addr, ok := n.isFor(address); ok {
+		return addr, nil
+	}
+
+	// Figure out which networks the address belongs to, so the user gets
+	// a helpful error instead of a generic decoding failure. Test
+	// networks share encodings, so there can be several matches.
+	var detected []string
+	for _, other := range Networks {
+		if _, ok := other.isFor(address); ok {
+			detected = append(detected, other.Name)
+		}
+	}
+	if len(detected) > 0 {
+		return nil, ErrWrongNetwork{
+			Address:  address,
+			Expected: n.Name,
+			Detected: detected,
+		}
+	}
+
+	_, err := btcutil.DecodeAddress(address, n.Params)
+	if err == nil {
+		err = fmt.Errorf("unsupported address format")
+	}
+
+	return nil, fmt.Errorf("invalid address: %w", err)
+}
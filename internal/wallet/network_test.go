@@ -0,0 +1,132 @@
+package wallet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNetworkByName checks the lookup of networks by their name.
+func TestNetworkByName(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range Networks {
+		found, err := NetworkByName(strings.ToUpper(n.Name))
+		require.NoError(t, err)
+		require.Equal(t, n, found)
+	}
+
+	_, err := NetworkByName("simnet")
+	require.Error(t, err)
+}
+
+// TestNetworkDerivation checks that the network switches the coin type, the
+// extended key prefix and the address encodings.
+func TestNetworkDerivation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		network    *Network
+		path       string
+		xpubPrefix string
+		addrPrefix string
+	}{{
+		network:    MainNet,
+		path:       "m/84'/0'/0'/0/0",
+		xpubPrefix: "xpub",
+		addrPrefix: "bc1q",
+	}, {
+		network:    TestNet,
+		path:       "m/84'/1'/0'/0/0",
+		xpubPrefix: "tpub",
+		addrPrefix: "tb1q",
+	}, {
+		network:    SigNet,
+		path:       "m/84'/1'/0'/0/0",
+		xpubPrefix: "tpub",
+		addrPrefix: "tb1q",
+	}, {
+		network:    RegTest,
+		path:       "m/84'/1'/0'/0/0",
+		xpubPrefix: "tpub",
+		addrPrefix: "bcrt1q",
+	}}
+
+	for _, test := range tests {
+		w, err := NewFromSeed(testBIP39Seed, test.network)
+		require.NoError(t, err)
+
+		xpub, err := w.AccountXpub(BIP84Purpose, DefaultAccount)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(xpub, test.xpubPrefix), xpub)
+
+		derived, err := w.DeriveAddress(
+			BIP84Purpose, DefaultAccount, ExternalChain, 0,
+		)
+		require.NoError(t, err)
+		require.Equal(t, test.path, derived.Path)
+
+		addr := derived.Address.String()
+		require.True(t, strings.HasPrefix(addr, test.addrPrefix), addr)
+
+		// The address must round trip through the network's decoder
+		// and be found in the wallet.
+		_, err = test.network.DecodeAddress(addr)
+		require.NoError(t, err)
+		found, err := w.FindAddress(addr, 1)
+		require.NoError(t, err)
+		require.Equal(t, test.path, found.Path)
+	}
+}
+
+// TestDecodeAddressWrongNetwork checks that addresses of another network are
+// rejected with a descriptive error.
+func TestDecodeAddressWrongNetwork(t *testing.T) {
+	t.Parallel()
+
+	addressOn := func(network *Network, purpose uint32) string {
+		w, err := NewFromSeed(testBIP39Seed, network)
+		require.NoError(t, err)
+		derived, err := w.DeriveAddress(
+			purpose, DefaultAccount, ExternalChain, 0,
+		)
+		require.NoError(t, err)
+
+		return derived.Address.String()
+	}
+	mainnetAddr := addressOn(MainNet, BIP84Purpose)
+	testnetAddr := addressOn(TestNet, BIP86Purpose)
+	regtestAddr := addressOn(RegTest, BIP84Purpose)
+	legacyAddr := addressOn(MainNet, BIP44Purpose)
+
+	tests := []struct {
+		network  *Network
+		address  string
+		detected []string
+	}{
+		{TestNet, mainnetAddr, []string{"mainnet"}},
+		{RegTest, legacyAddr, []string{"mainnet"}},
+		{MainNet, testnetAddr, []string{"testnet", "signet"}},
+		{RegTest, testnetAddr, []string{"testnet", "signet"}},
+		{MainNet, regtestAddr, []string{"regtest"}},
+	}
+	for _, test := range tests {
+		_, err := test.network.DecodeAddress(test.address)
+
+		var wrongNet ErrWrongNetwork
+		require.True(t, errors.As(err, &wrongNet), "%v", err)
+		require.Equal(t, test.network.Name, wrongNet.Expected)
+		require.Equal(t, test.detected, wrongNet.Detected)
+	}
+
+	w := newTestWallet(t)
+	_, err := w.FindAddress(testnetAddr, 1)
+	require.ErrorAs(t, err, &ErrWrongNetwork{})
+
+	// Garbage is reported as invalid, not as a network mismatch.
+	_, err = MainNet.DecodeAddress("bc1qinvalid")
+	require.Error(t, err)
+	require.False(t, errors.As(err, &ErrWrongNetwork{}))
+}
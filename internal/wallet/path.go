@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// ParsePath parses a BIP32 derivation path such as m/84'/0'/0'/0/1 into its
+// child indexes. Hardened elements are marked with ' or h.
+func ParsePath(path string) ([]uint32, error) {
+	elems := strings.Split(strings.TrimSpace(path), "/")
+	if len(elems) == 0 || elems[0] != "m" {
+		return nil, fmt.Errorf("invalid path %q: must start with m",
+			path)
+	}
+
+	indexes := make([]uint32, 0, len(elems)-1)
+	for _, elem := range elems[1:] {
+		hardened := strings.HasSuffix(elem, "'") ||
+			strings.HasSuffix(elem, "h") ||
+			strings.HasSuffix(elem, "H")
+		if hardened {
+			elem = elem[:len(elem)-1]
+		}
+
+		index, err := strconv.ParseUint(elem, 10, 32)
+		if err != nil || index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("invalid path %q: bad element %q",
+				path, elem)
+		}
+		if hardened {
+			index += hdkeychain.HardenedKeyStart
+		}
+		indexes = append(indexes, uint32(index))
+	}
+
+	return indexes, nil
+}
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// slip132Version is a SLIP-132 version of extended public keys, which tells
+// the script type of the key's addresses through its prefix.
+type slip132Version struct {
+	// Prefix is the first four characters of the encoded keys.
+	Prefix string
+
+	// Version are the version bytes of the serialized keys.
+	Version [4]byte
+
+	// TestNet marks versions of the test networks.
+	TestNet bool
+
+	// Purpose is the BIP purpose of the script type, zero for the
+	// standard xpub and tpub versions that don't imply one. Multisig
+	// versions use the purpose of the matching single key script type:
+	// BIP49 for P2WSH nested in P2SH and BIP84 for native P2WSH.
+	Purpose uint32
+
+	// Multisig marks the versions of multisig cosigner keys.
+	Multisig bool
+}
+
+// slip132Versions lists the supported public key versions.
+var slip132Versions = []slip132Version{
+	{"xpub", [4]byte{0x04, 0x88, 0xb2, 0x1e}, false, 0, false},
+	{"ypub", [4]byte{0x04, 0x9d, 0x7c, 0xb2}, false, BIP49Purpose, false},
+	{"zpub", [4]byte{0x04, 0xb2, 0x47, 0x46}, false, BIP84Purpose, false},
+	{"Ypub", [4]byte{0x02, 0x95, 0xb4, 0x3f}, false, BIP49Purpose, true},
+	{"Zpub", [4]byte{0x02, 0xaa, 0x7e, 0xd3}, false, BIP84Purpose, true},
+	{"tpub", [4]byte{0x04, 0x35, 0x87, 0xcf}, true, 0, false},
+	{"upub", [4]byte{0x04, 0x4a, 0x52, 0x62}, true, BIP49Purpose, false},
+	{"vpub", [4]byte{0x04, 0x5f, 0x1c, 0xf6}, true, BIP84Purpose, false},
+	{"Upub", [4]byte{0x02, 0x42, 0x89, 0xef}, true, BIP49Purpose, true},
+	{"Vpub", [4]byte{0x02, 0x57, 0x54, 0x83}, true, BIP84Purpose, true},
+}
+
+// isTestNet reports whether params are the parameters of a test network,
+// whose keys are encoded as tpub.
+func isTestNet(params *chaincfg.Params) bool {
+	return params.HDPublicKeyID != chaincfg.MainNetParams.HDPublicKeyID
+}
+
+// EncodeSLIP132 encodes an extended public key with the SLIP-132 version of
+// a purpose: ypub for BIP49 and zpub for BIP84 keys, or upub and vpub on the
+// test networks. Keys of other purposes are encoded as xpub or tpub.
+func EncodeSLIP132(key *hdkeychain.ExtendedKey, purpose uint32,
+	params *chaincfg.Params) (string, error) {
+
+	return encodeSLIP132(key, purpose, params, false)
+}
+
+// EncodeSLIP132Multisig encodes the extended public key of a multisig
+// cosigner with the SLIP-132 version of a purpose: Ypub for P2WSH nested in
+// P2SH (BIP49) and Zpub for native P2WSH (BIP84), or Upub and Vpub on the test
+// networks. Keys of other purposes are encoded as xpub or tpub.
+func EncodeSLIP132Multisig(key *hdkeychain.ExtendedKey, purpose uint32,
+	params *chaincfg.Params) (string, error) {
+
+	return encodeSLIP132(key, purpose, params, true)
+}
+
+// encodeSLIP132 encodes an extended public key with the single key or the
+// multisig SLIP-132 version of a purpose.
+func encodeSLIP132(key *hdkeychain.ExtendedKey, purpose uint32,
+	params *chaincfg.Params, multisig bool) (string, error) {
+
+	if key.IsPrivate() {
+		return "", fmt.Errorf("only public keys have SLIP-132 versions")
+	}
+
+	version := params.HDPublicKeyID
+	for _, v := range slip132Versions {
+		if v.Purpose == purpose && v.Purpose != 0 &&
+			v.TestNet == isTestNet(params) && v.Multisig == multisig {
+
+			version = v.Version
+		}
+	}
+	encoded, err := key.CloneWithVersion(version[:])
+	if err != nil {
+		return "", err
+	}
+
+	return encoded.String(), nil
+}
+
+// ParsedKey is an extended public key parsed by ParseExtendedKey.
+type ParsedKey struct {
+	// Key is the key, with the standard version of the network.
+	Key *hdkeychain.ExtendedKey
+
+	// Prefix is the prefix the key was encoded with, such as zpub.
+	Prefix string
+
+	// Purpose is the BIP purpose implied by the SLIP-132 version of the
+	// key, zero for xpub and tpub keys.
+	Purpose uint32
+
+	// Multisig is set for the keys of multisig cosigners, encoded as
+	// Ypub, Zpub, Upub or Vpub.
+	Multisig bool
+}
+
+// ParseExtendedKey parses an extended public key of the network, encoded
+// either as xpub/tpub or with a SLIP-132 version such as ypub, zpub or the
+// multisig Zpub.
+// Private keys are rejected, as they aren't needed to watch a wallet.
+func ParseExtendedKey(s string, network *Network) (*ParsedKey, error) {
+	key, err := hdkeychain.NewKeyFromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended key: %w", err)
+	}
+	if key.IsPrivate() {
+		return nil, fmt.Errorf("extended private keys are not accepted, " +
+			"use the extended public key")
+	}
+
+	for _, v := range slip132Versions {
+		if !bytes.Equal(key.Version(), v.Version[:]) {
+			continue
+		}
+		if v.TestNet != isTestNet(network.Params) {
+			return nil, fmt.Errorf("%s keys don't belong to %s", v.Prefix,
+				network)
+		}
+
+		standard, err := key.CloneWithVersion(
+			network.Params.HDPublicKeyID[:],
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ParsedKey{
+			Key:      standard,
+			Prefix:   v.Prefix,
+			Purpose:  v.Purpose,
+			Multisig: v.Multisig,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported extended key version %x",
+		key.Version())
+}
+
+// AccountSLIP132 returns the extended public key of an account, encoded with
+// the SLIP-132 version of its purpose.
+func (w *Wallet) AccountSLIP132(purpose, account uint32) (string, error) {
+	accountKey, err := w.accountKey(purpose, account)
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := accountKey.Neuter()
+	if err != nil {
+		return "", fmt.Errorf("failed to neuter account key: %w", err)
+	}
+
+	return EncodeSLIP132(pubKey, purpose, w.netParams)
+}
@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAccountSLIP132 checks the SLIP-132 account keys against the BIP49 and
+// BIP84 vectors and their round trip through ParseExtendedKey.
+func TestAccountSLIP132(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+
+	vectors := map[uint32]string{
+		BIP49Purpose: "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSE" +
+			"R9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf66" +
+			"3zsP",
+		BIP84Purpose: "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAc" +
+			"vPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGu" +
+			"tZYs",
+	}
+	for purpose, want := range vectors {
+		encoded, err := w.AccountSLIP132(purpose, 0)
+		require.NoError(t, err)
+		require.Equal(t, want, encoded)
+
+		xpub, err := w.AccountXpub(purpose, 0)
+		require.NoError(t, err)
+		parsed, err := ParseExtendedKey(encoded, MainNet)
+		require.NoError(t, err)
+		require.Equal(t, purpose, parsed.Purpose)
+		require.Equal(t, want[:4], parsed.Prefix)
+		require.Equal(t, xpub, parsed.Key.String())
+
+		reencoded, err := EncodeSLIP132(parsed.Key, purpose, MainNet.Params)
+		require.NoError(t, err)
+		require.Equal(t, want, reencoded)
+	}
+
+	// Legacy and taproot keys have no SLIP-132 version of their own.
+	for _, purpose := range []uint32{BIP44Purpose, BIP86Purpose} {
+		encoded, err := w.AccountSLIP132(purpose, 0)
+		require.NoError(t, err)
+		xpub, err := w.AccountXpub(purpose, 0)
+		require.NoError(t, err)
+		require.Equal(t, xpub, encoded)
+
+		parsed, err := ParseExtendedKey(encoded, MainNet)
+		require.NoError(t, err)
+		require.Zero(t, parsed.Purpose)
+	}
+}
+
+// TestSLIP132TestNet checks the upub and vpub versions of the test networks.
+func TestSLIP132TestNet(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewFromSeed(make([]byte, 32), SigNet)
+	require.NoError(t, err)
+
+	for purpose, prefix := range map[uint32]string{
+		BIP49Purpose: "upub", BIP84Purpose: "vpub",
+		BIP86Purpose: "tpub",
+	} {
+		encoded, err := w.AccountSLIP132(purpose, 1)
+		require.NoError(t, err)
+		require.Equal(t, prefix, encoded[:4])
+
+		tpub, err := w.AccountXpub(purpose, 1)
+		require.NoError(t, err)
+		parsed, err := ParseExtendedKey(encoded, RegTest)
+		require.NoError(t, err)
+		require.Equal(t, tpub, parsed.Key.String())
+
+		_, err = ParseExtendedKey(encoded, MainNet)
+		require.Error(t, err)
+	}
+}
+
+// TestParseExtendedKeyInvalid checks the rejected keys.
+func TestParseExtendedKeyInvalid(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewFromSeed(make([]byte, 32), MainNet)
+	require.NoError(t, err)
+
+	// Private keys aren't accepted as watch-only input.
+	_, err = ParseExtendedKey(w.MasterKey().String(), MainNet)
+	require.ErrorContains(t, err, "private")
+
+	_, err = ParseExtendedKey("zpub123", MainNet)
+	require.Error(t, err)
+
+	// Versions that aren't SLIP-132 ones are unsupported.
+	xpub, err := w.AccountXpub(BIP84Purpose, 0)
+	require.NoError(t, err)
+	parsed, err := ParseExtendedKey(xpub, MainNet)
+	require.NoError(t, err)
+	unknown, err := parsed.Key.CloneWithVersion(
+		[]byte{0x01, 0x02, 0x03, 0x04},
+	)
+	require.NoError(t, err)
+	_, err = ParseExtendedKey(unknown.String(), MainNet)
+	require.ErrorContains(t, err, "unsupported")
+}
+
+// TestSLIP132Multisig checks the round trip of the multisig Ypub/Zpub versions
+// and their Upub/Vpub test network variants through ParseExtendedKey.
+func TestSLIP132Multisig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		network *Network
+		purpose uint32
+		prefix  string
+		version []byte
+	}{
+		{MainNet, BIP49Purpose, "Ypub", []byte{0x02, 0x95, 0xb4, 0x3f}},
+		{MainNet, BIP84Purpose, "Zpub", []byte{0x02, 0xaa, 0x7e, 0xd3}},
+		{TestNet, BIP49Purpose, "Upub", []byte{0x02, 0x42, 0x89, 0xef}},
+		{TestNet, BIP84Purpose, "Vpub", []byte{0x02, 0x57, 0x54, 0x83}},
+	}
+	for _, test := range tests {
+		w, err := NewFromSeed(make([]byte, 32), test.network)
+		require.NoError(t, err)
+		xpub, err := w.AccountXpub(test.purpose, 0)
+		require.NoError(t, err)
+		key, err := ParseExtendedKey(xpub, test.network)
+		require.NoError(t, err)
+
+		encoded, err := EncodeSLIP132Multisig(
+			key.Key, test.purpose, test.network.Params,
+		)
+		require.NoError(t, err)
+		require.Equal(t, test.prefix, encoded[:4])
+
+		parsed, err := ParseExtendedKey(encoded, test.network)
+		require.NoError(t, err)
+		require.True(t, parsed.Multisig)
+		require.Equal(t, test.prefix, parsed.Prefix)
+		require.Equal(t, test.purpose, parsed.Purpose)
+		require.Equal(t, xpub, parsed.Key.String())
+
+		// The single key version of the purpose is another one.
+		singleEncoded, err := EncodeSLIP132(
+			key.Key, test.purpose, test.network.Params,
+		)
+		require.NoError(t, err)
+		require.NotEqual(t, encoded, singleEncoded)
+
+		raw, err := hdkeychain.NewKeyFromString(encoded)
+		require.NoError(t, err)
+		require.Equal(t, test.version, raw.Version())
+	}
+
+	// Mainnet multisig keys don't belong to the test networks.
+	w, err := NewFromSeed(make([]byte, 32), MainNet)
+	require.NoError(t, err)
+	zpub, err := w.AccountSLIP132(BIP84Purpose, 0)
+	require.NoError(t, err)
+	parsed, err := ParseExtendedKey(zpub, MainNet)
+	require.NoError(t, err)
+	multisig, err := EncodeSLIP132Multisig(
+		parsed.Key, BIP84Purpose, MainNet.Params,
+	)
+	require.NoError(t, err)
+	_, err = ParseExtendedKey(multisig, TestNet)
+	require.Error(t, err)
+}
@@ -0,0 +1,270 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// ScriptType is an address type, identified by the purpose whose addresses
+// are of that type.
+type ScriptType struct {
+	// Name is the short name of the script type, as used on the command
+	// line.
+	Name string
+
+	// Purpose is the BIP purpose that uses the script type.
+	Purpose uint32
+}
+
+// ScriptTypes lists the script types addresses can be generated for, in
+// display order.
+var ScriptTypes = []ScriptType{
+	{"p2pkh", BIP44Purpose},
+	{"p2sh-p2wpkh", BIP49Purpose},
+	{"p2wpkh", BIP84Purpose},
+	{"p2tr", BIP86Purpose},
+}
+
+// ScriptTypeByName looks up a script type by its name.
+func ScriptTypeByName(name string) (ScriptType, error) {
+	for _, t := range ScriptTypes {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+
+	return ScriptType{}, fmt.Errorf("unknown script type %q", name)
+}
+
+// PathTemplate is a BIP32 derivation path with a placeholder for an index,
+// such as m/84'/0'/0'/0/*. It allows exploring non-standard paths used by
+// other wallets.
+type PathTemplate struct {
+	// prefix and suffix are the child indexes before and after the
+	// placeholder.
+	prefix []uint32
+	suffix []uint32
+
+	// hardened marks a hardened placeholder, written as *'.
+	hardened bool
+}
+
+// ParsePathTemplate parses a path template. It must contain exactly one
+// placeholder element, *, which is hardened if marked with ' or h. Other
+// elements follow ParsePath.
+func ParsePathTemplate(template string) (*PathTemplate, error) {
+	elems := strings.Split(strings.TrimSpace(template), "/")
+	if len(elems) < 2 || elems[0] != "m" {
+		return nil, fmt.Errorf("invalid path template %q: must start "+
+			"with m/", template)
+	}
+
+	t := &PathTemplate{}
+	wildcard := -1
+	for i, elem := range elems[1:] {
+		switch elem {
+		case "*", "*'", "*h", "*H":
+			if wildcard != -1 {
+				return nil, fmt.Errorf("invalid path template "+
+					"%q: more than one *", template)
+			}
+			wildcard = i
+			t.hardened = elem != "*"
+		}
+	}
+	if wildcard == -1 {
+		return nil, fmt.Errorf("invalid path template %q: missing * "+
+			"for the index", template)
+	}
+
+	var err error
+	t.prefix, err = ParsePath(
+		strings.Join(append([]string{"m"}, elems[1:wildcard+1]...), "/"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %q: %w", template,
+			err)
+	}
+	t.suffix, err = ParsePath(
+		strings.Join(append([]string{"m"}, elems[wildcard+2:]...), "/"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %q: %w", template,
+			err)
+	}
+
+	return t, nil
+}
+
+// formatIndexes appends the path elements of child indexes to b.
+func formatIndexes(b *strings.Builder, indexes []uint32) {
+	for _, index := range indexes {
+		b.WriteByte('/')
+		if index >= hdkeychain.HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(
+				uint64(index-hdkeychain.HardenedKeyStart), 10,
+			))
+			b.WriteByte('\'')
+			continue
+		}
+		b.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+}
+
+// String returns the template in its canonical form.
+func (t *PathTemplate) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	formatIndexes(&b, t.prefix)
+	b.WriteString("/*")
+	if t.hardened {
+		b.WriteByte('\'')
+	}
+	formatIndexes(&b, t.suffix)
+
+	return b.String()
+}
+
+// Path returns the derivation path of the given index.
+func (t *PathTemplate) Path(index uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	formatIndexes(&b, t.prefix)
+	formatIndexes(&b, []uint32{t.childIndex(index)})
+	formatIndexes(&b, t.suffix)
+
+	return b.String()
+}
+
+// childIndex returns the child index the placeholder stands for.
+func (t *PathTemplate) childIndex(index uint32) uint32 {
+	if t.hardened {
+		return index + hdkeychain.HardenedKeyStart
+	}
+
+	return index
+}
+
+// deriveIndexes derives the child indexes from key.
+func deriveIndexes(key *hdkeychain.ExtendedKey,
+	indexes []uint32) (*hdkeychain.ExtendedKey, error) {
+
+	for _, index := range indexes {
+		var err error
+		key, err = key.Derive(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive child %d: %w",
+				index, err)
+		}
+	}
+
+	return key, nil
+}
+
+// templateDeriver derives the addresses of a template. The key up to the
+// placeholder is derived once.
+type templateDeriver struct {
+	w         *Wallet
+	t         *PathTemplate
+	script    ScriptType
+	parentKey *hdkeychain.ExtendedKey
+}
+
+func (w *Wallet) newTemplateDeriver(t *PathTemplate,
+	script ScriptType) (*templateDeriver, error) {
+
+	if w.masterKey == nil {
+		return nil, ErrWatchOnly
+	}
+	parentKey, err := deriveIndexes(w.masterKey, t.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateDeriver{
+		w: w, t: t, script: script, parentKey: parentKey,
+	}, nil
+}
+
+// derive derives the address of the given index.
+func (d *templateDeriver) derive(index uint32) (*DerivedAddress, error) {
+	if d.t.hardened && index >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("index %d out of the hardened range",
+			index)
+	}
+	key, err := deriveIndexes(
+		d.parentKey, append([]uint32{d.t.childIndex(index)},
+			d.t.suffix...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := GenerateAddress(key, d.script.Purpose, d.w.netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedAddress{
+		Purpose: d.script.Purpose,
+		Index:   index,
+		Path:    d.t.Path(index),
+		Address: addr,
+		Key:     key,
+	}, nil
+}
+
+// DeriveTemplateAddresses derives count addresses of the given script type
+// from a path template, starting at index start. The Purpose of the returned
+// addresses is the one of the script type, Account and Chain are zero.
+func (w *Wallet) DeriveTemplateAddresses(t *PathTemplate, script ScriptType,
+	start, count uint32) ([]*DerivedAddress, error) {
+
+	d, err := w.newTemplateDeriver(t, script)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]*DerivedAddress, 0, count)
+	for i := uint32(0); i < count; i++ {
+		addr, err := d.derive(start + i)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// FindTemplateAddress searches the indexes of a path template up to but
+// excluding searchLimit for an address of the given script type.
+// ErrAddressNotFound is returned if the address isn't found, and
+// ErrWrongNetwork if it belongs to another network.
+func (w *Wallet) FindTemplateAddress(address string, t *PathTemplate,
+	script ScriptType, searchLimit uint32) (*DerivedAddress, error) {
+
+	targetAddr, err := w.network.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	target := targetAddr.String()
+
+	d, err := w.newTemplateDeriver(t, script)
+	if err != nil {
+		return nil, err
+	}
+	for index := uint32(0); index < searchLimit; index++ {
+		addr, err := d.derive(index)
+		if err != nil {
+			return nil, err
+		}
+		if addr.Address.String() == target {
+			return addr, nil
+		}
+	}
+
+	return nil, ErrAddressNotFound
+}
@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePathTemplate checks the accepted and rejected templates and their
+// canonical form.
+func TestParsePathTemplate(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]string{
+		"m/84'/0'/0'/0/*": "m/84'/0'/0'/0/*",
+		"m/84h/0h/0h/1/*": "m/84'/0'/0'/1/*",
+		"m/0'/0'/*'":      "m/0'/0'/*'",
+		"m/0/*":           "m/0/*",
+		"m/*":             "m/*",
+		"m/*h/0":          "m/*'/0",
+	}
+	for template, canonical := range valid {
+		parsed, err := ParsePathTemplate(template)
+		require.NoError(t, err, template)
+		require.Equal(t, canonical, parsed.String())
+	}
+
+	for _, template := range []string{
+		"", "m", "m/84'/0'/0'/0/1", "m/*/*", "84'/*", "m/x/*", "m//*",
+		"m/2147483648/*",
+	} {
+		_, err := ParsePathTemplate(template)
+		require.Error(t, err, template)
+	}
+}
+
+// TestDeriveTemplateAddresses checks that templates of standard paths yield
+// the standard addresses and that hardened placeholders are derived
+// hardened.
+func TestDeriveTemplateAddresses(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	template, err := ParsePathTemplate("m/84'/0'/0'/1/*")
+	require.NoError(t, err)
+	script, err := ScriptTypeByName("P2WPKH")
+	require.NoError(t, err)
+
+	addrs, err := w.DeriveTemplateAddresses(template, script, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, addrs, 3)
+	for i, addr := range addrs {
+		want, err := w.DeriveAddress(
+			BIP84Purpose, 0, InternalChain, uint32(2+i),
+		)
+		require.NoError(t, err)
+		require.Equal(t, want.Path, addr.Path)
+		require.Equal(t, want.Address, addr.Address)
+		require.Equal(t, uint32(2+i), addr.Index)
+	}
+
+	// m/0'/0'/i' is the layout of old lnd and Bitcoin Core wallets. The
+	// key must match a plain derivation of the path.
+	template, err = ParsePathTemplate("m/0'/0'/*'")
+	require.NoError(t, err)
+	addrs, err = w.DeriveTemplateAddresses(template, ScriptTypes[0], 7, 1)
+	require.NoError(t, err)
+	require.Equal(t, "m/0'/0'/7'", addrs[0].Path)
+
+	path, err := ParsePath(addrs[0].Path)
+	require.NoError(t, err)
+	key, err := deriveIndexes(w.masterKey, path)
+	require.NoError(t, err)
+	want, err := GenerateLegacyAddress(key, w.netParams)
+	require.NoError(t, err)
+	require.Equal(t, want, addrs[0].Address)
+}
+
+// TestFindTemplateAddress checks the search of a template.
+func TestFindTemplateAddress(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	template, err := ParsePathTemplate("m/0/*")
+	require.NoError(t, err)
+	script, err := ScriptTypeByName("p2tr")
+	require.NoError(t, err)
+
+	addrs, err := w.DeriveTemplateAddresses(template, script, 4, 1)
+	require.NoError(t, err)
+	target := addrs[0].Address.String()
+
+	found, err := w.FindTemplateAddress(target, template, script, 5)
+	require.NoError(t, err)
+	require.Equal(t, "m/0/4", found.Path)
+
+	_, err = w.FindTemplateAddress(target, template, script, 4)
+	require.ErrorIs(t, err, ErrAddressNotFound)
+
+	// The same key as another script type is a different address.
+	_, err = w.FindTemplateAddress(target, template, ScriptTypes[2], 5)
+	require.ErrorIs(t, err, ErrAddressNotFound)
+
+	_, err = ScriptTypeByName("p2sh")
+	require.Error(t, err)
+}
@@ -0,0 +1,522 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	// BIP44Purpose is the purpose of legacy P2PKH derivation paths.
+	BIP44Purpose uint32 = 44
+
+	// BIP49Purpose is the purpose of nested P2SH-P2WPKH derivation paths.
+	BIP49Purpose uint32 = 49
+
+	// BIP84Purpose is the purpose of native P2WPKH derivation paths.
+	BIP84Purpose uint32 = 84
+
+	// BIP86Purpose is the purpose of key-path-only P2TR derivation paths.
+	BIP86Purpose uint32 = 86
+
+	// CoinTypeBitcoin is the BIP44 coin type of bitcoin mainnet.
+	CoinTypeBitcoin uint32 = 0
+
+	// DefaultAccount is the account used when none is specified.
+	DefaultAccount uint32 = 0
+
+	// MaxAccount is the highest account number, as accounts are hardened
+	// derivation indexes.
+	MaxAccount uint32 = hdkeychain.HardenedKeyStart - 1
+
+	// ExternalChain is the branch used for receive addresses.
+	ExternalChain uint32 = 0
+
+	// InternalChain is the branch used for change addresses.
+	InternalChain uint32 = 1
+)
+
+// PurposeInfo describes one of the supported BIP derivation schemes.
+type PurposeInfo struct {
+	// Purpose is the BIP purpose number, used as the first hardened path
+	// element.
+	Purpose uint32
+
+	// Name is a short human-readable name of the scheme.
+	Name string
+}
+
+// Purposes lists the derivation schemes a Wallet can generate addresses for,
+// in display order.
+var Purposes = []PurposeInfo{
+	{BIP44Purpose, "BIP44 (Legacy)"},
+	{BIP49Purpose, "BIP49 (Nested SegWit)"},
+	{BIP84Purpose, "BIP84 (Native SegWit)"},
+	{BIP86Purpose, "BIP86 (Taproot)"},
+}
+
+// DeriveChildKey derives the key at
+// m/purpose'/coinType'/account'/chain/index from an extended key.
+func DeriveChildKey(masterKey *hdkeychain.ExtendedKey, purpose, coinType,
+	account, chain, index uint32) (*hdkeychain.ExtendedKey, error) {
+
+	accountKey, err := DeriveAccountKey(masterKey, purpose, coinType, account)
+	if err != nil {
+		return nil, err
+	}
+	chainKey, err := accountKey.Derive(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive chain key: %w", err)
+	}
+	indexKey, err := chainKey.Derive(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive index key %d: %w",
+			index, err)
+	}
+
+	return indexKey, nil
+}
+
+// DeriveAccountKey derives the account-level extended key at
+// m/purpose'/coinType'/account'.
+func DeriveAccountKey(masterKey *hdkeychain.ExtendedKey, purpose, coinType,
+	account uint32) (*hdkeychain.ExtendedKey, error) {
+
+	if account > MaxAccount {
+		return nil, ErrInvalidAccount{Account: account}
+	}
+	purposeKey, err := masterKey.Derive(purpose + hdkeychain.HardenedKeyStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive purpose key: %w", err)
+	}
+	coinTypeKey, err := purposeKey.Derive(
+		coinType + hdkeychain.HardenedKeyStart,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive coin type key: %w", err)
+	}
+	accountKey, err := coinTypeKey.Derive(
+		account + hdkeychain.HardenedKeyStart,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive account key: %w", err)
+	}
+
+	return accountKey, nil
+}
+
+// DeriveAccountXpub derives the account-level extended public key (xpub) for
+// a given purpose, encoded for the given network.
+func DeriveAccountXpub(masterKey *hdkeychain.ExtendedKey, purpose, coinType,
+	account uint32, netParams *chaincfg.Params) (string, error) {
+
+	accountKey, err := DeriveAccountKey(masterKey, purpose, coinType, account)
+	if err != nil {
+		return "", err
+	}
+	xpubKey, err := accountKey.Neuter()
+	if err != nil {
+		return "", fmt.Errorf("failed to neuter account key: %w", err)
+	}
+	xpubKey.SetNet(netParams)
+
+	return xpubKey.String(), nil
+}
+
+// DerivedAddress is an address derived from a wallet along with the location
+// it was derived at.
+type DerivedAddress struct {
+	// Purpose, Account, Chain and Index locate the address within the
+	// wallet.
+	Purpose uint32
+	Account uint32
+	Chain   uint32
+	Index   uint32
+
+	// Path is the full BIP32 derivation path of the address.
+	Path string
+
+	// Address is the generated address.
+	Address btcutil.Address
+
+	// Key is the extended key the address was generated from.
+	Key *hdkeychain.ExtendedKey
+}
+
+// Wallet is an HD wallet rooted at the master key of an aezeed cipher seed,
+// or a watch-only wallet of a single account created by NewWatchOnly.
+// It is safe for concurrent use as it is never mutated after creation.
+type Wallet struct {
+	masterKey *hdkeychain.ExtendedKey
+	network   *Network
+	netParams *chaincfg.Params
+	coinType  uint32
+
+	// watchOnly is the account of a watch-only wallet, whose masterKey
+	// is nil.
+	watchOnly *watchedAccount
+}
+
+// watchedAccount is the account a watch-only wallet is built from.
+type watchedAccount struct {
+	purpose uint32
+	account uint32
+
+	// key is the extended public key of the account.
+	key *hdkeychain.ExtendedKey
+
+	// fingerprint is the fingerprint of the master key the account key
+	// was derived from, nil if it isn't known.
+	fingerprint []byte
+}
+
+// New creates a wallet for the given network from a deciphered cipher seed.
+// As done by lnd, the seed entropy is used directly as the BIP32 seed.
+func New(seed *crypto.CipherSeed, network *Network) (*Wallet, error) {
+	return NewFromSeed(seed.Entropy[:], network)
+}
+
+// NewFromSeed creates a wallet for the given network from raw BIP32 seed
+// bytes.
+func NewFromSeed(seed []byte, network *Network) (*Wallet, error) {
+	masterKey, err := hdkeychain.NewMaster(seed, network.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive master key: %w", err)
+	}
+
+	return &Wallet{
+		masterKey: masterKey,
+		network:   network,
+		netParams: network.Params,
+		coinType:  network.CoinType,
+	}, nil
+}
+
+// NewWatchOnly creates a watch-only wallet from the extended public key of
+// an account, such as m/84'/0'/0'. The account number is taken from the
+// key's child index and the script type from purpose. The master key
+// fingerprint of the key's origin is optional, but without it PSBTs can't
+// tell signers which seed the inputs belong to.
+//
+// Only the account of the key can be derived, and every feature that needs a
+// private key fails with ErrWatchOnly.
+func NewWatchOnly(purpose uint32, accountKey *hdkeychain.ExtendedKey,
+	masterFingerprint []byte, network *Network) (*Wallet, error) {
+
+	if _, err := purposeInfo(purpose); err != nil {
+		return nil, err
+	}
+	if accountKey.IsPrivate() {
+		return nil, fmt.Errorf("watch-only wallets take public keys")
+	}
+	if accountKey.Depth() != 3 ||
+		accountKey.ChildIndex() < hdkeychain.HardenedKeyStart {
+
+		return nil, fmt.Errorf("not an account key: depth %d, child "+
+			"%d", accountKey.Depth(), accountKey.ChildIndex())
+	}
+	if masterFingerprint != nil && len(masterFingerprint) != 4 {
+		return nil, fmt.Errorf("invalid master fingerprint %x",
+			masterFingerprint)
+	}
+
+	key, err := accountKey.CloneWithVersion(
+		network.Params.HDPublicKeyID[:],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Wallet{
+		network:   network,
+		netParams: network.Params,
+		coinType:  network.CoinType,
+		watchOnly: &watchedAccount{
+			purpose: purpose,
+			account: accountKey.ChildIndex() -
+				hdkeychain.HardenedKeyStart,
+			key:         key,
+			fingerprint: masterFingerprint,
+		},
+	}, nil
+}
+
+// purposeInfo returns the description of a supported purpose.
+func purposeInfo(purpose uint32) (PurposeInfo, error) {
+	for _, p := range Purposes {
+		if p.Purpose == purpose {
+			return p, nil
+		}
+	}
+
+	return PurposeInfo{}, ErrUnknownPurpose{Purpose: purpose}
+}
+
+// MasterKey returns the wallet's BIP32 root key, nil for watch-only wallets.
+func (w *Wallet) MasterKey() *hdkeychain.ExtendedKey {
+	return w.masterKey
+}
+
+// WatchOnly reports whether the wallet was created from an account public
+// key and has no private keys.
+func (w *Wallet) WatchOnly() bool {
+	return w.watchOnly != nil
+}
+
+// WatchedAccount returns the purpose and account of a watch-only wallet. ok
+// is false for wallets with a master key.
+func (w *Wallet) WatchedAccount() (purpose, account uint32, ok bool) {
+	if w.watchOnly == nil {
+		return 0, 0, false
+	}
+
+	return w.watchOnly.purpose, w.watchOnly.account, true
+}
+
+// Purposes returns the purposes the wallet can derive addresses for: every
+// supported purpose, or the one of the account of a watch-only wallet.
+func (w *Wallet) Purposes() []PurposeInfo {
+	if w.watchOnly == nil {
+		return Purposes
+	}
+
+	p, _ := purposeInfo(w.watchOnly.purpose)
+	return []PurposeInfo{p}
+}
+
+// HasAccount reports whether the wallet can derive the addresses of an
+// account.
+func (w *Wallet) HasAccount(purpose, account uint32) bool {
+	if w.watchOnly == nil {
+		return account <= MaxAccount
+	}
+
+	return purpose == w.watchOnly.purpose && account == w.watchOnly.account
+}
+
+// accountKey returns the extended key of an account, which is public for
+// watch-only wallets.
+func (w *Wallet) accountKey(purpose,
+	account uint32) (*hdkeychain.ExtendedKey, error) {
+
+	if w.watchOnly == nil {
+		return DeriveAccountKey(w.masterKey, purpose, w.coinType, account)
+	}
+	if !w.HasAccount(purpose, account) {
+		return nil, fmt.Errorf("account %s: %w",
+			w.AccountPath(purpose, account), ErrWatchOnly)
+	}
+
+	return w.watchOnly.key, nil
+}
+
+// Network returns the network the wallet generates addresses for.
+func (w *Wallet) Network() *Network {
+	return w.network
+}
+
+// NetParams returns the chain parameters of the wallet's network.
+func (w *Wallet) NetParams() *chaincfg.Params {
+	return w.netParams
+}
+
+// CoinType returns the BIP44 coin type used in the wallet's derivation paths.
+func (w *Wallet) CoinType() uint32 {
+	return w.coinType
+}
+
+// MasterFingerprint returns the hex encoded BIP32 fingerprint of the master
+// key, as expected by watch-only wallets such as Sparrow.
+func (w *Wallet) MasterFingerprint() (string, error) {
+	fingerprint, err := w.masterFingerprint()
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(fingerprint), nil
+}
+
+// MasterKeyFingerprint returns the BIP32 fingerprint of the master key as the
+// integer used in PSBT key origins, which holds the fingerprint bytes in
+// little-endian order.
+func (w *Wallet) MasterKeyFingerprint() (uint32, error) {
+	fingerprint, err := w.masterFingerprint()
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(fingerprint), nil
+}
+
+// masterFingerprint returns the first four bytes of the hash160 of the master
+// public key. Watch-only wallets return the fingerprint they were created
+// with, if any.
+func (w *Wallet) masterFingerprint() ([]byte, error) {
+	if w.watchOnly != nil {
+		if w.watchOnly.fingerprint == nil {
+			return nil, fmt.Errorf("master fingerprint unknown: %w",
+				ErrWatchOnly)
+		}
+		return w.watchOnly.fingerprint, nil
+	}
+
+	pubKey, err := w.masterKey.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master public key: %w", err)
+	}
+
+	return btcutil.Hash160(pubKey.SerializeCompressed())[:4], nil
+}
+
+// AccountPath returns the derivation path of an account.
+func (w *Wallet) AccountPath(purpose, account uint32) string {
+	return fmt.Sprintf("m/%d'/%d'/%d'", purpose, w.coinType, account)
+}
+
+// AccountXpub returns the extended public key of an account.
+func (w *Wallet) AccountXpub(purpose, account uint32) (string, error) {
+	if w.watchOnly == nil {
+		return DeriveAccountXpub(
+			w.masterKey, purpose, w.coinType, account, w.netParams,
+		)
+	}
+
+	key, err := w.accountKey(purpose, account)
+	if err != nil {
+		return "", err
+	}
+
+	return key.String(), nil
+}
+
+// DeriveAddress derives the address at
+// m/purpose'/coinType'/account'/chain/index.
+func (w *Wallet) DeriveAddress(purpose, account, chain,
+	index uint32) (*DerivedAddress, error) {
+
+	accountKey, err := w.accountKey(purpose, account)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveIndexes(accountKey, []uint32{chain, index})
+	if err != nil {
+		return nil, err
+	}
+	addr, err := GenerateAddress(key, purpose, w.netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedAddress{
+		Purpose: purpose,
+		Account: account,
+		Chain:   chain,
+		Index:   index,
+		Path: fmt.Sprintf("%s/%d/%d", w.AccountPath(purpose, account),
+			chain, index),
+		Address: addr,
+		Key:     key,
+	}, nil
+}
+
+// FindAddress searches the default account of every supported purpose, on
+// both the external and internal chain, for the given address. Indexes up to
+// but excluding searchLimit are tried. ErrAddressNotFound is returned if the
+// address isn't part of the searched range, and ErrWrongNetwork if it belongs
+// to another network.
+func (w *Wallet) FindAddress(address string,
+	searchLimit uint32) (*DerivedAddress, error) {
+
+	return w.FindAddressInAccounts(
+		address, DefaultAccount, DefaultAccount, searchLimit,
+	)
+}
+
+// FindAddressInAccounts is like FindAddress, but searches the accounts from
+// firstAccount up to and including lastAccount. Lower accounts are searched
+// first, as they are far more likely to be used.
+func (w *Wallet) FindAddressInAccounts(address string, firstAccount,
+	lastAccount, searchLimit uint32) (*DerivedAddress, error) {
+
+	if lastAccount > MaxAccount {
+		return nil, ErrInvalidAccount{Account: lastAccount}
+	}
+	targetAddr, err := w.network.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	target := targetAddr.String()
+
+	for account := firstAccount; account <= lastAccount; account++ {
+		found, err := w.findInAccount(target, account, searchLimit)
+		if err != nil || found != nil {
+			return found, err
+		}
+	}
+
+	return nil, ErrAddressNotFound
+}
+
+// findInAccount searches an account of every supported purpose for the
+// encoded address, returning nil if it isn't found.
+func (w *Wallet) findInAccount(target string, account,
+	searchLimit uint32) (*DerivedAddress, error) {
+
+	// Derive the account keys once up front, so each candidate address
+	// only costs two non-hardened derivations.
+	for _, p := range w.Purposes() {
+		if !w.HasAccount(p.Purpose, account) {
+			continue
+		}
+		accountKey, err := w.accountKey(p.Purpose, account)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, chain := range []uint32{ExternalChain, InternalChain} {
+			chainKey, err := accountKey.Derive(chain)
+			if err != nil {
+				return nil, fmt.Errorf("failed to derive chain "+
+					"key: %w", err)
+			}
+
+			for index := uint32(0); index < searchLimit; index++ {
+				key, err := chainKey.Derive(index)
+				if err != nil {
+					// Invalid child keys are astronomically
+					// rare and simply skipped, as wallets do.
+					continue
+				}
+				addr, err := GenerateAddress(
+					key, p.Purpose, w.netParams,
+				)
+				if err != nil {
+					return nil, err
+				}
+				if addr.String() != target {
+					continue
+				}
+
+				return &DerivedAddress{
+					Purpose: p.Purpose,
+					Account: account,
+					Chain:   chain,
+					Index:   index,
+					Path: fmt.Sprintf("%s/%d/%d",
+						w.AccountPath(p.Purpose, account),
+						chain, index),
+					Address: addr,
+					Key:     key,
+				}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
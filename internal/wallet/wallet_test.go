@@ -0,0 +1,327 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"aezeed_address_generator_gui/internal/crypto"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testBIP39Seed is the BIP39 seed of the mnemonic "abandon abandon
+	// abandon abandon abandon abandon abandon abandon abandon abandon
+	// abandon about" without passphrase, which is used by the test
+	// vectors of BIP44, BIP49, BIP84 and BIP86.
+	testBIP39Seed, _ = hex.DecodeString(
+		"5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc1" +
+			"9a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4",
+	)
+
+	// testEntropy is the entropy of the aezeed test vectors.
+	testEntropy = [crypto.EntropySize]byte{
+		0x81, 0xb6, 0x37, 0xd8,
+		0x63, 0x59, 0xe6, 0x96,
+		0x0d, 0xe7, 0x95, 0xe4,
+		0x1e, 0x0b, 0x4c, 0xfd,
+	}
+)
+
+type addressVector struct {
+	purpose uint32
+	chain   uint32
+	index   uint32
+	path    string
+	address string
+}
+
+// addressVectors are taken from the test vectors of the respective BIPs.
+var addressVectors = []addressVector{{
+	purpose: BIP44Purpose,
+	path:    "m/44'/0'/0'/0/0",
+	address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+}, {
+	purpose: BIP49Purpose,
+	path:    "m/49'/0'/0'/0/0",
+	address: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf",
+}, {
+	purpose: BIP84Purpose,
+	path:    "m/84'/0'/0'/0/0",
+	address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+}, {
+	purpose: BIP84Purpose,
+	index:   1,
+	path:    "m/84'/0'/0'/0/1",
+	address: "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g",
+}, {
+	purpose: BIP84Purpose,
+	chain:   InternalChain,
+	path:    "m/84'/0'/0'/1/0",
+	address: "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el",
+}, {
+	purpose: BIP86Purpose,
+	path:    "m/86'/0'/0'/0/0",
+	address: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+}}
+
+func newTestWallet(t *testing.T) *Wallet {
+	t.Helper()
+
+	w, err := NewFromSeed(testBIP39Seed, MainNet)
+	require.NoError(t, err)
+
+	return w
+}
+
+// TestDeriveAddressVectors checks address derivation for every purpose
+// against the BIP test vectors.
+func TestDeriveAddressVectors(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	for _, v := range addressVectors {
+		derived, err := w.DeriveAddress(
+			v.purpose, DefaultAccount, v.chain, v.index,
+		)
+		require.NoError(t, err)
+		require.Equal(t, v.path, derived.Path)
+		require.Equal(t, v.address, derived.Address.String())
+	}
+}
+
+// TestAccountXpubVectors checks the account extended public keys against the
+// BIP84 and BIP86 test vectors.
+func TestAccountXpubVectors(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+
+	xpub, err := w.AccountXpub(BIP86Purpose, DefaultAccount)
+	require.NoError(t, err)
+	require.Equal(
+		t, "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afY"+
+			"WcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ",
+		xpub,
+	)
+
+	// The account key must derive the same addresses as the wallet.
+	xpub, err = w.AccountXpub(BIP84Purpose, DefaultAccount)
+	require.NoError(t, err)
+	accountKey, err := hdkeychain.NewKeyFromString(xpub)
+	require.NoError(t, err)
+	chainKey, err := accountKey.Derive(ExternalChain)
+	require.NoError(t, err)
+	key, err := chainKey.Derive(0)
+	require.NoError(t, err)
+	addr, err := GenerateNativeSegWitAddress(key, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		addr.String())
+}
+
+// TestMasterFingerprint checks the fingerprint of the BIP39 test seed.
+func TestMasterFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fingerprint, err := newTestWallet(t).MasterFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, "73c5da0a", fingerprint)
+
+	// PSBTs store the same bytes as a little-endian integer.
+	psbtFingerprint, err := newTestWallet(t).MasterKeyFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x0adac573), psbtFingerprint)
+}
+
+// TestParsePath checks the parsing of derivation paths.
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	const h = hdkeychain.HardenedKeyStart
+	indexes, err := ParsePath("m/84'/0h/0H/1/7")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{84 + h, h, h, 1, 7}, indexes)
+
+	indexes, err = ParsePath("m")
+	require.NoError(t, err)
+	require.Empty(t, indexes)
+
+	for _, path := range []string{
+		"", "84'/0'", "m/", "m/x", "m/-1", "m/2147483648",
+	} {
+		_, err := ParsePath(path)
+		require.Error(t, err, path)
+	}
+
+	// Derived addresses carry a path that parses.
+	addr, err := newTestWallet(t).DeriveAddress(BIP86Purpose, 0, 1, 3)
+	require.NoError(t, err)
+	indexes, err = ParsePath(addr.Path)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{86 + h, h, h, 1, 3}, indexes)
+}
+
+// TestNewFromCipherSeed makes sure a wallet built from a cipher seed uses the
+// seed's entropy as the BIP32 seed, like lnd does.
+func TestNewFromCipherSeed(t *testing.T) {
+	t.Parallel()
+
+	seed, err := crypto.New(0, &testEntropy, time.Unix(1521799345, 0))
+	require.NoError(t, err)
+
+	w, err := New(seed, MainNet)
+	require.NoError(t, err)
+
+	fromEntropy, err := NewFromSeed(testEntropy[:], MainNet)
+	require.NoError(t, err)
+	require.Equal(
+		t, fromEntropy.MasterKey().String(), w.MasterKey().String(),
+	)
+}
+
+// TestFindAddress checks that addresses are located on both chains and that
+// addresses outside the search range are reported as not found.
+func TestFindAddress(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	for _, v := range addressVectors {
+		found, err := w.FindAddress(v.address, 5)
+		require.NoError(t, err)
+		require.Equal(t, v.path, found.Path)
+		require.Equal(t, v.purpose, found.Purpose)
+		require.Equal(t, v.chain, found.Chain)
+		require.Equal(t, v.index, found.Index)
+	}
+
+	// The second receive address isn't part of a search limited to the
+	// first index.
+	_, err := w.FindAddress(addressVectors[3].address, 1)
+	require.ErrorIs(t, err, ErrAddressNotFound)
+
+	_, err = w.FindAddress("not-an-address", 1)
+	require.Error(t, err)
+}
+
+// TestFindAddressInAccounts checks that a range of accounts is searched and
+// that the account is part of the result.
+func TestFindAddressInAccounts(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	want, err := w.DeriveAddress(BIP84Purpose, 3, InternalChain, 2)
+	require.NoError(t, err)
+
+	_, err = w.FindAddress(want.Address.String(), 5)
+	require.ErrorIs(t, err, ErrAddressNotFound)
+	_, err = w.FindAddressInAccounts(want.Address.String(), 0, 2, 5)
+	require.ErrorIs(t, err, ErrAddressNotFound)
+
+	found, err := w.FindAddressInAccounts(want.Address.String(), 1, 4, 5)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), found.Account)
+	require.Equal(t, want.Path, found.Path)
+
+	_, err = w.FindAddressInAccounts(
+		want.Address.String(), 0, MaxAccount+1, 5,
+	)
+	require.ErrorIs(t, err, ErrInvalidAccount{Account: MaxAccount + 1})
+}
+
+// TestDeriveInvalidAccount checks that accounts beyond the hardened range
+// are rejected instead of wrapping around.
+func TestDeriveInvalidAccount(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWallet(t)
+	_, err := w.DeriveAddress(BIP84Purpose, MaxAccount+1, 0, 0)
+	require.ErrorIs(t, err, ErrInvalidAccount{Account: MaxAccount + 1})
+
+	_, err = w.AccountXpub(BIP84Purpose, MaxAccount)
+	require.NoError(t, err)
+}
+
+// TestGenerateAddressUnknownPurpose checks that unknown purposes are rejected.
+func TestGenerateAddressUnknownPurpose(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestWallet(t).DeriveAddress(45, DefaultAccount, 0, 0)
+	require.ErrorIs(t, err, ErrUnknownPurpose{Purpose: 45})
+}
+
+// TestWatchOnly checks that a watch-only wallet derives the addresses of its
+// account like the full wallet, and nothing else.
+func TestWatchOnly(t *testing.T) {
+	t.Parallel()
+
+	full := newTestWallet(t)
+	xpub, err := full.AccountXpub(BIP84Purpose, 2)
+	require.NoError(t, err)
+	parsed, err := ParseExtendedKey(xpub, MainNet)
+	require.NoError(t, err)
+
+	w, err := NewWatchOnly(BIP84Purpose, parsed.Key, nil, MainNet)
+	require.NoError(t, err)
+	require.True(t, w.WatchOnly())
+	require.Nil(t, w.MasterKey())
+	purpose, account, ok := w.WatchedAccount()
+	require.True(t, ok)
+	require.Equal(t, BIP84Purpose, purpose)
+	require.Equal(t, uint32(2), account)
+	require.Equal(t, []PurposeInfo{Purposes[2]}, w.Purposes())
+
+	got, err := w.AccountXpub(BIP84Purpose, 2)
+	require.NoError(t, err)
+	require.Equal(t, xpub, got)
+
+	want, err := full.DeriveAddress(BIP84Purpose, 2, InternalChain, 7)
+	require.NoError(t, err)
+	addr, err := w.DeriveAddress(BIP84Purpose, 2, InternalChain, 7)
+	require.NoError(t, err)
+	require.Equal(t, want.Path, addr.Path)
+	require.Equal(t, want.Address, addr.Address)
+	require.False(t, addr.Key.IsPrivate())
+
+	found, err := w.FindAddressInAccounts(want.Address.String(), 0, 3, 10)
+	require.NoError(t, err)
+	require.Equal(t, want.Path, found.Path)
+
+	// Other accounts and private keys aren't available.
+	_, err = w.DeriveAddress(BIP84Purpose, 0, ExternalChain, 0)
+	require.ErrorIs(t, err, ErrWatchOnly)
+	_, err = w.DeriveAddress(BIP49Purpose, 2, ExternalChain, 0)
+	require.ErrorIs(t, err, ErrWatchOnly)
+	_, err = w.NodePubKey()
+	require.ErrorIs(t, err, ErrWatchOnly)
+	_, err = w.MasterFingerprint()
+	require.ErrorIs(t, err, ErrWatchOnly)
+	template, err := ParsePathTemplate("m/0/*")
+	require.NoError(t, err)
+	_, err = w.DeriveTemplateAddresses(template, ScriptTypes[0], 0, 1)
+	require.ErrorIs(t, err, ErrWatchOnly)
+
+	// The fingerprint of the key origin is kept.
+	fingerprint, err := full.MasterKeyFingerprint()
+	require.NoError(t, err)
+	fingerprintBytes, err := full.masterFingerprint()
+	require.NoError(t, err)
+	w, err = NewWatchOnly(BIP84Purpose, parsed.Key, fingerprintBytes, MainNet)
+	require.NoError(t, err)
+	got32, err := w.MasterKeyFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, got32)
+
+	// Only account level public keys are accepted.
+	_, err = NewWatchOnly(BIP84Purpose, full.MasterKey(), nil, MainNet)
+	require.Error(t, err)
+	masterPub, err := full.MasterKey().Neuter()
+	require.NoError(t, err)
+	_, err = NewWatchOnly(BIP84Purpose, masterPub, nil, MainNet)
+	require.ErrorContains(t, err, "not an account key")
+	_, err = NewWatchOnly(45, parsed.Key, nil, MainNet)
+	require.ErrorIs(t, err, ErrUnknownPurpose{Purpose: 45})
+}
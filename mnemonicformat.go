@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/bip39"
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// currentBIP39Seed is the BIP32 seed of the loaded BIP39 mnemonic, kept to
+// rebuild the wallet when the network changes. It is nil for aezeed and
+// watch-only wallets.
+var currentBIP39Seed []byte
+
+// mnemonicFormat tells which wallet formats a phrase is valid in. aezeed and
+// BIP39 share the same word list, so a phrase can look like either.
+type mnemonicFormat struct {
+	words []string
+
+	// unknown are the words that aren't in the word list.
+	unknown []string
+
+	// aezeed reports whether the phrase has 24 words and a valid aezeed
+	// checksum. Only deciphering it tells whether the passphrase is right.
+	aezeed bool
+
+	// bip39 reports whether the phrase has a valid BIP39 checksum.
+	bip39 bool
+}
+
+// analyzeMnemonic checks a phrase against both formats.
+func analyzeMnemonic(mnemonicStr string) mnemonicFormat {
+	f := mnemonicFormat{words: strings.Fields(mnemonicStr)}
+	for _, word := range f.words {
+		if _, ok := crypto.ReverseWordMap[word]; !ok {
+			f.unknown = append(f.unknown, word)
+		}
+	}
+
+	if len(f.words) == crypto.NumMnemonicWords {
+		var mnemonic crypto.Mnemonic
+		copy(mnemonic[:], f.words)
+		f.aezeed = mnemonic.ChecksumValid()
+	}
+	_, err := bip39.Entropy(f.words)
+	f.bip39 = err == nil
+
+	return f
+}
+
+// isBIP39 reports whether the phrase is to be loaded as a BIP39 mnemonic. A
+// phrase valid in both formats is an aezeed: a BIP39 phrase passes the 32-bit
+// aezeed checksum by chance once in about 4 billion, while an aezeed passes
+// the BIP39 checksum of 24 words once in 256.
+func (f mnemonicFormat) isBIP39() bool {
+	return f.bip39 && !f.aezeed
+}
+
+// newBIP39Wallet creates the wallet of a BIP39 mnemonic and its passphrase,
+// which, unlike the aezeed one, has no default.
+func newBIP39Wallet(words []string, passphrase string, network *wallet.Network) ([]byte, *wallet.Wallet, error) {
+	if _, err := bip39.Entropy(words); err != nil {
+		return nil, nil, describeBIP39Error(err)
+	}
+	seed := bip39.Seed(words, passphrase)
+	w, err := wallet.NewFromSeed(seed, network)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao derivar chave mestra BIP39: %w", err)
+	}
+	return seed, w, nil
+}
+
+// describeBIP39Error explains why a phrase isn't a valid BIP39 mnemonic.
+func describeBIP39Error(err error) error {
+	var wordErr crypto.ErrUnknownMnemonicWord
+	switch {
+	case errors.Is(err, bip39.ErrInvalidChecksum):
+		return errors.New("o checksum BIP39 é inválido")
+	case errors.As(err, &wordErr):
+		return fmt.Errorf("a palavra %d (%q) não está na lista", wordErr.Index+1, wordErr.Word)
+	default:
+		return fmt.Errorf("mnemônico BIP39 inválido: %w", err)
+	}
+}
+
+// mnemonicReport tells which formats a phrase is valid in and what each valid
+// interpretation deciphers to with the passphrase.
+type mnemonicReport struct {
+	Words        int      `json:"words"`
+	UnknownWords []string `json:"unknown_words,omitempty"`
+
+	// Format is "aezeed", "bip39", "both" or "none".
+	Format string `json:"format"`
+
+	AezeedChecksum    bool   `json:"aezeed_checksum"`
+	AezeedDeciphered  bool   `json:"aezeed_deciphered"`
+	AezeedFingerprint string `json:"aezeed_master_fingerprint,omitempty"`
+	Birthday          string `json:"birthday,omitempty"`
+
+	BIP39            bool   `json:"bip39"`
+	BIP39Error       string `json:"bip39_error,omitempty"`
+	BIP39Fingerprint string `json:"bip39_master_fingerprint,omitempty"`
+}
+
+// newMnemonicReport checks a phrase against both formats, deciphering each
+// valid interpretation with the passphrase.
+func newMnemonicReport(f mnemonicFormat, passphrase string) mnemonicReport {
+	r := mnemonicReport{
+		Words:          len(f.words),
+		UnknownWords:   f.unknown,
+		AezeedChecksum: f.aezeed,
+		BIP39:          f.bip39,
+	}
+	switch {
+	case f.aezeed && f.bip39:
+		r.Format = "both"
+	case f.aezeed:
+		r.Format = "aezeed"
+	case f.bip39:
+		r.Format = "bip39"
+	default:
+		r.Format = "none"
+	}
+
+	if f.aezeed {
+		seed, err := decodeMnemonic(strings.Join(f.words, " "), passphraseOrDefault(passphrase))
+		if err == nil {
+			r.AezeedDeciphered = true
+			r.AezeedFingerprint = seedFingerprint(seed)
+			r.Birthday = formatBirthday(seed)
+		}
+	}
+	if _, w, err := newBIP39Wallet(f.words, passphrase, currentNetwork); err != nil {
+		r.BIP39Error = err.Error()
+	} else {
+		r.BIP39Fingerprint, _ = w.MasterFingerprint()
+	}
+
+	return r
+}
+
+// writeMnemonicReport writes a format report as text.
+func writeMnemonicReport(out io.Writer, r mnemonicReport, passphrase string) {
+	fmt.Fprintf(out, "Palavras: %d\n", r.Words)
+	if len(r.UnknownWords) > 0 {
+		fmt.Fprintf(out, "Palavras fora da lista: %s\n", strings.Join(r.UnknownWords, ", "))
+	}
+
+	fmt.Fprint(out, "\naezeed (LND): ")
+	switch {
+	case r.Words != crypto.NumMnemonicWords:
+		fmt.Fprintf(out, "não, o aezeed tem sempre %d palavras.\n", crypto.NumMnemonicWords)
+	case !r.AezeedChecksum:
+		fmt.Fprintln(out, "não, o checksum aezeed é inválido.")
+	case !r.AezeedDeciphered:
+		fmt.Fprintln(out, "checksum válido, mas a passphrase não o decifra.")
+	default:
+		fmt.Fprintln(out, "válido, decifrado com a passphrase.")
+		fmt.Fprintf(out, "  Master Fingerprint: %s\n", r.AezeedFingerprint)
+		fmt.Fprintf(out, "  Aniversário: %s\n", r.Birthday)
+	}
+
+	fmt.Fprint(out, "\nBIP39: ")
+	if !r.BIP39 {
+		fmt.Fprintf(out, "não, %s.\n", r.BIP39Error)
+	} else {
+		fmt.Fprintln(out, "válido.")
+		fmt.Fprintf(out, "  Master Fingerprint: %s (passphrase BIP39 %s)\n", r.BIP39Fingerprint, describeBIP39Passphrase(passphrase))
+	}
+
+	fmt.Fprint(out, "\nConclusão: ")
+	switch r.Format {
+	case "both":
+		fmt.Fprintln(out, "válido nos dois formatos. A frase será carregada como aezeed, pois uma frase BIP39 só passa no checksum aezeed por acaso (1 em 4 bilhões), enquanto um aezeed passa no checksum BIP39 de 24 palavras 1 vez em 256.")
+	case "aezeed":
+		fmt.Fprintln(out, "mnemônico aezeed, como os gerados pelo LND.")
+	case "bip39":
+		fmt.Fprintln(out, "mnemônico BIP39, como os da maioria das carteiras. Ele será carregado como BIP39, sem aniversário.")
+	default:
+		fmt.Fprintln(out, "inválido nos dois formatos. Use a recuperação para procurar erros de transcrição.")
+	}
+	fmt.Fprintln(out, "\nAs mesmas palavras nunca geram a mesma carteira nos dois formatos: o aezeed cifra a entropia com a passphrase, enquanto o BIP39 deriva a seed das próprias palavras.")
+}
+
+// describeBIP39Passphrase tells whether a BIP39 passphrase was given, without
+// showing it.
+func describeBIP39Passphrase(passphrase string) string {
+	if passphrase == "" {
+		return "vazia"
+	}
+	return "informada"
+}
+
+// --- CLI ---
+
+func runSeedAnalyze(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "seed analyze")
+	var flags seedFlags
+	flags.register(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+	mnemonicStr, err := flags.readMnemonic(env)
+	if err != nil {
+		return err
+	}
+
+	passphrase := flags.rawPassphrase()
+	report := newMnemonicReport(analyzeMnemonic(mnemonicStr), passphrase)
+	if flags.jsonOut {
+		return writeJSON(env.stdout, report)
+	}
+	writeMnemonicReport(env.stdout, report, passphrase)
+	return nil
+}
+
+// --- GUI ---
+
+// loadBIP39MnemonicAndAddresses loads the wallet of the BIP39 mnemonic in the
+// entry, with the passphrase entry as its BIP39 passphrase.
+func loadBIP39MnemonicAndAddresses(f mnemonicFormat) {
+	seed, w, err := newBIP39Wallet(f.words, passphraseEntry.Text, currentNetwork)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		updateXPUBDisplay()
+		return
+	}
+	currentSeed = nil
+	currentBIP39Seed = seed
+	currentWallet = w
+	currentBatchStart = 0
+
+	updateXPUBDisplay()
+	updateAddressGrid()
+	loadMoreButton.Enable()
+	if selectedBackend != nil {
+		verificationButtons.Show()
+	}
+	showStatus(fmt.Sprintf("Mnemônico BIP39 de %d palavras carregado (passphrase BIP39 %s).", len(f.words), describeBIP39Passphrase(passphraseEntry.Text)), false)
+}
+
+// showMnemonicReport shows which formats the phrase in the entry is valid in.
+func showMnemonicReport() {
+	if strings.TrimSpace(mnemonicEntry.Text) == "" {
+		showStatus("Erro: Cole o mnemônico a analisar.", true)
+		return
+	}
+	var text strings.Builder
+	report := newMnemonicReport(analyzeMnemonic(mnemonicEntry.Text), passphraseEntry.Text)
+	writeMnemonicReport(&text, report, passphraseEntry.Text)
+
+	reportLabel := widget.NewLabel(text.String())
+	reportLabel.Wrapping = fyne.TextWrapWord
+	reportScroll := container.NewScroll(reportLabel)
+	reportScroll.SetMinSize(fyne.NewSize(600, 400))
+	dialog.ShowCustom("Formato do Mnemônico", "Fechar", reportScroll, mainWindow)
+}
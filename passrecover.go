@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"aezeed_address_generator_gui/internal/config"
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/recovery"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// passphraseStateFile is the file in the configuration directory that
+	// keeps the progress of an interrupted passphrase search.
+	passphraseStateFile = "passphrase-recovery.json"
+
+	// passphraseStateInterval is how often the progress of a passphrase
+	// search is saved while it runs.
+	passphraseStateInterval = 10 * time.Second
+)
+
+// defaultPassphraseStatePath returns where the progress of passphrase
+// searches is saved, next to the settings file.
+func defaultPassphraseStatePath() string {
+	path, err := config.DefaultPath()
+	if err != nil {
+		log.Printf("Diretório de configuração indisponível: %v", err)
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), passphraseStateFile)
+}
+
+// readWordlist reads the candidate passphrases of a word list, one per line,
+// most likely first. Only the line endings are removed, since spaces may be
+// part of a passphrase.
+func readWordlist(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimRight(scanner.Text(), "\r")
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler lista de palavras: %w", err)
+	}
+	return words, nil
+}
+
+// recoverPassphrase runs a passphrase search of a mnemonic, resuming it from
+// the state saved at statePath by an interrupted run of the same search
+// unless restart is set. The progress is saved there periodically and when
+// the search is interrupted, and removed once it ends. It returns the number
+// of candidates skipped by resuming.
+func recoverPassphrase(ctx context.Context, mnemonic crypto.Mnemonic, cfg *recovery.PassphraseConfig, statePath string, restart bool) (*recovery.PassphraseResult, int, error) {
+	searchID := cfg.SearchID(mnemonic)
+	if statePath != "" && !restart {
+		state, err := recovery.LoadPassphraseState(statePath)
+		if err != nil {
+			log.Printf("Estado da busca de passphrase ignorado: %v", err)
+		} else if state != nil && state.Search == searchID {
+			cfg.Skip = state.Checkpoint
+		}
+	}
+
+	saveState := func(checkpoint, total int) {
+		if statePath == "" {
+			return
+		}
+		state := &recovery.PassphraseState{Search: searchID, Checkpoint: checkpoint, Total: total}
+		if err := state.Save(statePath); err != nil {
+			log.Printf("Erro ao salvar o estado da busca de passphrase: %v", err)
+		}
+	}
+
+	progress := cfg.Progress
+	lastSave := time.Now()
+	cfg.Progress = func(p recovery.PassphraseProgress) {
+		if time.Since(lastSave) >= passphraseStateInterval {
+			saveState(p.Checkpoint, p.Total)
+			lastSave = time.Now()
+		}
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	result, err := recovery.RecoverPassphrase(ctx, mnemonic, cfg)
+	var wordErr crypto.ErrUnknownMnemonicWord
+	switch {
+	case errors.Is(err, crypto.ErrIncorrectMnemonic), errors.As(err, &wordErr):
+		return nil, 0, errors.New("o checksum do mnemônico é inválido: recupere as palavras antes da passphrase")
+	case errors.Is(err, context.Canceled):
+		saveState(result.Checkpoint, result.Total)
+		return result, cfg.Skip, err
+	case err != nil:
+		return nil, 0, fmt.Errorf("erro na busca da passphrase: %w", err)
+	}
+
+	if statePath != "" {
+		if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Erro ao remover o estado da busca de passphrase: %v", err)
+		}
+	}
+	return result, cfg.Skip, nil
+}
+
+// formatPassphraseProgress describes the progress of a passphrase search,
+// with the estimated time left.
+func formatPassphraseProgress(p recovery.PassphraseProgress) string {
+	eta := "calculando..."
+	if p.Remaining > 0 {
+		eta = p.Remaining.Round(time.Second).String()
+	}
+	return fmt.Sprintf("Testadas: %d de %d (%.1f%%), tempo restante estimado: %s", p.Tried, p.Total, 100*float64(p.Tried)/float64(max(p.Total, 1)), eta)
+}
+
+// writePassphraseResult writes the outcome of a passphrase search as text.
+func writePassphraseResult(out io.Writer, result *recovery.PassphraseResult, skipped int) {
+	if skipped > 0 {
+		fmt.Fprintf(out, "Busca retomada após %d candidatas já testadas.\n", skipped)
+	}
+	fmt.Fprintf(out, "Candidatas testadas: %d de %d\n", result.Tried, result.Total)
+	if !result.Found {
+		fmt.Fprintln(out, "Nenhuma passphrase encontrada.")
+		return
+	}
+	fmt.Fprintf(out, "Passphrase encontrada: %q\n", result.Passphrase)
+	fmt.Fprintf(out, "Master Fingerprint: %s\n", seedFingerprint(result.Seed))
+	fmt.Fprintf(out, "Aniversário: %s\n", formatBirthday(result.Seed))
+}
+
+// --- CLI ---
+
+func runPassphraseRecover(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "passphrase recover")
+	var flags seedFlags
+	fs.StringVar(&flags.mnemonic, "mnemonic", "", "mnemônico aezeed de 24 palavras")
+	fs.StringVar(&flags.network, "network", wallet.MainNet.Name, "rede: mainnet, testnet, signet ou regtest")
+	fs.BoolVar(&flags.jsonOut, "json", false, "saída em JSON")
+	var words stringList
+	fs.Var(&words, "word", "passphrase candidata, pode ser repetida")
+	wordlist := fs.String("wordlist", "", "arquivo com uma passphrase candidata por linha, as mais prováveis primeiro")
+	prefix := fs.String("prefix", "", "início conhecido da passphrase")
+	capitalize := fs.Bool("capitalize", false, "testa também minúsculas, maiúsculas e a primeira letra maiúscula")
+	digits := fs.Int("digits", 0, fmt.Sprintf("testa também números de até N dígitos no final (máx. %d)", recovery.MaxTailLen))
+	suffixChars := fs.String("suffix-chars", "", "caracteres de um final desconhecido")
+	suffixLen := fs.Int("suffix-len", 0, fmt.Sprintf("tamanho máximo do final desconhecido (máx. %d)", recovery.MaxTailLen))
+	statePath := fs.String("state", defaultPassphraseStatePath(), "arquivo onde o progresso é salvo para retomar a busca")
+	restart := fs.Bool("restart", false, "ignora o progresso salvo e recomeça a busca")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+
+	cfg := &recovery.PassphraseConfig{
+		Words:       words,
+		Prefix:      *prefix,
+		Capitalize:  *capitalize,
+		Digits:      *digits,
+		SuffixChars: *suffixChars,
+		SuffixLen:   *suffixLen,
+	}
+	if *wordlist != "" {
+		file, err := os.Open(*wordlist)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir lista de palavras: %w", err)
+		}
+		listed, err := readWordlist(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		cfg.Words = append(cfg.Words, listed...)
+	}
+	if len(cfg.Words) == 0 && cfg.Prefix == "" {
+		return fmt.Errorf("informe as candidatas com --word, --wordlist ou --prefix")
+	}
+
+	mnemonicStr, err := flags.readMnemonic(env)
+	if err != nil {
+		return err
+	}
+	mnemonic, err := parseMnemonic(mnemonicStr)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C stops the search and saves its progress to resume it later.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cfg.Progress = func(p recovery.PassphraseProgress) {
+		fmt.Fprintf(env.stderr, "\r%-80s", formatPassphraseProgress(p))
+	}
+	result, skipped, err := recoverPassphrase(ctx, mnemonic, cfg, *statePath, *restart)
+	fmt.Fprintln(env.stderr)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		return err
+	}
+
+	if flags.jsonOut {
+		out := struct {
+			Found             bool    `json:"found"`
+			Passphrase        *string `json:"passphrase,omitempty"`
+			MasterFingerprint string  `json:"master_fingerprint,omitempty"`
+			Tried             int     `json:"tried"`
+			Total             int     `json:"total"`
+			Skipped           int     `json:"skipped"`
+			Canceled          bool    `json:"canceled,omitempty"`
+		}{Found: result.Found, Tried: result.Tried, Total: result.Total, Skipped: skipped, Canceled: canceled}
+		if result.Found {
+			out.Passphrase = &result.Passphrase
+			out.MasterFingerprint = seedFingerprint(result.Seed)
+		}
+		return writeJSON(env.stdout, out)
+	}
+	if canceled {
+		fmt.Fprintf(env.stdout, "Busca INTERROMPIDA. Execute o mesmo comando para retomá-la a partir da candidata %d.\n", result.Checkpoint+1)
+	}
+	writePassphraseResult(env.stdout, result, skipped)
+	return nil
+}
+
+// --- GUI ---
+
+// showPassphraseRecovery asks for the candidates of a passphrase search of a
+// mnemonic, and starts it.
+func showPassphraseRecovery(mnemonicStr, message string) {
+	mnemonic, err := parseMnemonic(mnemonicStr)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	wordsEntry := widget.NewMultiLineEntry()
+	wordsEntry.SetPlaceHolder("Passphrases candidatas, uma por linha, as mais prováveis primeiro")
+	wordsEntry.SetMinRowsVisible(4)
+	var listed []string
+	listLabel := widget.NewLabel("Nenhuma lista de palavras carregada.")
+	listButton := widget.NewButtonWithIcon("Abrir Lista de Palavras...", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				showStatus(fmt.Sprintf("Erro ao abrir arquivo: %v", err), true)
+				return
+			}
+			if reader == nil {
+				return // Cancelado
+			}
+			defer reader.Close()
+
+			words, err := readWordlist(reader)
+			if err != nil {
+				showStatus(fmt.Sprintf("Erro: %v", err), true)
+				return
+			}
+			listed = words
+			listLabel.SetText(fmt.Sprintf("%d palavras carregadas de %s.", len(words), reader.URI().Name()))
+		}, mainWindow)
+	})
+
+	prefixEntry := widget.NewEntry()
+	prefixEntry.SetPlaceHolder("Início conhecido (opcional)")
+	capitalizeCheck := widget.NewCheck("Testar minúsculas, maiúsculas e primeira letra maiúscula", nil)
+	var lengths []string
+	for i := 0; i <= recovery.MaxTailLen; i++ {
+		lengths = append(lengths, strconv.Itoa(i))
+	}
+	digitsSelect := widget.NewSelect(lengths, nil)
+	digitsSelect.SetSelectedIndex(0)
+	suffixCharsEntry := widget.NewEntry()
+	suffixCharsEntry.SetPlaceHolder("Ex.: !@#$ ou abcdefghijklmnopqrstuvwxyz")
+	suffixLenSelect := widget.NewSelect(lengths, nil)
+	suffixLenSelect.SetSelectedIndex(0)
+
+	content := container.NewVBox(
+		widget.NewLabel(message),
+		wordsEntry,
+		container.NewHBox(listButton, listLabel),
+		widget.NewForm(
+			widget.NewFormItem("Prefixo:", prefixEntry),
+			widget.NewFormItem("Variações:", capitalizeCheck),
+			widget.NewFormItem("Dígitos no final:", digitsSelect),
+			widget.NewFormItem("Caracteres do final:", suffixCharsEntry),
+			widget.NewFormItem("Tamanho do final:", suffixLenSelect),
+		),
+	)
+	formDialog := dialog.NewCustomConfirm("Recuperar Passphrase", "Procurar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		var words []string
+		for _, line := range strings.Split(wordsEntry.Text, "\n") {
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				words = append(words, line)
+			}
+		}
+		digits, _ := strconv.Atoi(digitsSelect.Selected)
+		suffixLen, _ := strconv.Atoi(suffixLenSelect.Selected)
+		cfg := &recovery.PassphraseConfig{
+			Words:       append(words, listed...),
+			Prefix:      prefixEntry.Text,
+			Capitalize:  capitalizeCheck.Checked,
+			Digits:      digits,
+			SuffixChars: suffixCharsEntry.Text,
+			SuffixLen:   suffixLen,
+		}
+		if len(cfg.Words) == 0 && cfg.Prefix == "" {
+			showStatus("Erro: Informe passphrases candidatas, uma lista de palavras ou um prefixo.", true)
+			return
+		}
+		startPassphraseRecovery(mnemonic, cfg)
+	}, mainWindow)
+	formDialog.Resize(fyne.NewSize(600, 500))
+	formDialog.Show()
+}
+
+// startPassphraseRecovery runs a passphrase search in the background, with
+// progress, estimated time and cancellation. A canceled search is resumed by
+// the next run of the same search.
+func startPassphraseRecovery(mnemonic crypto.Mnemonic, cfg *recovery.PassphraseConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progressLabel := widget.NewLabel("Iniciando busca...")
+	bar := widget.NewProgressBar()
+	progressDialog := dialog.NewCustom("Busca de Passphrase em Andamento", "Cancelar", container.NewVBox(
+		progressLabel,
+		bar,
+	), mainWindow)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	cfg.Progress = func(p recovery.PassphraseProgress) {
+		msg := formatPassphraseProgress(p)
+		value := float64(p.Tried) / float64(max(p.Total, 1))
+		fyne.Do(func() {
+			progressLabel.SetText(msg)
+			bar.SetValue(value)
+		})
+	}
+
+	showStatus("Busca da passphrase iniciada...", false)
+	go func() {
+		result, skipped, err := recoverPassphrase(ctx, mnemonic, cfg, defaultPassphraseStatePath(), false)
+		canceled := errors.Is(err, context.Canceled)
+		if err != nil && !canceled {
+			log.Printf("Erro na busca da passphrase: %v", err)
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			switch {
+			case err != nil && !canceled:
+				showStatus(fmt.Sprintf("Erro: %v", err), true)
+			case canceled:
+				showStatus(fmt.Sprintf("Busca da passphrase interrompida em %d de %d candidatas. Repita a mesma busca para retomá-la.", result.Checkpoint, result.Total), false)
+			default:
+				showPassphraseResult(mnemonic, result, skipped)
+			}
+		})
+	}()
+}
+
+// showPassphraseResult shows the outcome of a passphrase search, with a
+// button that decodes the mnemonic with the passphrase found.
+func showPassphraseResult(mnemonic crypto.Mnemonic, result *recovery.PassphraseResult, skipped int) {
+	var text strings.Builder
+	writePassphraseResult(&text, result, skipped)
+	content := container.NewVBox(widget.NewLabel(text.String()))
+	var resultDialog dialog.Dialog
+	if result.Found {
+		content.Add(widget.NewButtonWithIcon("Usar Passphrase", theme.ConfirmIcon(), func() {
+			resultDialog.Hide()
+			mnemonicEntry.SetText(strings.Join(mnemonic[:], " "))
+			passphraseEntry.SetText(result.Passphrase)
+			clearStatus()
+			decodeMnemonicAndAddresses()
+		}))
+	}
+	resultDialog = dialog.NewCustom("Busca de Passphrase Concluída", "Fechar", content, mainWindow)
+	resultDialog.Show()
+
+	if result.Found {
+		showStatus("Passphrase encontrada!", false)
+	} else {
+		showStatus("Nenhuma passphrase encontrada entre as candidatas.", true)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/crypto"
+	"aezeed_address_generator_gui/internal/recovery"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// canRecoverMnemonic reports whether a mnemonic that failed to decode with
+// err may be fixed by a single-word recovery: a word outside the word list, a
+// checksum mismatch or a missing word.
+func canRecoverMnemonic(mnemonicStr string, err error) bool {
+	var wordErr crypto.ErrUnknownMnemonicWord
+	return errors.As(err, &wordErr) ||
+		errors.Is(err, crypto.ErrIncorrectMnemonic) ||
+		len(strings.Fields(mnemonicStr)) == crypto.NumMnemonicWords-1
+}
+
+// recoverFunc is a recovery of the recovery package, searching for the
+// mnemonic the words were meant to be.
+type recoverFunc func(ctx context.Context, words []string, pass []byte, report func(recovery.Progress)) (*recovery.Result, error)
+
+// orderRecovery returns the recovery of the order of size words starting at
+// index start.
+func orderRecovery(start, size int) recoverFunc {
+	return func(ctx context.Context, words []string, pass []byte, report func(recovery.Progress)) (*recovery.Result, error) {
+		return recovery.RecoverOrder(ctx, words, pass, start, size, report)
+	}
+}
+
+// parseWindow parses a window of positions of the mnemonic, such as "7-12",
+// counting from 1, into the index of its first word and its size.
+func parseWindow(s string) (int, int, error) {
+	first, last, ok := strings.Cut(strings.TrimSpace(s), "-")
+	from, err1 := strconv.Atoi(strings.TrimSpace(first))
+	to, err2 := strconv.Atoi(strings.TrimSpace(last))
+	if !ok || err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("trecho inválido %q, use as posições da primeira e da última palavra (ex.: 7-12)", s)
+	}
+	if from < 1 || to > crypto.NumMnemonicWords || to-from+1 < 2 || to-from+1 > recovery.MaxWindow {
+		return 0, 0, fmt.Errorf("trecho inválido %q: deve ter de 2 a %d palavras entre as posições 1 e %d", s, recovery.MaxWindow, crypto.NumMnemonicWords)
+	}
+	return from - 1, to - from + 1, nil
+}
+
+// recoverMnemonic runs a recovery of a mnemonic with passphrase. Unreadable
+// words can be given as "?" to the single-word recovery.
+func recoverMnemonic(ctx context.Context, run recoverFunc, mnemonicStr string, passphrase []byte, report func(recovery.Progress)) (*recovery.Result, error) {
+	result, err := run(ctx, strings.Fields(mnemonicStr), passphrase, report)
+	var wordErr crypto.ErrUnknownMnemonicWord
+	switch {
+	case errors.Is(err, recovery.ErrChecksumValid):
+		return nil, errors.New("o checksum do mnemônico é válido, as palavras provavelmente estão corretas: verifique a passphrase")
+	case errors.Is(err, recovery.ErrTooManyErrors):
+		return nil, errors.New("há mais de uma palavra desconhecida, a recuperação corrige uma única palavra errada ou ausente")
+	case errors.As(err, &wordErr):
+		return nil, fmt.Errorf("a palavra %d (%q) não está na lista: corrija-a antes de recuperar a ordem, ou recupere a palavra errada", wordErr.Index+1, wordErr.Word)
+	case errors.Is(err, context.Canceled):
+		return result, err
+	case err != nil:
+		return nil, fmt.Errorf("erro na recuperação do mnemônico: %w", err)
+	}
+	return result, nil
+}
+
+// formatRecoveryProgress describes the progress of a recovery, and returns
+// the fraction of its current step that is done.
+func formatRecoveryProgress(p recovery.Progress) (string, float64) {
+	if p.Checked < p.Total {
+		return fmt.Sprintf("Checksum verificado: %d de %d candidatos", p.Checked, p.Total), float64(p.Checked) / float64(p.Total)
+	}
+	return fmt.Sprintf("Decifrando candidatos com checksum válido: %d de %d", p.Deciphered, p.Matches), float64(p.Deciphered) / float64(max(p.Matches, 1))
+}
+
+// describeCandidate describes the word a recovery candidate fixed.
+func describeCandidate(c recovery.Candidate) string {
+	if c.Span > 1 {
+		return fmt.Sprintf("palavras %d-%d: %q → %q (%d movidas)", c.Position+1, c.Position+c.Span, c.Original, c.Word, c.Distance)
+	}
+	if c.Original == "" {
+		return fmt.Sprintf("palavra %d ausente: %q", c.Position+1, c.Word)
+	}
+	return fmt.Sprintf("palavra %d: %q → %q (distância %d)", c.Position+1, c.Original, c.Word, c.Distance)
+}
+
+// seedFingerprint returns the master fingerprint of a recovered seed on the
+// current network, which helps to tell the right one apart.
+func seedFingerprint(seed *crypto.CipherSeed) string {
+	w, err := wallet.New(seed, currentNetwork)
+	if err != nil {
+		return "?"
+	}
+	fingerprint, err := w.MasterFingerprint()
+	if err != nil {
+		return "?"
+	}
+	return fingerprint
+}
+
+// writeRecoveryResult writes a recovery result as text.
+func writeRecoveryResult(out io.Writer, result *recovery.Result) {
+	fmt.Fprintf(out, "Candidatos verificados: %d\n", result.Checked)
+	fmt.Fprintf(out, "Candidatos com checksum válido: %d\n", result.ChecksumMatches)
+	fmt.Fprintf(out, "Mnemônicos recuperados: %d\n", len(result.Candidates))
+
+	if len(result.Candidates) == 0 && result.ChecksumMatches > 0 {
+		fmt.Fprintln(out, "\nNenhum candidato decifrou com a passphrase informada. Verifique a passphrase.")
+	}
+	for i, c := range result.Candidates {
+		fmt.Fprintf(out, "\n%d. %s\n", i+1, describeCandidate(c))
+		fmt.Fprintf(out, "   Master Fingerprint: %s\n", seedFingerprint(c.Seed))
+		fmt.Fprintf(out, "   Aniversário: %s\n", formatBirthday(c.Seed))
+		fmt.Fprintf(out, "   Mnemônico: %s\n", strings.Join(c.Mnemonic[:], " "))
+	}
+}
+
+// recoveryCandidateJSON is the JSON form of a recovered mnemonic.
+type recoveryCandidateJSON struct {
+	Position          int      `json:"position"`
+	Span              int      `json:"span"`
+	Original          string   `json:"original,omitempty"`
+	Word              string   `json:"word"`
+	Distance          int      `json:"distance"`
+	MasterFingerprint string   `json:"master_fingerprint"`
+	Birthday          string   `json:"birthday"`
+	Mnemonic          []string `json:"mnemonic"`
+}
+
+// recoveryJSON is the JSON form of a recovery result.
+type recoveryJSON struct {
+	Checked         int                     `json:"checked"`
+	ChecksumMatches int                     `json:"checksum_matches"`
+	Candidates      []recoveryCandidateJSON `json:"candidates"`
+	Canceled        bool                    `json:"canceled,omitempty"`
+}
+
+// newRecoveryJSON converts a recovery result to its JSON form.
+func newRecoveryJSON(result *recovery.Result) recoveryJSON {
+	out := recoveryJSON{
+		Checked:         result.Checked,
+		ChecksumMatches: result.ChecksumMatches,
+		Candidates:      []recoveryCandidateJSON{},
+	}
+	for _, c := range result.Candidates {
+		out.Candidates = append(out.Candidates, recoveryCandidateJSON{
+			Position:          c.Position + 1,
+			Span:              c.Span,
+			Original:          c.Original,
+			Word:              c.Word,
+			Distance:          c.Distance,
+			MasterFingerprint: seedFingerprint(c.Seed),
+			Birthday:          c.Seed.BirthdayTime().Format("2006-01-02"),
+			Mnemonic:          c.Mnemonic[:],
+		})
+	}
+	return out
+}
+
+// --- CLI ---
+
+func runSeedRecover(env *cliEnv, args []string) error {
+	fs := newFlagSet(env, "seed recover")
+	var flags seedFlags
+	flags.register(fs)
+	swap := fs.Bool("swap", false, "procura duas palavras adjacentes trocadas de lugar")
+	window := fs.String("window", "", fmt.Sprintf("procura a ordem das palavras de um trecho, ex.: 7-12 (até %d palavras)", recovery.MaxWindow))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	run := recoverFunc(recovery.RecoverWord)
+	switch {
+	case *swap && *window != "":
+		return fmt.Errorf("use apenas uma das opções --swap e --window")
+	case *swap:
+		run = recovery.RecoverSwap
+	case *window != "":
+		start, size, err := parseWindow(*window)
+		if err != nil {
+			return err
+		}
+		run = orderRecovery(start, size)
+	}
+
+	if err := flags.selectNetwork(); err != nil {
+		return err
+	}
+	mnemonicStr, err := flags.readMnemonic(env)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C stops the search and still prints the candidates found.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := recoverMnemonic(ctx, run, mnemonicStr, flags.passphraseBytes(), func(p recovery.Progress) {
+		msg, _ := formatRecoveryProgress(p)
+		fmt.Fprintf(env.stderr, "\r%-70s", msg)
+	})
+	fmt.Fprintln(env.stderr)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		return err
+	}
+
+	if flags.jsonOut {
+		out := newRecoveryJSON(result)
+		out.Canceled = canceled
+		return writeJSON(env.stdout, out)
+	}
+	if canceled {
+		fmt.Fprintln(env.stdout, "Recuperação CANCELADA. Resultado parcial:")
+	}
+	writeRecoveryResult(env.stdout, result)
+	return nil
+}
+
+// --- GUI ---
+
+// offerMnemonicRecovery offers to recover a mnemonic that failed to decode,
+// or its passphrase, when the error is one a recovery may fix.
+func offerMnemonicRecovery(mnemonicStr string, err error) {
+	if errors.Is(err, crypto.ErrInvalidPass) {
+		showPassphraseRecovery(mnemonicStr, "A passphrase não decifra o mnemônico. Informe as candidatas e os padrões a testar:")
+		return
+	}
+	if !canRecoverMnemonic(mnemonicStr, err) {
+		return
+	}
+	showRecoveryOptions(mnemonicStr, "O mnemônico parece ter um erro de transcrição. Escolha o tipo de erro a procurar:")
+}
+
+// Recovery modes shown by showRecoveryOptions.
+const (
+	recoveryModeWord  = "Uma palavra errada, ilegível (?) ou ausente"
+	recoveryModeSwap  = "Duas palavras adjacentes trocadas"
+	recoveryModeOrder = "Ordem perdida em um trecho"
+	recoveryModePass  = "Passphrase esquecida"
+)
+
+// showRecoveryOptions asks which kind of error to look for in a mnemonic, and
+// starts the recovery.
+func showRecoveryOptions(mnemonicStr, message string) {
+	windowEntry := widget.NewEntry()
+	windowEntry.SetPlaceHolder("Ex.: 7-12")
+	windowEntry.Disable()
+	modeRadio := widget.NewRadioGroup([]string{recoveryModeWord, recoveryModeSwap, recoveryModeOrder, recoveryModePass}, func(mode string) {
+		if mode == recoveryModeOrder {
+			windowEntry.Enable()
+		} else {
+			windowEntry.Disable()
+		}
+	})
+	modeRadio.Required = true
+	modeRadio.SetSelected(recoveryModeWord)
+
+	content := container.NewVBox(
+		widget.NewLabel(message),
+		modeRadio,
+		widget.NewForm(widget.NewFormItem(fmt.Sprintf("Trecho (até %d palavras):", recovery.MaxWindow), windowEntry)),
+	)
+	dialog.ShowCustomConfirm("Recuperar Mnemônico", "Recuperar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		run := recoverFunc(recovery.RecoverWord)
+		switch modeRadio.Selected {
+		case recoveryModePass:
+			showPassphraseRecovery(mnemonicStr, "Informe as candidatas e os padrões da passphrase:")
+			return
+		case recoveryModeSwap:
+			run = recovery.RecoverSwap
+		case recoveryModeOrder:
+			start, size, err := parseWindow(windowEntry.Text)
+			if err != nil {
+				showStatus(fmt.Sprintf("Erro: %v", err), true)
+				return
+			}
+			run = orderRecovery(start, size)
+		}
+		startRecovery(run, mnemonicStr, passphraseOrDefault(passphraseEntry.Text))
+	}, mainWindow)
+}
+
+// startRecovery runs a recovery in the background, with progress and
+// cancellation, and shows the candidates found.
+func startRecovery(run recoverFunc, mnemonicStr string, passphrase []byte) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progressLabel := widget.NewLabel("Iniciando recuperação...")
+	bar := widget.NewProgressBar()
+	progressDialog := dialog.NewCustom("Recuperação em Andamento", "Cancelar", container.NewVBox(
+		progressLabel,
+		bar,
+	), mainWindow)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	report := func(p recovery.Progress) {
+		msg, value := formatRecoveryProgress(p)
+		fyne.Do(func() {
+			progressLabel.SetText(msg)
+			bar.SetValue(value)
+		})
+	}
+
+	showStatus(fmt.Sprintf("Recuperação do mnemônico iniciada em %d núcleos...", runtime.NumCPU()), false)
+	go func() {
+		result, err := recoverMnemonic(ctx, run, mnemonicStr, passphrase, report)
+		canceled := errors.Is(err, context.Canceled)
+		if err != nil && !canceled {
+			log.Printf("Erro na recuperação: %v", err)
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			if err != nil && !canceled {
+				showStatus(fmt.Sprintf("Erro: %v", err), true)
+				return
+			}
+			showRecoveryResult(result, canceled)
+		})
+	}()
+}
+
+// showRecoveryResult lists the recovered mnemonics in a dialog, each with a
+// button that decodes it.
+func showRecoveryResult(result *recovery.Result, canceled bool) {
+	var text strings.Builder
+	if canceled {
+		text.WriteString("Recuperação CANCELADA. Resultado parcial:\n\n")
+	}
+	writeRecoveryResult(&text, result)
+	resultEntry := widget.NewMultiLineEntry()
+	resultEntry.SetText(text.String())
+	resultEntry.Wrapping = fyne.TextWrapWord
+	resultEntry.Disable()
+
+	var resultDialog dialog.Dialog
+	buttons := container.NewVBox()
+	for i, c := range result.Candidates {
+		mnemonicStr := strings.Join(c.Mnemonic[:], " ")
+		buttons.Add(widget.NewButtonWithIcon(fmt.Sprintf("Usar candidato %d (%s)", i+1, describeCandidate(c)), theme.ConfirmIcon(), func() {
+			resultDialog.Hide()
+			mnemonicEntry.SetText(mnemonicStr)
+			clearStatus()
+			decodeMnemonicAndAddresses()
+		}))
+	}
+
+	resultScroll := container.NewScroll(container.NewVBox(resultEntry, buttons))
+	resultScroll.SetMinSize(fyne.NewSize(700, 400))
+	resultDialog = dialog.NewCustom("Recuperação Concluída", "Fechar", resultScroll, mainWindow)
+	resultDialog.Show()
+
+	showStatus(fmt.Sprintf("Recuperação concluída: %d mnemônicos recuperados.", len(result.Candidates)), len(result.Candidates) == 0)
+}
+
+// handleMnemonicRecovery starts a recovery of the mnemonic in the entry.
+func handleMnemonicRecovery() {
+	if strings.TrimSpace(mnemonicEntry.Text) == "" {
+		showStatus("Erro: Cole o mnemônico a recuperar, com \"?\" no lugar de palavras ilegíveis.", true)
+		return
+	}
+	showRecoveryOptions(mnemonicEntry.Text, "Escolha o tipo de erro a procurar no mnemônico:")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/config"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// settingsPath is the file saveSettings writes to. It is only set by the GUI,
+// the CLI reads the settings but never changes them.
+var settingsPath string
+
+// loadSettings restores the persisted source settings and proxy, returning the
+// settings so the caller can restore the rest, and the path of the settings
+// file. Problems are logged and yield empty settings: the application works
+// the same without them.
+func loadSettings() (*config.Settings, string) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		log.Printf("Configurações indisponíveis: %v", err)
+		return &config.Settings{}, ""
+	}
+
+	settings, err := config.Load(path)
+	if err != nil {
+		log.Printf("Erro ao carregar configurações: %v", err)
+		return &config.Settings{}, path
+	}
+
+	for _, b := range blockchainSources.Backends() {
+		values := settings.Sources[b.ID]
+		for _, opt := range b.Options {
+			if value, ok := values[opt.Key]; ok && !opt.Secret {
+				setSourceOption(b, opt.Key, value)
+			}
+		}
+	}
+	setSourceProxy(settings.Proxy.Addr, settings.Proxy.All)
+
+	return settings, path
+}
+
+// saveSettings persists the network, the selected source, the source options
+// and the proxy. Secret options, such as RPC passwords, are never written,
+// and neither are values equal to the network's default, so defaults keep
+// following the network.
+func saveSettings() {
+	if settingsPath == "" {
+		return
+	}
+	log.Printf("Salvando configurações em %s", settingsPath)
+
+	settings := &config.Settings{
+		Network: currentNetwork.Name,
+		Source:  "offline",
+		Sources: make(map[string]map[string]string),
+		SLIP132: slip132Xpubs,
+	}
+	if selectedBackend != nil {
+		settings.Source = selectedBackend.ID
+	}
+	for _, b := range blockchainSources.Backends() {
+		for _, opt := range b.Options {
+			value := sourceOptionValue(b, opt.Key)
+			if opt.Secret || value == opt.DefaultValue(currentNetwork) {
+				continue
+			}
+			if settings.Sources[b.ID] == nil {
+				settings.Sources[b.ID] = make(map[string]string)
+			}
+			settings.Sources[b.ID][opt.Key] = value
+		}
+	}
+
+	sourceMutex.Lock()
+	if sourceProxy != nil {
+		settings.Proxy = config.Proxy{Addr: sourceProxy.Addr, All: sourceProxy.All}
+	}
+	sourceMutex.Unlock()
+
+	if err := settings.Save(settingsPath); err != nil {
+		log.Printf("Erro ao salvar configurações: %v", err)
+	}
+}
+
+// savedNetwork returns the persisted network, or the current one if none was
+// saved.
+func savedNetwork(settings *config.Settings) *wallet.Network {
+	if settings.Network == "" {
+		return currentNetwork
+	}
+	n, err := wallet.NetworkByName(settings.Network)
+	if err != nil {
+		log.Printf("Rede salva inválida: %v", err)
+		return currentNetwork
+	}
+	return n
+}
+
+// savedSourceName returns the display name of the persisted source, or
+// SourceOffline.
+func savedSourceName(settings *config.Settings) string {
+	if settings.Source == "" || strings.EqualFold(settings.Source, "offline") {
+		return SourceOffline
+	}
+	b, err := blockchainSources.ByID(settings.Source)
+	if err != nil {
+		log.Printf("Fonte salva inválida: %v", err)
+		return SourceOffline
+	}
+	return b.Name
+}
+
+// --- GUI ---
+
+// newProxyConfigCard builds the form of the SOCKS5 proxy shared by the online
+// sources.
+func newProxyConfigCard() *widget.Card {
+	sourceMutex.Lock()
+	proxy := blockchain.Proxy{}
+	if sourceProxy != nil {
+		proxy = *sourceProxy
+	}
+	sourceMutex.Unlock()
+
+	addrEntry := widget.NewEntry()
+	addrEntry.SetPlaceHolder("127.0.0.1:9050 (vazio = sem proxy)")
+	addrEntry.SetText(proxy.Addr)
+	allCheck := widget.NewCheck("Usar para todas as conexões (não apenas .onion)", nil)
+	allCheck.SetChecked(proxy.All)
+
+	update := func() { setSourceProxy(addrEntry.Text, allCheck.Checked) }
+	addrEntry.OnChanged = func(string) { update() }
+	allCheck.OnChanged = func(bool) { update() }
+
+	form := widget.NewForm(widget.NewFormItem("Endereço:", addrEntry))
+	return widget.NewCard("Proxy SOCKS5 (Tor)", "", container.NewVBox(form, allCheck))
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"aezeed_address_generator_gui/internal/blockchain"
+	"aezeed_address_generator_gui/internal/blockchain/bitcoind"
+	"aezeed_address_generator_gui/internal/blockchain/esplora"
+	"aezeed_address_generator_gui/internal/electrum"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// blockchainSources are the online blockchain sources offered by the GUI and
+// the CLI. A new backend only has to implement blockchain.Source and be
+// registered here: the source selection, the configuration forms and the
+// command line flags are built from the registry.
+var blockchainSources = newBlockchainSources()
+
+func newBlockchainSources() *blockchain.Registry {
+	registry := blockchain.NewRegistry()
+	backends := []*blockchain.Backend{{
+		ID:          "blockstream",
+		Name:        "API Esplora (Blockstream.info)",
+		Concurrency: 4,
+		Options: []blockchain.Option{{
+			Key:         "url",
+			Flag:        "esplora-url",
+			Label:       "URL:",
+			Usage:       "URL base da API Esplora, como uma instância própria do Esplora ou do mempool.space (padrão Blockstream.info)",
+			Placeholder: "http://host:3000/api ou http://endereço.onion/api",
+			Default: func(n *wallet.Network) string {
+				return n.EsploraURL
+			},
+		}},
+		Open: func(cfg *blockchain.OpenConfig) (blockchain.Source, error) {
+			baseURL := strings.TrimRight(cfg.Settings["url"], "/")
+			if baseURL == "" {
+				return nil, fmt.Errorf("não há API Esplora pública para a rede %s, informe a URL de uma instância própria", cfg.Network)
+			}
+			return esplora.New(&esplora.Config{
+				BaseURL:      baseURL,
+				HTTPClient:   cfg.Proxy.HTTPClient(),
+				RequestDelay: apiCallDelay,
+			}), nil
+		},
+	}, {
+		ID:   "local",
+		Name: "Nó Local (RPC)",
+		// scantxoutset can't run in parallel.
+		Concurrency: 1,
+		Options: []blockchain.Option{{
+			Key:   "url",
+			Flag:  "rpc-url",
+			Label: "URL:",
+			Usage: "URL do nó local (RPC) (padrão conforme a rede)",
+			Default: func(n *wallet.Network) string {
+				return n.RPCHost
+			},
+		}, {
+			Key:         "user",
+			Flag:        "rpc-user",
+			Label:       "Usuário:",
+			Usage:       "usuário RPC do nó local",
+			Placeholder: "Usuário RPC (opcional)",
+		}, {
+			Key:         "pass",
+			Flag:        "rpc-pass",
+			Label:       "Senha:",
+			Usage:       "senha RPC do nó local",
+			Placeholder: "Senha RPC (opcional)",
+			Secret:      true,
+		}},
+		Open: func(cfg *blockchain.OpenConfig) (blockchain.Source, error) {
+			return bitcoind.New(&bitcoind.Config{
+				Host:       cfg.Settings["url"],
+				User:       cfg.Settings["user"],
+				Pass:       cfg.Settings["pass"],
+				Network:    cfg.Network,
+				HTTPClient: cfg.Proxy.HTTPClient(),
+			}), nil
+		},
+	}, {
+		ID:   "electrum",
+		Name: "Servidor Electrum",
+		// The whole batch is sent in a single request.
+		Concurrency: AddressBatchSize,
+		Options: []blockchain.Option{{
+			Key:         "server",
+			Flag:        "electrum-server",
+			Label:       "Servidor:",
+			Usage:       "servidor Electrum, tcp://host:porta ou ssl://host:porta (padrão conforme a rede)",
+			Placeholder: "tcp://host:50001 ou ssl://host:50002",
+			Default: func(n *wallet.Network) string {
+				return n.ElectrumServer
+			},
+		}, {
+			Key:   "insecure",
+			Flag:  "electrum-insecure",
+			Label: "Aceitar certificado TLS autoassinado",
+			Usage: "aceitar certificado TLS autoassinado do servidor Electrum",
+			Bool:  true,
+		}},
+		Open: func(cfg *blockchain.OpenConfig) (blockchain.Source, error) {
+			return electrum.NewSource(&electrum.Config{
+				Server:             cfg.Settings["server"],
+				InsecureSkipVerify: cfg.Settings["insecure"] == "true",
+				Dial:               cfg.Proxy.DialContext,
+			}, cfg.Network.Params.GenesisHash), nil
+		},
+	}}
+	for _, b := range backends {
+		if err := registry.Register(b); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+var (
+	// selectedBackend is the selected online source, nil when offline.
+	selectedBackend *blockchain.Backend
+
+	// sourceSettings holds the option values set by the user, by backend
+	// ID and option key. Unset options use the network's defaults.
+	sourceSettings = make(map[string]map[string]string)
+
+	// sourceProxy is the SOCKS5 proxy of the sources, nil for direct
+	// connections.
+	sourceProxy *blockchain.Proxy
+
+	sourceMutex   sync.Mutex
+	openSource    blockchain.Source
+	openSourceKey string
+)
+
+// sourceName returns the display name of the selected source.
+func sourceName() string {
+	if selectedBackend == nil {
+		return SourceOffline
+	}
+	return selectedBackend.Name
+}
+
+// selectSource selects the backend with the given display name, or none for
+// SourceOffline.
+func selectSource(name string) error {
+	if name == SourceOffline {
+		selectedBackend = nil
+		return nil
+	}
+	b, err := blockchainSources.ByName(name)
+	if err != nil {
+		return err
+	}
+	selectedBackend = b
+	return nil
+}
+
+// setSourceOption stores the value of a backend option.
+func setSourceOption(b *blockchain.Backend, key, value string) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	if sourceSettings[b.ID] == nil {
+		sourceSettings[b.ID] = make(map[string]string)
+	}
+	sourceSettings[b.ID][key] = value
+}
+
+// setSourceProxy sets the SOCKS5 proxy of the sources. An empty address
+// disables it.
+func setSourceProxy(addr string, all bool) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		sourceProxy = nil
+		return
+	}
+	sourceProxy = &blockchain.Proxy{Addr: addr, All: all}
+}
+
+// sourceOptionValue returns the effective value of a backend option for the
+// current network.
+func sourceOptionValue(b *blockchain.Backend, key string) string {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	return b.Settings(currentNetwork, sourceSettings[b.ID])[key]
+}
+
+// currentSource returns the selected source for the current network. The
+// source is kept open and only reopened when the backend, the network or the
+// settings change.
+func currentSource() (blockchain.Source, error) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	if selectedBackend == nil {
+		return nil, fmt.Errorf("nenhuma fonte online selecionada")
+	}
+	settings := selectedBackend.Settings(currentNetwork, sourceSettings[selectedBackend.ID])
+	key := fmt.Sprintf("%s|%s|%v|%+v", selectedBackend.ID, currentNetwork.Name, settings, sourceProxy)
+	if openSource != nil && key == openSourceKey {
+		return openSource, nil
+	}
+
+	if openSource != nil {
+		openSource.Close()
+		openSource = nil
+	}
+	src, err := selectedBackend.Open(&blockchain.OpenConfig{
+		Network:  currentNetwork,
+		Settings: settings,
+		Proxy:    sourceProxy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	openSource = src
+	openSourceKey = key
+	return src, nil
+}
+
+// describeSourceError turns the common source errors into messages for the
+// user.
+func describeSourceError(err error) error {
+	switch {
+	case errors.Is(err, esplora.ErrRateLimited):
+		return errors.New("muitas requisições para a API (rate limit). Tente novamente mais tarde")
+	case errors.Is(err, blockchain.ErrOnionNeedsProxy):
+		return errors.New("endereços .onion requerem um proxy SOCKS5 (Tor), configure-o na fonte de dados")
+	case errors.Is(err, blockchain.ErrNotSupported):
+		return fmt.Errorf("operação não suportada pela fonte %s", sourceName())
+	default:
+		return err
+	}
+}
+
+// formatAddressInfo formats the result of an address lookup for display.
+func formatAddressInfo(info *blockchain.AddressInfo) string {
+	return fmt.Sprintf("Tx Count: %d, Saldo: %s", info.TxCount, info.Balance())
+}
+
+// sourceIDs lists the accepted --source values.
+func sourceIDs() string {
+	ids := []string{"offline"}
+	for _, b := range blockchainSources.Backends() {
+		ids = append(ids, b.ID)
+	}
+	return strings.Join(ids[:len(ids)-1], ", ") + " ou " + ids[len(ids)-1]
+}
+
+// --- GUI ---
+
+// sourceOptionEntries are the form entries of the string options, by backend
+// ID and option key, so they can follow network changes.
+var sourceOptionEntries = make(map[string]map[string]*widget.Entry)
+
+// newSourceConfigCard builds the configuration form of a backend, or returns
+// nil if it has no options.
+func newSourceConfigCard(b *blockchain.Backend) *widget.Card {
+	if len(b.Options) == 0 {
+		return nil
+	}
+
+	form := widget.NewForm()
+	content := container.NewVBox(form)
+	sourceOptionEntries[b.ID] = make(map[string]*widget.Entry)
+	for _, opt := range b.Options {
+		key := opt.Key
+		if opt.Bool {
+			check := widget.NewCheck(opt.Label, func(on bool) {
+				value := ""
+				if on {
+					value = "true"
+				}
+				setSourceOption(b, key, value)
+			})
+			check.SetChecked(sourceOptionValue(b, key) == "true")
+			content.Add(check)
+			continue
+		}
+
+		entry := widget.NewEntry()
+		if opt.Secret {
+			entry = widget.NewPasswordEntry()
+		}
+		entry.SetPlaceHolder(opt.Placeholder)
+		entry.SetText(sourceOptionValue(b, key))
+		entry.OnChanged = func(s string) { setSourceOption(b, key, s) }
+		form.Append(opt.Label, entry)
+		sourceOptionEntries[b.ID][key] = entry
+	}
+	return widget.NewCard(fmt.Sprintf("Configuração %s", b.Name), "", content)
+}
+
+// followNetworkDefaults updates the options still set to the default of the
+// old network to the default of the new one, keeping values entered by the
+// user.
+func followNetworkDefaults(old, n *wallet.Network) {
+	for _, b := range blockchainSources.Backends() {
+		for _, opt := range b.Options {
+			if opt.Default == nil || sourceOptionValue(b, opt.Key) != opt.DefaultValue(old) {
+				continue
+			}
+			setSourceOption(b, opt.Key, "")
+			if entry := sourceOptionEntries[b.ID][opt.Key]; entry != nil {
+				entry.SetText(opt.DefaultValue(n))
+			}
+		}
+	}
+}
+
+// lookupAddressInfo looks an address up on the selected source.
+func lookupAddressInfo(ctx context.Context, address string) (*blockchain.AddressInfo, error) {
+	addr, err := currentNetwork.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	src, err := currentSource()
+	if err != nil {
+		return nil, err
+	}
+	info, err := src.AddressInfo(ctx, addr)
+	if err != nil {
+		return nil, describeSourceError(err)
+	}
+	return info, nil
+}
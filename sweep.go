@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"aezeed_address_generator_gui/internal/discovery"
+	"aezeed_address_generator_gui/internal/sweep"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// defaultSweepFeeRate is the fee rate suggested for sweeps, in sat/vB.
+const defaultSweepFeeRate = 5.0
+
+// fundedAddresses returns the used addresses of a discovery report that still
+// hold funds.
+func fundedAddresses(report *discovery.Report) []*wallet.DerivedAddress {
+	var addrs []*wallet.DerivedAddress
+	for _, u := range report.Used {
+		if u.Stats.Balance > 0 {
+			addrs = append(addrs, u.DerivedAddress)
+		}
+	}
+	return addrs
+}
+
+// parseFeeRate parses a fee rate in sat/vB.
+func parseFeeRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(strings.ReplaceAll(s, ",", ".")), 64)
+	if err != nil || rate < sweep.MinFeeRate {
+		return 0, fmt.Errorf("taxa inválida %q (mínimo %v sat/vB)", s, sweep.MinFeeRate)
+	}
+	return rate, nil
+}
+
+// decodeDestination decodes the destination address of a sweep.
+func decodeDestination(w *wallet.Wallet, destination string) (btcutil.Address, error) {
+	dest, err := w.Network().DecodeAddress(strings.TrimSpace(destination))
+	var wrongNet wallet.ErrWrongNetwork
+	switch {
+	case errors.As(err, &wrongNet):
+		return nil, fmt.Errorf("o destino pertence à rede %s, mas a rede selecionada é %s", strings.Join(wrongNet.Detected, "/"), wrongNet.Expected)
+	case err != nil:
+		return nil, fmt.Errorf("endereço de destino inválido: %w", err)
+	}
+	return dest, nil
+}
+
+// errSignWatchOnly is returned when a sweep of a watch-only wallet is asked
+// to be signed.
+var errSignWatchOnly = errors.New("assinar requer a seed, indisponível no modo somente leitura")
+
+// buildSweep collects the unspent outputs of the addresses from the selected
+// source and builds a sweep to the destination, signing it if asked to.
+func buildSweep(ctx context.Context, w *wallet.Wallet, addrs []*wallet.DerivedAddress,
+	dest btcutil.Address, feeRate float64, sign bool) (*sweep.Sweep, error) {
+
+	if sign && w.WatchOnly() {
+		return nil, errSignWatchOnly
+	}
+	src, err := currentSource()
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := sweep.CollectInputs(ctx, src, addrs)
+	if err != nil {
+		return nil, describeSourceError(err)
+	}
+	log.Printf("Varredura: %d UTXOs encontrados em %d endereços.", len(inputs), len(addrs))
+
+	s, err := sweep.Build(&sweep.Config{
+		Wallet:      w,
+		Inputs:      inputs,
+		Destination: dest,
+		FeeRate:     feeRate,
+	})
+	switch {
+	case errors.Is(err, wallet.ErrWatchOnly):
+		return nil, fmt.Errorf("a PSBT requer a master fingerprint: carregue um descritor com a origem da chave ([fingerprint/caminho])")
+	case errors.Is(err, sweep.ErrNoInputs):
+		return nil, fmt.Errorf("nenhum UTXO encontrado para varrer")
+	case errors.Is(err, sweep.ErrDust):
+		return nil, fmt.Errorf("o saldo não cobre a taxa: %w", err)
+	case err != nil:
+		return nil, err
+	}
+
+	if sign {
+		if err := s.Sign(); err != nil {
+			return nil, fmt.Errorf("erro ao assinar: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// sweepTxHex returns the hex encoded transaction of a signed sweep.
+func sweepTxHex(s *sweep.Sweep) (string, error) {
+	tx, err := s.Tx()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// sweepInputJSON is the JSON form of a swept output.
+type sweepInputJSON struct {
+	Path     string `json:"path"`
+	Address  string `json:"address"`
+	OutPoint string `json:"outpoint"`
+	ValueSat int64  `json:"value_sat"`
+}
+
+// sweepJSON is the JSON form of a sweep.
+type sweepJSON struct {
+	Inputs      []sweepInputJSON `json:"inputs"`
+	Destination string           `json:"destination"`
+	TotalSat    int64            `json:"total_sat"`
+	FeeSat      int64            `json:"fee_sat"`
+	AmountSat   int64            `json:"amount_sat"`
+	VSize       int64            `json:"vsize"`
+	Signed      bool             `json:"signed"`
+	PSBT        string           `json:"psbt"`
+	TxHex       string           `json:"tx_hex,omitempty"`
+}
+
+func newSweepJSON(s *sweep.Sweep, destination string) (*sweepJSON, error) {
+	psbt, err := s.Base64()
+	if err != nil {
+		return nil, err
+	}
+	out := &sweepJSON{
+		Destination: destination,
+		TotalSat:    int64(s.Total),
+		FeeSat:      int64(s.Fee),
+		AmountSat:   int64(s.Amount),
+		VSize:       s.VSize,
+		Signed:      s.Signed(),
+		PSBT:        psbt,
+	}
+	for _, in := range s.Inputs {
+		out.Inputs = append(out.Inputs, sweepInputJSON{
+			Path:     in.Address.Path,
+			Address:  in.Address.Address.String(),
+			OutPoint: in.UTXO.OutPoint.String(),
+			ValueSat: int64(in.UTXO.Value),
+		})
+	}
+	if out.Signed {
+		out.TxHex, err = sweepTxHex(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// writeSweep writes a human-readable summary of a sweep, followed by the PSBT
+// and, if signed, the raw transaction.
+func writeSweep(out io.Writer, s *sweep.Sweep, destination string) error {
+	j, err := newSweepJSON(s, destination)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Entradas (%d):\n", len(s.Inputs))
+	for _, in := range s.Inputs {
+		fmt.Fprintf(out, "  %-24s %s  %s  %s\n", in.Address.Path, in.Address.Address, in.UTXO.OutPoint, in.UTXO.Value)
+	}
+	fmt.Fprintf(out, "\nDestino: %s\n", destination)
+	fmt.Fprintf(out, "Total das entradas: %s\n", s.Total)
+	fmt.Fprintf(out, "Taxa: %s (%d vB, %.2f sat/vB)\n", s.Fee, s.VSize, float64(s.Fee)/float64(s.VSize))
+	fmt.Fprintf(out, "Valor enviado: %s\n", s.Amount)
+
+	if j.Signed {
+		fmt.Fprintf(out, "\nPSBT assinada e finalizada (base64):\n%s\n", j.PSBT)
+		fmt.Fprintf(out, "\nTransação assinada (hex):\n%s\n", j.TxHex)
+	} else {
+		fmt.Fprintf(out, "\nPSBT não assinada (base64), para um assinador externo:\n%s\n", j.PSBT)
+	}
+	return nil
+}
+
+// --- GUI ---
+
+// handleSweep asks for the destination and fee rate of a sweep of the loaded
+// seed's funds, found with a discovery scan.
+func handleSweep() {
+	if currentWallet == nil {
+		showStatus("Erro: Nenhuma chave mestra disponível. Gere ou decodifique uma seed primeiro.", true)
+		return
+	}
+
+	destEntry := widget.NewEntry()
+	destEntry.SetPlaceHolder("Endereço de destino")
+	feeEntry := widget.NewEntry()
+	feeEntry.SetText(strconv.FormatFloat(defaultSweepFeeRate, 'f', -1, 64))
+	signCheck := widget.NewCheck("Assinar com a seed carregada", nil)
+	signCheck.SetChecked(true)
+	if currentWallet.WatchOnly() {
+		signCheck.SetChecked(false)
+		signCheck.Disable()
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Destino:", destEntry),
+		widget.NewFormItem("Taxa (sat/vB):", feeEntry),
+		widget.NewFormItem("", signCheck),
+	}
+	dialog.ShowForm("Varrer Fundos (PSBT)", "Continuar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		dest, err := decodeDestination(currentWallet, destEntry.Text)
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro: %v", err), true)
+			return
+		}
+		feeRate, err := parseFeeRate(feeEntry.Text)
+		if err != nil {
+			showStatus(fmt.Sprintf("Erro: %v", err), true)
+			return
+		}
+		startSweep(dest, feeRate, signCheck.Checked)
+	}, mainWindow)
+}
+
+// startSweep finds the funded addresses with a discovery scan using the
+// default limits and builds the sweep in the background.
+func startSweep(dest btcutil.Address, feeRate float64, sign bool) {
+	w := currentWallet
+	cfg, err := newDiscoveryConfig(w, discovery.DefaultGapLimit, discovery.DefaultMaxAccounts)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progressLabel := widget.NewLabel("Procurando endereços com saldo...")
+	progressDialog := dialog.NewCustom("Varredura em Andamento", "Cancelar", container.NewVBox(
+		progressLabel,
+		widget.NewProgressBarInfinite(),
+	), mainWindow)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	cfg.Progress = func(p discovery.Progress) {
+		msg := fmt.Sprintf("Procurando endereços com saldo...\nBIP%d conta %d change %d índice %d",
+			p.Purpose, p.Account, p.Chain, p.Index)
+		fyne.Do(func() { progressLabel.SetText(msg) })
+	}
+
+	showStatus(fmt.Sprintf("Varredura iniciada via %s...", sourceName()), false)
+	go func() {
+		s, err := func() (*sweep.Sweep, error) {
+			report, err := discovery.Scan(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			fyne.Do(func() { progressLabel.SetText("Montando a transação...") })
+			return buildSweep(ctx, w, fundedAddresses(report), dest, feeRate, sign)
+		}()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Erro na varredura: %v", err)
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			switch {
+			case errors.Is(err, context.Canceled):
+				showStatus("Varredura cancelada.", false)
+			case err != nil:
+				showStatus(fmt.Sprintf("Erro na varredura: %v", err), true)
+			default:
+				showSweep(s, dest.String())
+			}
+		})
+	}()
+}
+
+// showSweep shows a sweep in a dialog, from which the PSBT and the signed
+// transaction can be copied.
+func showSweep(s *sweep.Sweep, destination string) {
+	j, err := newSweepJSON(s, destination)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+	var text strings.Builder
+	if err := writeSweep(&text, s, destination); err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+
+	resultEntry := widget.NewMultiLineEntry()
+	resultEntry.SetText(text.String())
+	resultEntry.Wrapping = fyne.TextWrapWord
+	resultEntry.Disable()
+	resultScroll := container.NewScroll(resultEntry)
+	resultScroll.SetMinSize(fyne.NewSize(700, 400))
+
+	buttons := container.NewHBox(widget.NewButton("Copiar PSBT", func() {
+		mainWindow.Clipboard().SetContent(j.PSBT)
+		showStatus("PSBT copiada para a área de transferência.", false)
+	}))
+	if j.Signed {
+		buttons.Add(widget.NewButton("Copiar Transação (hex)", func() {
+			mainWindow.Clipboard().SetContent(j.TxHex)
+			showStatus("Transação copiada para a área de transferência.", false)
+		}))
+		buttons.Add(widget.NewButton("Transmitir...", func() {
+			startBroadcast(j.TxHex)
+		}))
+	}
+
+	dialog.ShowCustom("Varredura de Fundos", "Fechar", container.NewBorder(nil, buttons, nil, nil, resultScroll), mainWindow)
+	showStatus(fmt.Sprintf("Varredura montada: %d entradas, %s para %s (taxa %s).", len(s.Inputs), s.Amount, destination, s.Fee), false)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"aezeed_address_generator_gui/internal/descriptor"
+	"aezeed_address_generator_gui/internal/wallet"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultWatchPurpose is the purpose of xpub keys, whose prefix doesn't tell
+// the script type.
+const defaultWatchPurpose = wallet.BIP84Purpose
+
+var (
+	watchOnlyEntry         *widget.Entry
+	watchOnlyPurposeSelect *widget.Select
+)
+
+// loadWatchOnly creates a watch-only wallet from an account extended public
+// key (xpub, ypub or zpub) or descriptor. purpose is the script type of xpub
+// keys.
+func loadWatchOnly(input string, network *wallet.Network, purpose uint32) (*wallet.Wallet, error) {
+	w, err := descriptor.ParseWatchOnly(input, network, purpose)
+	var unknownPurpose wallet.ErrUnknownPurpose
+	switch {
+	case errors.As(err, &unknownPurpose):
+		return nil, fmt.Errorf("propósito inválido %d", unknownPurpose.Purpose)
+	case err != nil:
+		return nil, fmt.Errorf("chave ou descritor inválido: %w", err)
+	}
+	return w, nil
+}
+
+// lookupLastAccount returns the last account an address search must cover to
+// reach the account of a watch-only wallet, which is the only one it can
+// search.
+func lookupLastAccount(w *wallet.Wallet, lastAccount uint32) uint32 {
+	if _, account, ok := w.WatchedAccount(); ok && account > lastAccount {
+		return account
+	}
+	return lastAccount
+}
+
+// --- GUI ---
+
+// newWatchOnlyCard builds the card to load an extended public key or
+// descriptor instead of a mnemonic.
+func newWatchOnlyCard() fyne.CanvasObject {
+	watchOnlyEntry = widget.NewMultiLineEntry()
+	watchOnlyEntry.SetPlaceHolder("xpub/ypub/zpub da conta ou descritor, ex.: wpkh([fingerprint/84'/0'/0']xpub.../0/*)")
+	watchOnlyEntry.Wrapping = fyne.TextWrapBreak
+	watchOnlyEntry.SetMinRowsVisible(2)
+
+	names := make([]string, 0, len(wallet.Purposes))
+	for _, p := range wallet.Purposes {
+		names = append(names, p.Name)
+	}
+	watchOnlyPurposeSelect = widget.NewSelect(names, nil)
+	for _, p := range wallet.Purposes {
+		if p.Purpose == defaultWatchPurpose {
+			watchOnlyPurposeSelect.SetSelected(p.Name)
+		}
+	}
+
+	loadButton := widget.NewButtonWithIcon("Carregar Somente Leitura", theme.VisibilityIcon(), func() {
+		clearStatus()
+		loadWatchOnlyWallet()
+	})
+
+	return widget.NewCard("Modo Somente Leitura (Watch-Only)", "", container.NewPadded(
+		container.NewVBox(
+			widget.NewLabel("Audite saldos e busque endereços sem a seed."),
+			watchOnlyEntry,
+			widget.NewForm(widget.NewFormItem("Tipo de uma xpub:", watchOnlyPurposeSelect)),
+			loadButton,
+		),
+	))
+}
+
+// loadWatchOnlyWallet replaces the loaded seed with the watch-only wallet of
+// the key or descriptor entered in the watch-only card. Features that need
+// private keys are disabled until a seed is loaded again.
+func loadWatchOnlyWallet() {
+	purpose := defaultWatchPurpose
+	for _, p := range wallet.Purposes {
+		if p.Name == watchOnlyPurposeSelect.Selected {
+			purpose = p.Purpose
+		}
+	}
+
+	w, err := loadWatchOnly(watchOnlyEntry.Text, currentNetwork, purpose)
+	if err != nil {
+		showStatus(fmt.Sprintf("Erro: %v", err), true)
+		return
+	}
+	watchedPurpose, account, _ := w.WatchedAccount()
+	log.Printf("Carteira somente leitura: BIP%d, conta %d", watchedPurpose, account)
+
+	currentSeed = nil
+	currentBIP39Seed = nil
+	currentWallet = w
+	currentAccount = account
+	currentBatchStart = 0
+	customPath = nil
+	accountEntry.SetText(strconv.FormatUint(uint64(account), 10))
+	mnemonicEntry.SetText("")
+
+	updateXPUBDisplay()
+	updateAddressGrid()
+	loadMoreButton.Enable()
+	if selectedBackend != nil {
+		verificationButtons.Show()
+	}
+	showStatus(fmt.Sprintf("Modo somente leitura: conta %d BIP%d carregada. Recursos que exigem a seed estão desabilitados.", account, watchedPurpose), false)
+}